@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// authorAssignment is the outcome of assigning posted issues to the MR author.
+type authorAssignment struct {
+	login string
+	keys  []string
+	// unmapped is set when issues needed an assignee but the author has no
+	// SonarQube user.
+	unmapped bool
+}
+
+// loadUserMap reads a file of gitlab-username=sonar-login lines. Blank lines and
+// lines starting with # are skipped; usernames are matched case-insensitively.
+func loadUserMap(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SonarQube user map: %w", err)
+	}
+
+	users := make(map[string]string)
+	for index, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		username, login, found := strings.Cut(line, "=")
+		username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+		login = strings.TrimSpace(login)
+		if !found || username == "" || login == "" {
+			return nil, fmt.Errorf("invalid SonarQube user map line %d in %s: expected gitlab-username=sonar-login", index+1, path)
+		}
+		users[strings.ToLower(username)] = login
+	}
+
+	return users, nil
+}
+
+// findSonarLogin maps a GitLab user to a SonarQube login through the user map,
+// then by email, then by a login equal to the username. It returns an empty login
+// when nothing matches.
+func findSonarLogin(
+	ctx context.Context,
+	gitlabClient *gitlab.Client,
+	sonarClient *sonar.Client,
+	userMap map[string]string,
+	author gitlab.User,
+) (string, error) {
+	if author.Username == "" {
+		return "", nil
+	}
+	if login, ok := userMap[strings.ToLower(author.Username)]; ok {
+		return login, nil
+	}
+
+	if author.ID > 0 {
+		user, err := gitlabClient.GetUser(ctx, author.ID)
+		if err != nil {
+			return "", wrapGitLabError(err, "failed to read the merge request author")
+		}
+		if user.Email != "" {
+			candidates, err := sonarClient.SearchUsers(ctx, user.Email)
+			if err != nil {
+				return "", wrapSonarError(err, "failed to search SonarQube users")
+			}
+			for _, candidate := range candidates {
+				if strings.EqualFold(candidate.Email, user.Email) {
+					return candidate.Login, nil
+				}
+			}
+		}
+	}
+
+	candidates, err := sonarClient.SearchUsers(ctx, author.Username)
+	if err != nil {
+		return "", wrapSonarError(err, "failed to search SonarQube users")
+	}
+	for _, candidate := range candidates {
+		if candidate.Login == author.Username {
+			return candidate.Login, nil
+		}
+	}
+
+	return "", nil
+}
+
+// assignIssuesToAuthor assigns the issues nobody is assigned to in SonarQube to
+// the login of the MR author.
+func assignIssuesToAuthor(
+	ctx context.Context,
+	gitlabClient *gitlab.Client,
+	sonarClient *sonar.Client,
+	userMap map[string]string,
+	author gitlab.User,
+	issues []sonar.Issue,
+) (authorAssignment, error) {
+	var unassigned []sonar.Issue
+	for _, issue := range issues {
+		if issue.Assignee == "" {
+			unassigned = append(unassigned, issue)
+		}
+	}
+	if len(unassigned) == 0 {
+		return authorAssignment{}, nil
+	}
+
+	login, err := findSonarLogin(ctx, gitlabClient, sonarClient, userMap, author)
+	if err != nil {
+		return authorAssignment{}, err
+	}
+	if login == "" {
+		return authorAssignment{unmapped: true}, nil
+	}
+
+	result := authorAssignment{login: login}
+	for _, issue := range unassigned {
+		if err := sonarClient.AssignIssue(ctx, issue.Key, login); err != nil {
+			return result, wrapSonarError(err, fmt.Sprintf("failed to assign SonarQube issue %s", issue.Key))
+		}
+		result.keys = append(result.keys, issue.Key)
+	}
+
+	return result, nil
+}
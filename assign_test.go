@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestLoadUserMap(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := writeTemplateFile(t, dir, "users.txt", "# GitLab = SonarQube\n\n@Alice = alice.s\nbob=bob@corp\n")
+	users, err := loadUserMap(path)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(users, map[string]string{"alice": "alice.s", "bob": "bob@corp"}) {
+		t.Fatalf("unexpected user map: %v", users)
+	}
+
+	_, err = loadUserMap(writeTemplateFile(t, dir, "invalid.txt", "alice=alice\ncarol\n"))
+	if err == nil || !strings.Contains(err.Error(), "invalid SonarQube user map line 2") {
+		t.Fatalf("expected invalid line error, got %v", err)
+	}
+
+	_, err = loadUserMap(filepath.Join(dir, "missing.txt"))
+	if err == nil || !strings.Contains(err.Error(), "failed to read SonarQube user map") {
+		t.Fatalf("expected missing file error, got %v", err)
+	}
+}
+
+func TestAssignIssuesToAuthorMatchesUsers(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v4/users/7":
+			_, _ = w.Write([]byte(`{"id":7,"username":"alice","public_email":"Alice@Example.com"}`))
+		case r.URL.Path == "/api/v4/users/8":
+			_, _ = w.Write([]byte(`{"id":8,"username":"bob"}`))
+		case r.URL.Path == "/api/v4/users/9":
+			_, _ = w.Write([]byte(`{"id":9,"username":"carol"}`))
+		case r.URL.Path == "/api/users/search" && r.URL.Query().Get("q") == "Alice@Example.com":
+			_, _ = w.Write([]byte(`{"users":[{"login":"alice.s","email":"alice@example.com"}]}`))
+		case r.URL.Path == "/api/users/search" && r.URL.Query().Get("q") == "bob":
+			_, _ = w.Write([]byte(`{"users":[{"login":"bobby"},{"login":"bob"}]}`))
+		case r.URL.Path == "/api/users/search":
+			_, _ = w.Write([]byte(`{"users":[]}`))
+		case r.URL.Path == "/api/issues/assign":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.String())
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	gitlabClient := gitlab.NewClient(server.URL, "token", server.Client())
+	sonarClient := sonar.NewClient(server.URL, "token", server.Client())
+	issues := []sonar.Issue{{Key: "A"}, {Key: "B", Assignee: "someone"}, {Key: "C"}}
+
+	tests := []struct {
+		author   gitlab.User
+		userMap  map[string]string
+		expected authorAssignment
+	}{
+		{author: gitlab.User{ID: 6, Username: "Dave"}, userMap: map[string]string{"dave": "d.login"}, expected: authorAssignment{login: "d.login", keys: []string{"A", "C"}}},
+		{author: gitlab.User{ID: 7, Username: "alice"}, expected: authorAssignment{login: "alice.s", keys: []string{"A", "C"}}},
+		{author: gitlab.User{ID: 8, Username: "bob"}, expected: authorAssignment{login: "bob", keys: []string{"A", "C"}}},
+		{author: gitlab.User{ID: 9, Username: "carol"}, expected: authorAssignment{unmapped: true}},
+	}
+	for _, test := range tests {
+		assignment, err := assignIssuesToAuthor(context.Background(), gitlabClient, sonarClient, test.userMap, test.author, issues)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", test.author.Username, err)
+		}
+		if !reflect.DeepEqual(assignment, test.expected) {
+			t.Fatalf("%s: expected %+v, got %+v", test.author.Username, test.expected, assignment)
+		}
+	}
+
+	assignment, err := assignIssuesToAuthor(context.Background(), gitlabClient, sonarClient, nil, gitlab.User{ID: 9, Username: "carol"}, issues[1:2])
+	if err != nil || !reflect.DeepEqual(assignment, authorAssignment{}) {
+		t.Fatalf("expected no lookup for assigned issues, got %+v, %v", assignment, err)
+	}
+}
+
+func TestRunWithAssignAuthorAssignsPostedIssues(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		assigns []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"author":{"id":7,"username":"alice"},"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}]}`))
+		case r.Method == http.MethodGet && (r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions" || r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes"):
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes/bulk_publish":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			_, _ = w.Write([]byte(`{
+				"issues":[
+					{"key":"ISSUE-1","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"first","component":"project:main.go","line":12},
+					{"key":"ISSUE-2","rule":"go:S200","type":"BUG","severity":"MAJOR","message":"second","component":"project:main.go","line":13,"assignee":"bob"},
+					{"key":"ISSUE-3","rule":"go:S300","type":"BUG","severity":"MAJOR","message":"third","component":"project:main.go"}
+				],
+				"paging":{"pageIndex":1,"pageSize":500,"total":3}
+			}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/issues/assign":
+			assigns = append(assigns, r.PostForm.Get("issue")+"="+r.PostForm.Get("assignee"))
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	userMap := writeTemplateFile(t, t.TempDir(), "users.txt", "alice=alice.s\n")
+	var output bytes.Buffer
+	err := runWith(
+		append(draftNotesTestArgs(server.URL), "--assign-author", "--sonar-user-map="+userMap),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(assigns, []string{"ISSUE-1=alice.s"}) {
+		t.Fatalf("expected only ISSUE-1 to be assigned, got %q", assigns)
+	}
+	assertCommentContains(t, output.String(), "Assigned 1 SonarQube issues to alice.s (GitLab @alice): ISSUE-1")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// baselineComparison holds the issues of the MR diff that the target branch
+// analysis already reports.
+type baselineComparison struct {
+	target      string
+	preexisting []sonar.Issue
+}
+
+// baselineData is the template view of baselineComparison.
+type baselineData struct {
+	Target            string
+	PreexistingIssues []issueData
+}
+
+// fetchTargetIssues loads the target branch analysis for the same scope as the
+// MR issues. Diff-scoped fetches search paths, the MR files before renames.
+func fetchTargetIssues(ctx context.Context, client *sonar.Client, cfg config.Config, paths []string) ([]sonar.Issue, error) {
+	projectKey := cmp.Or(cfg.SonarTargetProjectKey, cfg.SonarProjectKey)
+	if cfg.IssueScope != config.IssueScopeDiff {
+		return client.FetchProjectIssues(ctx, projectKey)
+	}
+
+	return client.FetchFileIssues(ctx, projectKey, paths)
+}
+
+// targetPaths lists the MR files by their paths on the target branch.
+func targetPaths(index diffLineIndex) []string {
+	paths := make([]string, 0, len(index.lines))
+	for _, path := range index.paths() {
+		paths = append(paths, targetPath(index, path))
+	}
+
+	return paths
+}
+
+// targetBranchScope is the branch searched in the target project: a separate
+// target project holds only the target branch analysis.
+func targetBranchScope(cfg config.Config) string {
+	if cfg.SonarTargetProjectKey != "" {
+		return ""
+	}
+
+	return cfg.TargetBranch
+}
+
+// baselineTarget names the analysis the MR is compared with.
+func baselineTarget(cfg config.Config) string {
+	if cfg.SonarTargetProjectKey != "" {
+		return cfg.SonarTargetProjectKey
+	}
+
+	return cfg.TargetBranch
+}
+
+// splitIssuesByBaseline separates issues introduced by the MR from those the
+// target branch already has. Issue keys and lines differ between analyses, so
+// issues are matched by rule, path before renames and line content hash, or by
+// message when SonarQube reports no hash. Each target issue matches once.
+func splitIssuesByBaseline(issues, targetIssues []sonar.Issue, index diffLineIndex) ([]sonar.Issue, []sonar.Issue) {
+	remaining := make(map[string]int, len(targetIssues))
+	for _, issue := range targetIssues {
+		remaining[issueFingerprint(issue, normalizeRepoPath(issue.FilePath))]++
+	}
+
+	var introduced, preexisting []sonar.Issue
+	for _, issue := range issues {
+		fingerprint := issueFingerprint(issue, targetPath(index, normalizeRepoPath(issue.FilePath)))
+		if remaining[fingerprint] > 0 {
+			remaining[fingerprint]--
+			preexisting = append(preexisting, issue)
+			continue
+		}
+		introduced = append(introduced, issue)
+	}
+
+	return introduced, preexisting
+}
+
+func issueFingerprint(issue sonar.Issue, path string) string {
+	content := strings.TrimSpace(issue.Hash)
+	if content == "" {
+		content = "message:" + strings.TrimSpace(issue.Message)
+	}
+
+	return strings.Join([]string{strings.TrimSpace(issue.Rule), path, content}, "\x00")
+}
+
+// targetPath maps a path of the MR head to its path on the target branch.
+func targetPath(index diffLineIndex, path string) string {
+	if info, ok := index.pathMap[path]; ok {
+		if oldPath := normalizeRepoPath(info.oldPath); oldPath != "" {
+			return oldPath
+		}
+	}
+
+	return path
+}
+
+func newBaselineData(links commentLinks, baseline *baselineComparison) *baselineData {
+	if baseline == nil {
+		return nil
+	}
+
+	data := &baselineData{Target: baseline.target}
+	for _, issue := range baseline.preexisting {
+		data.PreexistingIssues = append(data.PreexistingIssues, newIssueData(links, issue))
+	}
+	slices.SortStableFunc(data.PreexistingIssues, func(a, b issueData) int {
+		return cmp.Or(strings.Compare(a.FilePath, b.FilePath), cmp.Compare(a.Line, b.Line))
+	})
+
+	return data
+}
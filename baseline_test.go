@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestSplitIssuesByBaseline(t *testing.T) {
+	t.Parallel()
+
+	index := diffLineIndex{pathMap: map[string]pathInfo{
+		"src/renamed.go": {oldPath: "src/original.go", newPath: "src/renamed.go"},
+	}}
+	targetIssues := []sonar.Issue{
+		{Key: "T1", Rule: "go:S1", FilePath: "src/original.go", Line: 3, Hash: "h1"},
+		{Key: "T2", Rule: "go:S2", FilePath: "main.go", Line: 9, Hash: "h2"},
+		{Key: "T3", Rule: "go:S3", FilePath: "main.go", Message: "no hash"},
+	}
+	issues := []sonar.Issue{
+		{Key: "A", Rule: "go:S1", FilePath: "src/renamed.go", Line: 5, Hash: "h1"},
+		{Key: "B", Rule: "go:S2", FilePath: "main.go", Line: 12, Hash: "h2"},
+		{Key: "C", Rule: "go:S2", FilePath: "main.go", Line: 13, Hash: "h2"},
+		{Key: "D", Rule: "go:S1", FilePath: "main.go", Line: 14, Hash: "h1"},
+		{Key: "E", Rule: "go:S3", FilePath: "main.go", Message: "no hash"},
+	}
+
+	introduced, preexisting := splitIssuesByBaseline(issues, targetIssues, index)
+
+	if keys := issueKeys(introduced); len(keys) != 2 || keys[0] != "C" || keys[1] != "D" {
+		t.Fatalf("unexpected introduced issues: %v", keys)
+	}
+	if keys := issueKeys(preexisting); len(keys) != 3 || keys[0] != "A" || keys[1] != "B" || keys[2] != "E" {
+		t.Fatalf("unexpected pre-existing issues: %v", keys)
+	}
+}
+
+func TestTargetBranchScope(t *testing.T) {
+	t.Parallel()
+
+	if scope := targetBranchScope(config.Config{TargetBranch: "main"}); scope != "main" {
+		t.Fatalf("expected target branch scope, got %q", scope)
+	}
+	if scope := targetBranchScope(config.Config{TargetBranch: "main", SonarTargetProjectKey: "project-main"}); scope != "" {
+		t.Fatalf("expected no branch for a separate target project, got %q", scope)
+	}
+	if target := baselineTarget(config.Config{TargetBranch: "main", SonarTargetProjectKey: "project-main"}); target != "project-main" {
+		t.Fatalf("expected target project as baseline name, got %q", target)
+	}
+}
+
+func issueKeys(issues []sonar.Issue) []string {
+	keys := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+
+	return keys
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// summaryTopRules is the number of most violated rules listed in the summary.
+const summaryTopRules = 5
+
+type fileBreakdown struct {
+	Path    string
+	BlobURL string
+	// Counts has one entry per column returned by fileBreakdownSeverities.
+	Counts      []severityCount
+	Total       int
+	Inline      int
+	SummaryOnly int
+	severity    int
+}
+
+type ruleCount struct {
+	Rule    string
+	RuleURL string
+	Count   int
+}
+
+// fileBreakdownSeverities returns the severity columns of the per-file table:
+// impact severities for the clean code taxonomy, legacy severities otherwise.
+func fileBreakdownSeverities(taxonomy sonar.Taxonomy) []string {
+	if taxonomy == sonar.TaxonomyCleanCode {
+		return summaryImpactSeverityOrder
+	}
+
+	return summarySeverityOrder
+}
+
+// fileBreakdownRank returns the column of an issue in severities, or -1 when its
+// severity is unknown. With the clean code taxonomy an issue is counted once,
+// under the highest severity of its impacts.
+func fileBreakdownRank(issue sonar.Issue, taxonomy sonar.Taxonomy, severities []string) int {
+	if taxonomy != sonar.TaxonomyCleanCode {
+		return slices.Index(severities, sonar.NormalizeSeverity(issue.Severity))
+	}
+
+	rank := -1
+	for _, impact := range sonar.EffectiveImpacts(issue) {
+		if index := slices.Index(severities, sonar.NormalizeSeverity(impact.Severity)); index >= 0 && (rank < 0 || index < rank) {
+			rank = index
+		}
+	}
+
+	return rank
+}
+
+// buildFileBreakdown counts issues per file. Issues listed in summaryOnly were not
+// posted as inline discussions. Files are sorted by their most severe issue, then
+// by issue count and path.
+func buildFileBreakdown(links commentLinks, issues []sonar.Issue, summaryOnly map[string]bool, taxonomy sonar.Taxonomy) []fileBreakdown {
+	severities := fileBreakdownSeverities(taxonomy)
+	var files []fileBreakdown
+	fileIndexes := make(map[string]int)
+	for _, issue := range issues {
+		path := normalizeRepoPath(issue.FilePath)
+		if path == "" {
+			continue
+		}
+
+		position, exists := fileIndexes[path]
+		if !exists {
+			position = len(files)
+			fileIndexes[path] = position
+			files = append(files, fileBreakdown{
+				Path:     path,
+				BlobURL:  links.blob(path, 0),
+				Counts:   make([]severityCount, len(severities)),
+				severity: len(severities),
+			})
+			for i, severity := range severities {
+				files[position].Counts[i].Severity = severity
+			}
+		}
+
+		file := &files[position]
+		file.Total++
+		if summaryOnly[issue.Key] {
+			file.SummaryOnly++
+		} else {
+			file.Inline++
+		}
+		if rank := fileBreakdownRank(issue, taxonomy, severities); rank >= 0 {
+			file.Counts[rank].Count++
+			file.severity = min(file.severity, rank)
+		}
+	}
+
+	slices.SortStableFunc(files, func(a, b fileBreakdown) int {
+		return cmp.Or(
+			cmp.Compare(a.severity, b.severity),
+			cmp.Compare(b.Total, a.Total),
+			strings.Compare(a.Path, b.Path),
+		)
+	})
+
+	return files
+}
+
+// topViolatedRules returns up to limit rules with the most issues, ties broken by
+// rule key.
+func topViolatedRules(links commentLinks, issues []sonar.Issue, limit int) []ruleCount {
+	counts := make(map[string]int)
+	for _, issue := range issues {
+		if rule := strings.TrimSpace(issue.Rule); rule != "" {
+			counts[rule]++
+		}
+	}
+
+	rules := make([]ruleCount, 0, len(counts))
+	for rule, count := range counts {
+		rules = append(rules, ruleCount{Rule: rule, RuleURL: links.sonar.Rule(rule), Count: count})
+	}
+	slices.SortFunc(rules, func(a, b ruleCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Rule, b.Rule))
+	})
+
+	return rules[:min(limit, len(rules))]
+}
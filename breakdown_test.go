@@ -0,0 +1,88 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestBuildFileBreakdown(t *testing.T) {
+	t.Parallel()
+
+	links := commentLinks{projectURL: "https://gitlab.example.com/group/app", headSHA: "abc123"}
+	issues := []sonar.Issue{
+		{Key: "1", FilePath: "a.go", Severity: "MINOR"},
+		{Key: "2", FilePath: "a.go", Severity: "MINOR"},
+		{Key: "3", FilePath: "b.go", Severity: "CRITICAL"},
+		{Key: "4", FilePath: "c.go", Severity: "MINOR"},
+		{Key: "5", FilePath: "c.go", Severity: "MINOR"},
+		{Key: "6", FilePath: "c.go", Severity: "MAJOR"},
+		{Key: "7", Severity: "BLOCKER"},
+	}
+
+	files := buildFileBreakdown(links, issues, map[string]bool{"2": true, "6": true}, sonar.TaxonomyLegacy)
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	if !slices.Equal(paths, []string{"b.go", "c.go", "a.go"}) {
+		t.Fatalf("unexpected file order: %v", paths)
+	}
+
+	c := files[1]
+	if c.Total != 3 || c.Inline != 2 || c.SummaryOnly != 1 {
+		t.Fatalf("unexpected counts for c.go: %+v", c)
+	}
+	if c.Counts[2] != (severityCount{Severity: "MAJOR", Count: 1}) || c.Counts[3] != (severityCount{Severity: "MINOR", Count: 2}) {
+		t.Fatalf("unexpected severity counts for c.go: %+v", c.Counts)
+	}
+	if c.BlobURL != "https://gitlab.example.com/group/app/-/blob/abc123/c.go" {
+		t.Fatalf("unexpected blob URL: %q", c.BlobURL)
+	}
+}
+
+func TestBuildFileBreakdownWithCleanCodeTaxonomy(t *testing.T) {
+	t.Parallel()
+
+	issues := []sonar.Issue{
+		{Key: "1", FilePath: "a.go", Severity: "MAJOR", Impacts: []sonar.Impact{
+			{SoftwareQuality: "MAINTAINABILITY", Severity: "LOW"},
+			{SoftwareQuality: "SECURITY", Severity: "HIGH"},
+		}},
+		{Key: "2", FilePath: "a.go", Severity: "BLOCKER", Impacts: []sonar.Impact{{SoftwareQuality: "RELIABILITY", Severity: "MEDIUM"}}},
+		{Key: "3", FilePath: "b.go", Type: "BUG", Severity: "BLOCKER"},
+	}
+
+	files := buildFileBreakdown(commentLinks{}, issues, nil, sonar.TaxonomyCleanCode)
+
+	if len(files) != 2 || files[0].Path != "b.go" || files[1].Path != "a.go" {
+		t.Fatalf("expected b.go before a.go, got %+v", files)
+	}
+	expected := []severityCount{
+		{Severity: "BLOCKER"}, {Severity: "HIGH", Count: 1}, {Severity: "MEDIUM", Count: 1}, {Severity: "LOW"}, {Severity: "INFO"},
+	}
+	if !slices.Equal(files[1].Counts, expected) {
+		t.Fatalf("expected impact severity counts %+v, got %+v", expected, files[1].Counts)
+	}
+	if files[0].Counts[0] != (severityCount{Severity: "BLOCKER", Count: 1}) {
+		t.Fatalf("expected the legacy severity fallback for b.go, got %+v", files[0].Counts)
+	}
+}
+
+func TestTopViolatedRules(t *testing.T) {
+	t.Parallel()
+
+	issues := []sonar.Issue{
+		{Rule: "go:S2"}, {Rule: "go:S1"}, {Rule: "go:S3"},
+		{Rule: "go:S3"}, {Rule: "go:S2"}, {Rule: "go:S3"}, {Rule: ""},
+	}
+
+	rules := topViolatedRules(commentLinks{}, issues, 2)
+
+	expected := []ruleCount{{Rule: "go:S3", Count: 3}, {Rule: "go:S2", Count: 2}}
+	if !slices.Equal(rules, expected) {
+		t.Fatalf("unexpected top rules: %+v", rules)
+	}
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+const sonarCommandPrefix = "/sonar"
+
+// commandReplyMarkerFormat tags the reply to a command note with the note ID, so
+// that each command is applied once.
+const commandReplyMarkerFormat = "<!-- sonar-gitlab-commenter:command %d -->"
+
+var commandReplyMarkerRegex = regexp.MustCompile(`<!-- sonar-gitlab-commenter:command (\d+) -->`)
+
+const (
+	commandFalsePositive = "falsepositive"
+	commandAccept        = "accept"
+	commandAssign        = "assign"
+)
+
+// commandAliases maps accepted spellings to command actions.
+var commandAliases = map[string]string{
+	"falsepositive":  commandFalsePositive,
+	"false-positive": commandFalsePositive,
+	"fp":             commandFalsePositive,
+	"accept":         commandAccept,
+	"wontfix":        commandAccept,
+	"assign":         commandAssign,
+}
+
+// sonarCommand is a /sonar command replied to a tool discussion.
+type sonarCommand struct {
+	discussionID string
+	noteID       int
+	authorID     int
+	author       string
+	keys         []string
+	// text is the command line as written.
+	text string
+	// action is empty for unknown commands.
+	action   string
+	argument string
+}
+
+// sonarCommandResult reports how many planned commands were applied, which issues
+// they transitioned and how many were left when the deadline ran out.
+type sonarCommandResult struct {
+	applied      int
+	skipped      int
+	transitioned map[string]bool
+}
+
+// parseSonarCommand returns the first /sonar command line of a note.
+func parseSonarCommand(body string) (sonarCommand, bool) {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.EqualFold(fields[0], sonarCommandPrefix) {
+			continue
+		}
+
+		command := sonarCommand{text: line}
+		if len(fields) > 1 {
+			command.action = commandAliases[strings.ToLower(fields[1])]
+			rest := strings.TrimSpace(line[len(fields[0]):])
+			command.argument = strings.TrimSpace(rest[len(fields[1]):])
+		}
+		return command, true
+	}
+
+	return sonarCommand{}, false
+}
+
+// planSonarCommands collects the unanswered /sonar commands that people other
+// than the bot replied to tool discussions.
+func planSonarCommands(discussions []gitlab.Discussion, bot gitlab.User) []sonarCommand {
+	var commands []sonarCommand
+	for _, discussion := range discussions {
+		if !discussionContainsMarker(discussion) {
+			continue
+		}
+
+		answered := make(map[int]bool)
+		for _, note := range discussion.Notes {
+			for _, match := range commandReplyMarkerRegex.FindAllStringSubmatch(note.Body, -1) {
+				if id, err := strconv.Atoi(match[1]); err == nil {
+					answered[id] = true
+				}
+			}
+		}
+
+		keys := discussionIssueKeys(discussion)
+		for _, note := range discussion.Notes {
+			if note.ID <= 0 || answered[note.ID] || note.Author.ID == bot.ID || commentHasMarker(note.Body) {
+				continue
+			}
+			command, ok := parseSonarCommand(note.Body)
+			if !ok {
+				continue
+			}
+
+			command.discussionID = discussion.ID
+			command.noteID = note.ID
+			command.authorID = note.Author.ID
+			command.author = note.Author.Username
+			command.keys = keys
+			commands = append(commands, command)
+		}
+	}
+
+	return commands
+}
+
+// applySonarCommands applies the commands in SonarQube and replies to each
+// command note with the outcome. Commands of authors below the configured role
+// are rejected. Keys missing from openKeys are skipped; a nil openKeys accepts
+// every key. /sonar assign maps the GitLab username through userMap, falling back
+// to the same login. Rejected SonarQube requests are reported in the thread, while
+// authorization and GitLab errors abort. When ctx runs out, the command in flight
+// and the rest are counted as skipped: without a reply they are applied again on
+// the next run.
+func applySonarCommands(
+	ctx context.Context,
+	gitlabClient *gitlab.Client,
+	sonarClient *sonar.Client,
+	cfg config.Config,
+	catalog i18n.Catalog,
+	commands []sonarCommand,
+	openKeys map[string]bool,
+	acceptTransition string,
+	userMap map[string]string,
+) (sonarCommandResult, error) {
+	result := sonarCommandResult{transitioned: make(map[string]bool)}
+	accessLevels := make(map[int]int)
+	for index, command := range commands {
+		if ctx.Err() != nil {
+			result.skipped = len(commands) - index
+			return result, nil
+		}
+
+		level, ok := accessLevels[command.authorID]
+		if !ok {
+			var err error
+			level, err = gitlabClient.GetProjectAccessLevel(ctx, cfg.GitLabProjectID, command.authorID)
+			if errors.Is(err, context.DeadlineExceeded) {
+				result.skipped = len(commands) - index
+				return result, nil
+			}
+			if err != nil {
+				return result, wrapGitLabError(err, fmt.Sprintf("failed to check the project role of @%s", command.author))
+			}
+			accessLevels[command.authorID] = level
+		}
+
+		var reply string
+		if level < config.RoleAccessLevel(cfg.SonarCommandRole) {
+			reply = fmt.Sprintf(catalog.CommandNotAllowed, command.text, command.author, cfg.SonarCommandRole)
+		} else {
+			var (
+				done []string
+				err  error
+			)
+			reply, done, err = applySonarCommand(ctx, sonarClient, cfg, catalog, command, openKeys, acceptTransition, userMap)
+			if errors.Is(err, context.DeadlineExceeded) {
+				result.skipped = len(commands) - index
+				return result, nil
+			}
+			if err != nil {
+				return result, err
+			}
+			if len(done) > 0 {
+				result.applied++
+			}
+			if command.action != commandAssign {
+				for _, key := range done {
+					result.transitioned[key] = true
+				}
+			}
+		}
+
+		body := fmt.Sprintf(commandReplyMarkerFormat, command.noteID) + "\n" + reply
+		err := gitlabClient.CreateDiscussionNote(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID, command.discussionID, body)
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.skipped = len(commands) - index
+			return result, nil
+		}
+		if err != nil {
+			return result, wrapGitLabError(err, "failed to reply to a /sonar command")
+		}
+	}
+
+	return result, nil
+}
+
+// applySonarCommand returns the reply to the command and the keys it was applied
+// to. Keys that SonarQube rejects are listed in the reply after the applied ones.
+func applySonarCommand(
+	ctx context.Context,
+	sonarClient *sonar.Client,
+	cfg config.Config,
+	catalog i18n.Catalog,
+	command sonarCommand,
+	openKeys map[string]bool,
+	acceptTransition string,
+	userMap map[string]string,
+) (string, []string, error) {
+	assignee := strings.TrimPrefix(command.argument, "@")
+	if command.action == "" || (command.action == commandAssign && (assignee == "" || strings.ContainsAny(assignee, " \t"))) {
+		return fmt.Sprintf(catalog.CommandUnknown, command.text), nil, nil
+	}
+	if login, ok := userMap[strings.ToLower(assignee)]; ok && command.action == commandAssign {
+		assignee = login
+	}
+
+	keys := commandKeys(command, openKeys)
+	if len(keys) == 0 {
+		return fmt.Sprintf(catalog.CommandNoOpenIssues, command.text), nil, nil
+	}
+
+	var (
+		replies []string
+		done    []string
+		errs    []error
+	)
+	if command.action == commandAssign {
+		for _, key := range keys {
+			if err := sonarClient.AssignIssue(ctx, key, assignee); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+			done = append(done, key)
+		}
+		if len(done) > 0 {
+			replies = append(replies, fmt.Sprintf(catalog.CommandAssigned, formatIssueKeyList(done), assignee, command.author))
+		}
+	} else {
+		transition := acceptTransition
+		if command.action == commandFalsePositive {
+			transition = sonar.TransitionFalsePositive
+		}
+		done, errs = transitionCommandIssues(ctx, sonarClient, cfg, catalog, command, keys, transition)
+		if len(done) > 0 {
+			replies = append(replies, fmt.Sprintf(catalog.CommandTransitioned, formatIssueKeyList(done), transitionName(catalog, transition), command.author))
+		}
+	}
+	for _, err := range errs {
+		if errors.Is(err, sonar.ErrUnauthorized) || errors.Is(err, context.DeadlineExceeded) {
+			return "", nil, fmt.Errorf("failed to apply %q: %w", command.text, err)
+		}
+		replies = append(replies, fmt.Sprintf(catalog.CommandFailed, command.text, err))
+	}
+
+	return strings.Join(replies, "\n\n"), done, nil
+}
+
+// transitionCommandIssues transitions each issue and adds the command reason as a
+// SonarQube comment. It returns the transitioned keys and one error per failed
+// key.
+func transitionCommandIssues(
+	ctx context.Context,
+	sonarClient *sonar.Client,
+	cfg config.Config,
+	catalog i18n.Catalog,
+	command sonarCommand,
+	keys []string,
+	transition string,
+) ([]string, []error) {
+	var (
+		done []string
+		errs []error
+	)
+	for _, key := range keys {
+		if err := sonarClient.DoTransition(ctx, key, transition); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		done = append(done, key)
+		if command.argument == "" {
+			continue
+		}
+		comment := fmt.Sprintf(catalog.CommandSonarComment, command.argument, command.author, cfg.GitLabMRIID)
+		if err := sonarClient.AddComment(ctx, key, comment); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	return done, errs
+}
+
+func commandKeys(command sonarCommand, openKeys map[string]bool) []string {
+	if openKeys == nil {
+		return command.keys
+	}
+
+	var keys []string
+	for _, key := range command.keys {
+		if openKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// runTriage is the triage subcommand: it applies /sonar commands to every issue
+// of their discussions without fetching issues or posting comments.
+func runTriage(cfg config.Config, catalog i18n.Catalog, stdout io.Writer) error {
+	userMap, err := loadUserMap(cfg.SonarUserMapPath)
+	if err != nil {
+		return err
+	}
+
+	gitlabClient := gitlab.NewClient(cfg.GitLabURL, cfg.GitLabToken, nil)
+	client := sonar.NewClient(
+		cfg.SonarURL,
+		cfg.SonarToken,
+		nil,
+		sonar.WithOrganization(cfg.SonarOrganization),
+		sonar.WithAuthScheme(cfg.SonarAuthScheme),
+	)
+
+	readCtx, cancelRead := context.WithTimeout(context.Background(), cfg.FetchTimeout)
+	defer cancelRead()
+
+	var (
+		discussions   []gitlab.Discussion
+		botUser       gitlab.User
+		serverVersion string
+	)
+	group, fetchCtx := newFetchGroup(readCtx)
+	group.Go(func() error {
+		var err error
+		discussions, err = gitlabClient.ListMergeRequestDiscussions(fetchCtx, cfg.GitLabProjectID, cfg.GitLabMRIID)
+		return wrapGitLabError(err, "failed to list merge request discussions")
+	})
+	group.Go(func() error {
+		var err error
+		botUser, err = gitlabClient.GetCurrentUser(fetchCtx)
+		return wrapGitLabError(err, "failed to identify the GitLab token user")
+	})
+	var versionErr error
+	group.Go(func() error {
+		serverVersion, versionErr = client.FetchServerVersion(fetchCtx)
+		return nil
+	})
+	if err := group.Wait(); err != nil {
+		return fetchTimeoutError(err, cfg.FetchTimeout)
+	}
+	cancelRead()
+	if versionErr != nil {
+		if err := writeOutput(stdout, "SonarQube server version unavailable, using the %q transition: %v\n", sonar.TransitionWontFix, versionErr); err != nil {
+			return err
+		}
+	}
+
+	commands := planSonarCommands(discussions, botUser)
+	if cfg.DryRun {
+		if err := writeOutput(stdout, "%s", catalog.LogDryRun); err != nil {
+			return err
+		}
+		for _, command := range commands {
+			if err := writeOutput(stdout, "Planned %q from note %d on issues %s\n", command.text, command.noteID, strings.Join(command.keys, ",")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.WriteTimeout)
+	defer cancel()
+
+	result, err := applySonarCommands(ctx, gitlabClient, client, cfg, catalog, commands, nil, acceptTransitionFor(cfg, serverVersion), userMap)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(stdout, catalog.LogCommands, result.applied, len(commands)); err != nil {
+		return err
+	}
+	if result.skipped > 0 {
+		if err := writeOutput(stdout, catalog.LogCommandsSkipped, result.skipped, cfg.WriteTimeout); err != nil {
+			return err
+		}
+	}
+
+	return writeOutput(stdout, catalog.LogMergeRequestTarget, cfg.GitLabProjectID, cfg.GitLabMRIID)
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/gitlab"
+)
+
+func TestParseSonarCommand(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		body     string
+		found    bool
+		action   string
+		argument string
+	}{
+		{body: "/sonar falsepositive generated code", found: true, action: commandFalsePositive, argument: "generated code"},
+		{body: "Agreed.\n  /SONAR FP  mock only ", found: true, action: commandFalsePositive, argument: "mock only"},
+		{body: "/sonar accept", found: true, action: commandAccept},
+		{body: "/sonar wontfix legacy", found: true, action: commandAccept, argument: "legacy"},
+		{body: "/sonar assign @alice", found: true, action: commandAssign, argument: "@alice"},
+		{body: "/sonar reopen", found: true},
+		{body: "/sonar", found: true},
+		{body: "see /sonar accept", found: false},
+		{body: "/sonarqube accept", found: false},
+	}
+
+	for _, tc := range testCases {
+		command, found := parseSonarCommand(tc.body)
+		if found != tc.found || command.action != tc.action || command.argument != tc.argument {
+			t.Fatalf("body %q: expected found=%t action=%q argument=%q, got found=%t %+v", tc.body, tc.found, tc.action, tc.argument, found, command)
+		}
+	}
+}
+
+func TestPlanSonarCommandsSkipsAnsweredAndBotNotes(t *testing.T) {
+	t.Parallel()
+
+	bot := gitlab.User{ID: 1, Username: "bot"}
+	alice := gitlab.User{ID: 2, Username: "alice"}
+	discussions := []gitlab.Discussion{
+		{ID: "d1", Notes: []gitlab.DiscussionNote{
+			{ID: 10, Body: commentMarker + "\n" + issueKeysMarkerPrefix + "A,B -->\n/sonar accept", Author: bot},
+			{ID: 11, Body: "/sonar accept", Author: alice},
+			{ID: 12, Body: "<!-- sonar-gitlab-commenter:command 11 -->\ndone", Author: bot},
+			{ID: 13, Body: "/sonar fp mock", Author: alice},
+		}},
+		{ID: "human", Notes: []gitlab.DiscussionNote{{ID: 20, Body: "/sonar accept", Author: alice}}},
+	}
+
+	commands := planSonarCommands(discussions, bot)
+
+	expected := []sonarCommand{{
+		discussionID: "d1",
+		noteID:       13,
+		authorID:     2,
+		author:       "alice",
+		keys:         []string{"A", "B"},
+		text:         "/sonar fp mock",
+		action:       commandFalsePositive,
+		argument:     "mock",
+	}}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, commands)
+	}
+}
+
+func TestRunWithTriageAppliesCommands(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests []string
+		replies  []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/user":
+			_, _ = w.Write([]byte(`{"id":1,"username":"bot"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[
+				{"id":"d1","notes":[
+					{"id":1,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-1 -->","author":{"id":1,"username":"bot"}},
+					{"id":2,"body":"/sonar falsepositive generated code","author":{"id":2,"username":"alice"}}
+				]},
+				{"id":"d2","notes":[
+					{"id":3,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-2 -->","author":{"id":1,"username":"bot"}},
+					{"id":4,"body":"/sonar assign @bob","author":{"id":2,"username":"alice"}},
+					{"id":5,"body":"/sonar reopen","author":{"id":2,"username":"alice"}}
+				]},
+				{"id":"d3","notes":[
+					{"id":6,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-3,ISSUE-4 -->","author":{"id":1,"username":"bot"}},
+					{"id":7,"body":"/sonar accept","author":{"id":3,"username":"mallory"}},
+					{"id":8,"body":"/sonar accept","author":{"id":4,"username":"carol"}},
+					{"id":9,"body":"/sonar fp","author":{"id":2,"username":"alice"}}
+				]}
+			]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/members/all/2":
+			_, _ = w.Write([]byte(`{"id":2,"username":"alice","access_level":30}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/members/all/3":
+			http.Error(w, `{"message":"404 Not found"}`, http.StatusNotFound)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/members/all/4":
+			_, _ = w.Write([]byte(`{"id":4,"username":"carol","access_level":20}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/issues/do_transition" && r.PostForm.Get("issue") == "ISSUE-3":
+			http.Error(w, `{"errors":[{"msg":"Transition from state CLOSED is not allowed"}]}`, http.StatusBadRequest)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`10.6.0.92116`))
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/issues/"):
+			requests = append(requests, strings.TrimPrefix(r.URL.Path, "/api/issues/")+" "+r.PostForm.Encode())
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/discussions/"):
+			replies = append(replies, r.PostForm.Get("body"))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		append([]string{"triage"}, draftNotesTestArgs(server.URL)...),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectedRequests := []string{
+		"do_transition issue=ISSUE-1&transition=falsepositive",
+		"add_comment issue=ISSUE-1&text=generated+code%0A%0A%28%40alice+in+GitLab+merge+request+%2142%29",
+		"assign assignee=bob&issue=ISSUE-2",
+		"do_transition issue=ISSUE-4&transition=falsepositive",
+	}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Fatalf("expected SonarQube requests %q, got %q", expectedRequests, requests)
+	}
+	expectedReplies := []string{
+		"<!-- sonar-gitlab-commenter:command 2 -->\nMarked `ISSUE-1` as **false positive** in SonarQube as requested by @alice.",
+		"<!-- sonar-gitlab-commenter:command 4 -->\nAssigned `ISSUE-2` to `bob` in SonarQube as requested by @alice.",
+		"<!-- sonar-gitlab-commenter:command 5 -->\nUnknown command `/sonar reopen`. Use `/sonar falsepositive <reason>`, `/sonar accept [reason]` or `/sonar assign @user`.",
+		"<!-- sonar-gitlab-commenter:command 7 -->\nIgnored `/sonar accept`: @mallory needs at least the developer role in this project to run /sonar commands.",
+		"<!-- sonar-gitlab-commenter:command 8 -->\nIgnored `/sonar accept`: @carol needs at least the developer role in this project to run /sonar commands.",
+		"<!-- sonar-gitlab-commenter:command 9 -->\nMarked `ISSUE-4` as **false positive** in SonarQube as requested by @alice.\n\nCould not apply `/sonar fp`: ISSUE-3: SonarQube API request failed for /api/issues/do_transition: HTTP 400: {\"errors\":[{\"msg\":\"Transition from state CLOSED is not allowed\"}]}",
+	}
+	if !reflect.DeepEqual(replies, expectedReplies) {
+		t.Fatalf("expected replies %q, got %q", expectedReplies, replies)
+	}
+	assertCommentContains(t, output.String(), "Applied 3 of 6 /sonar commands from merge request discussions")
+}
+
+func TestRunWithTriageMapsAssigneeThroughUserMap(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests []string
+		replies  []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/user":
+			_, _ = w.Write([]byte(`{"id":1,"username":"bot"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[
+				{"id":"d1","notes":[
+					{"id":1,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-1 -->","author":{"id":1,"username":"bot"}},
+					{"id":2,"body":"/sonar assign @Bob","author":{"id":2,"username":"alice"}}
+				]},
+				{"id":"d2","notes":[
+					{"id":3,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-2 -->","author":{"id":1,"username":"bot"}},
+					{"id":4,"body":"/sonar assign @carol","author":{"id":2,"username":"alice"}}
+				]}
+			]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/members/all/2":
+			_, _ = w.Write([]byte(`{"id":2,"username":"alice","access_level":30}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`10.6.0.92116`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/issues/assign":
+			requests = append(requests, r.PostForm.Encode())
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/discussions/"):
+			replies = append(replies, r.PostForm.Get("body"))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	userMap := writeTemplateFile(t, t.TempDir(), "users.txt", "# GitLab = SonarQube\nbob=bob.smith\n")
+
+	var output bytes.Buffer
+	err := runWith(
+		append([]string{"triage", "--sonar-user-map=" + userMap}, draftNotesTestArgs(server.URL)...),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectedRequests := []string{"assignee=bob.smith&issue=ISSUE-1", "assignee=carol&issue=ISSUE-2"}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Fatalf("expected SonarQube requests %q, got %q", expectedRequests, requests)
+	}
+	expectedReplies := []string{
+		"<!-- sonar-gitlab-commenter:command 2 -->\nAssigned `ISSUE-1` to `bob.smith` in SonarQube as requested by @alice.",
+		"<!-- sonar-gitlab-commenter:command 4 -->\nAssigned `ISSUE-2` to `carol` in SonarQube as requested by @alice.",
+	}
+	if !reflect.DeepEqual(replies, expectedReplies) {
+		t.Fatalf("expected replies %q, got %q", expectedReplies, replies)
+	}
+}
+
+func TestRunWithTriageSkipsCommandsAfterWriteTimeout(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		replies []string
+	)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/user":
+			_, _ = w.Write([]byte(`{"id":1,"username":"bot"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[
+				{"id":"d1","notes":[
+					{"id":1,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-1 -->","author":{"id":1,"username":"bot"}},
+					{"id":2,"body":"/sonar accept","author":{"id":2,"username":"alice"}}
+				]},
+				{"id":"d2","notes":[
+					{"id":3,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-2 -->","author":{"id":1,"username":"bot"}},
+					{"id":4,"body":"/sonar accept","author":{"id":2,"username":"alice"}}
+				]},
+				{"id":"d3","notes":[
+					{"id":5,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-3 -->","author":{"id":1,"username":"bot"}},
+					{"id":6,"body":"/sonar accept","author":{"id":2,"username":"alice"}}
+				]}
+			]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/members/all/2":
+			_, _ = w.Write([]byte(`{"id":2,"username":"alice","access_level":30}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`10.6.0.92116`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/issues/do_transition" && r.PostForm.Get("issue") == "ISSUE-2":
+			// The second command hangs past the write timeout.
+			<-release
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/issues/"):
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/discussions/"):
+			mu.Lock()
+			replies = append(replies, r.PostForm.Get("body"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var output bytes.Buffer
+	err := runWith(
+		append([]string{"triage", "--write-timeout=150ms"}, draftNotesTestArgs(server.URL)...),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(replies) != 1 || !strings.HasPrefix(replies[0], "<!-- sonar-gitlab-commenter:command 2 -->") {
+		t.Fatalf("expected only the reply to the first command, got %q", replies)
+	}
+	assertCommentContains(t, output.String(), "Applied 1 of 3 /sonar commands from merge request discussions")
+	assertCommentContains(t, output.String(), "Skipped 2 /sonar commands after --write-timeout=150ms; they are applied on the next run")
+}
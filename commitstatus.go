@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// commitStatusDescriptionLength is the longest description GitLab stores.
+const commitStatusDescriptionLength = 255
+
+// newCommitStatus builds the quality gate status of the MR head commit. The state
+// comes from the configured mapping and the description carries coverage and
+// issue counts.
+func newCommitStatus(
+	cfg config.Config,
+	catalog i18n.Catalog,
+	links commentLinks,
+	report sonar.QualityReport,
+	issues []sonar.Issue,
+) gitlab.CommitStatus {
+	gate := strings.ToLower(strings.TrimSpace(report.QualityGateStatus))
+	state, ok := cfg.CommitStatusStates[gate]
+	if !ok {
+		state = cfg.CommitStatusStates["warning"]
+	}
+
+	gateName := catalog.QualityGateWarning
+	switch gate {
+	case "passed":
+		gateName = catalog.QualityGatePassed
+	case "failed":
+		gateName = catalog.QualityGateFailed
+	}
+
+	description := fmt.Sprintf(catalog.CommitStatusDescription, gateName, report.OverallCoverage, report.NewCodeCoverage, len(issues))
+	counts, _ := countIssuesBySeverity(issues)
+	var parts []string
+	for _, severity := range summarySeverityOrder {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", severity, counts[severity]))
+		}
+	}
+	if len(parts) > 0 {
+		description += " (" + strings.Join(parts, ", ") + ")"
+	}
+
+	return gitlab.CommitStatus{
+		Name:        cfg.CommitStatusName,
+		State:       state,
+		Description: truncateRunes(description, commitStatusDescriptionLength),
+		TargetURL:   links.sonar.Dashboard(),
+	}
+}
+
+// publishCommitStatus sets the status on the head commit and returns the action
+// log line. The current status is read first, and the request is skipped when its
+// state, description and target URL already match, so that reruns do not add
+// duplicate entries to the commit; a commit without the status gets a new one. In
+// dry-run mode it only describes the planned status.
+func publishCommitStatus(
+	ctx context.Context,
+	gitlabClient *gitlab.Client,
+	cfg config.Config,
+	catalog i18n.Catalog,
+	headSHA string,
+	status gitlab.CommitStatus,
+) (string, error) {
+	if cfg.DryRun {
+		return fmt.Sprintf(catalog.LogCommitStatusPlanned, status.Name, shortSHA(headSHA), status.State, status.Description), nil
+	}
+
+	current, exists, err := gitlabClient.GetCommitStatus(ctx, cfg.GitLabProjectID, headSHA, status.Name)
+	if err != nil {
+		return "", wrapGitLabError(err, "failed to read commit status")
+	}
+	if exists && current == status {
+		return fmt.Sprintf(catalog.LogCommitStatusUnchanged, status.Name, shortSHA(headSHA), status.State), nil
+	}
+
+	if err := gitlabClient.SetCommitStatus(ctx, cfg.GitLabProjectID, headSHA, status); err != nil {
+		return "", wrapGitLabError(err, "failed to set commit status")
+	}
+
+	return fmt.Sprintf(catalog.LogCommitStatus, status.Name, shortSHA(headSHA), status.State), nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestNewCommitStatus(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.Config{
+		CommitStatusName:   config.DefaultCommitStatusName,
+		CommitStatusStates: map[string]string{"passed": "success", "failed": "failed", "warning": "canceled"},
+	}
+	links := commentLinks{sonar: sonar.UILinks{BaseURL: "https://sonar.example.com", ProjectKey: "app"}}
+	issues := []sonar.Issue{{Key: "A", Severity: "MAJOR"}, {Key: "B", Severity: "blocker"}, {Key: "C", Severity: "MAJOR"}}
+
+	status := newCommitStatus(cfg, i18n.English(), links, sonar.QualityReport{QualityGateStatus: "failed", OverallCoverage: 80.5, NewCodeCoverage: 70}, issues)
+
+	expected := gitlab.CommitStatus{
+		Name:        "sonarqube/quality-gate",
+		State:       "failed",
+		Description: "Quality gate failed, coverage 80.50%, new code coverage 70.00%, 3 issues (BLOCKER: 1, MAJOR: 2)",
+		TargetURL:   "https://sonar.example.com/dashboard?id=app",
+	}
+	if status != expected {
+		t.Fatalf("expected %+v, got %+v", expected, status)
+	}
+
+	status = newCommitStatus(cfg, i18n.English(), commentLinks{}, sonar.QualityReport{QualityGateStatus: "unknown"}, nil)
+	if status.State != "canceled" || status.TargetURL != "" || status.Description != "Quality gate warning, coverage 0.00%, new code coverage 0.00%, 0 issues" {
+		t.Fatalf("expected the warning state without a target URL, got %+v", status)
+	}
+}
+
+func TestRunWithCommitStatus(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		args     []string
+		current  string
+		missing  bool
+		statuses []string
+		log      string
+	}{
+		{
+			name:     "published",
+			args:     []string{"--commit-status"},
+			statuses: []string{"head: state=success name=sonarqube/quality-gate"},
+			log:      `Set commit status "sonarqube/quality-gate" on head to success`,
+		},
+		{
+			name:     "missing commit",
+			args:     []string{"--commit-status"},
+			missing:  true,
+			statuses: []string{"head: state=success name=sonarqube/quality-gate"},
+			log:      `Set commit status "sonarqube/quality-gate" on head to success`,
+		},
+		{
+			name:     "description differs",
+			args:     []string{"--commit-status"},
+			current:  `[{"id":3,"name":"sonarqube/quality-gate","status":"success","description":"Quality gate passed, coverage 80.50%, new code coverage 70.00%, 2 issues (MAJOR: 2)","target_url":"SONAR_URL/dashboard?id=project"}]`,
+			statuses: []string{"head: state=success name=sonarqube/quality-gate"},
+			log:      `Set commit status "sonarqube/quality-gate" on head to success`,
+		},
+		{
+			name:     "target URL differs",
+			args:     []string{"--commit-status"},
+			current:  `[{"id":3,"name":"sonarqube/quality-gate","status":"success","description":"Quality gate passed, coverage 80.50%, new code coverage 70.00%, 1 issues (MAJOR: 1)","target_url":"https://old.example.com"}]`,
+			statuses: []string{"head: state=success name=sonarqube/quality-gate"},
+			log:      `Set commit status "sonarqube/quality-gate" on head to success`,
+		},
+		{
+			name:    "unchanged",
+			args:    []string{"--commit-status"},
+			current: `[{"id":3,"name":"sonarqube/quality-gate","status":"success","description":"Quality gate passed, coverage 80.50%, new code coverage 70.00%, 1 issues (MAJOR: 1)","target_url":"SONAR_URL/dashboard?id=project"}]`,
+			log:     `Commit status "sonarqube/quality-gate" on head is already success`,
+		},
+		{
+			name: "dry run",
+			args: []string{"--commit-status", "--commit-status-name=ci/sonar", "--dry-run"},
+			log:  `Planned commit status "ci/sonar" on head: success, Quality gate passed, coverage 80.50%, new code coverage 70.00%, 1 issues (MAJOR: 1)`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu       sync.Mutex
+				statuses []string
+			)
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = r.ParseForm()
+				mu.Lock()
+				defer mu.Unlock()
+
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+					_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/repository/commits/head/statuses":
+					if tc.missing {
+						http.Error(w, `{"message":"404 Commit Not Found"}`, http.StatusNotFound)
+						return
+					}
+					_, _ = w.Write([]byte(strings.ReplaceAll(cmp.Or(tc.current, `[]`), "SONAR_URL", server.URL)))
+				case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/statuses/"):
+					statuses = append(statuses, strings.TrimPrefix(r.URL.Path, "/api/v4/projects/100/statuses/")+
+						": state="+r.PostForm.Get("state")+" name="+r.PostForm.Get("name"))
+					w.WriteHeader(http.StatusCreated)
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+					_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}]}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+					_, _ = w.Write([]byte(`[]`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+					_, _ = w.Write([]byte(`[]`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+					_, _ = w.Write([]byte(`[]`))
+				case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/draft_notes"):
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"id":1}`))
+				case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+					w.WriteHeader(http.StatusCreated)
+				case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+					_, _ = w.Write([]byte(`{"valid":true}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+					_, _ = w.Write([]byte(`10.6.0.92116`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+					_, _ = w.Write([]byte(`{"issues":[{"key":"ISSUE-1","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"first","component":"project:main.go","line":12}],"paging":{"pageIndex":1,"pageSize":500,"total":1}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+					_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+					_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+					http.Error(w, "unexpected", http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			var output bytes.Buffer
+			err := runWith(
+				append(draftNotesTestArgs(server.URL), tc.args...),
+				func(string) string { return "" },
+				&output,
+			)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !reflect.DeepEqual(statuses, tc.statuses) {
+				t.Fatalf("expected commit statuses %q, got %q", tc.statuses, statuses)
+			}
+			assertCommentContains(t, output.String(), tc.log)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// inlinePostWorkers bounds concurrent inline discussion requests to GitLab.
+const inlinePostWorkers = 4
+
+// inlinePostInterval spaces inline discussion requests to stay below GitLab rate limits.
+const inlinePostInterval = 100 * time.Millisecond
+
+// updateTimeout bounds the requests that follow the read phase.
+const updateTimeout = 30 * time.Second
+
+// fetchGroup runs independent fetches concurrently. The first error cancels the
+// shared context and is the one returned by Wait.
+type fetchGroup struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+func newFetchGroup(ctx context.Context) (*fetchGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &fetchGroup{cancel: cancel}, ctx
+}
+
+func (g *fetchGroup) Go(fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := fn(); err != nil {
+			g.once.Do(func() {
+				g.err = err
+				g.cancel()
+			})
+		}
+	}()
+}
+
+func (g *fetchGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// fetchTimeoutError names --fetch-timeout when the read phase ran out of time.
+func fetchTimeoutError(err error, timeout time.Duration) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w (read phase exceeded --fetch-timeout=%s)", err, timeout)
+	}
+
+	return err
+}
+
+type phaseTiming struct {
+	name     string
+	started  time.Time
+	duration time.Duration
+}
+
+// phaseTimings collects how long each phase of a run took; it is safe for
+// concurrent use by the fetch group.
+type phaseTimings struct {
+	mu      sync.Mutex
+	entries []phaseTiming
+}
+
+func (t *phaseTimings) measure(name string, fn func() error) error {
+	started := time.Now()
+	err := fn()
+
+	t.mu.Lock()
+	t.entries = append(t.entries, phaseTiming{name: name, started: started, duration: time.Since(started)})
+	t.mu.Unlock()
+
+	return err
+}
+
+func (t *phaseTimings) write(stdout io.Writer) error {
+	t.mu.Lock()
+	entries := make([]phaseTiming, len(t.entries))
+	copy(entries, t.entries)
+	t.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].started.Before(entries[j].started)
+	})
+
+	if err := writeOutput(stdout, "Timing breakdown:\n"); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := writeOutput(stdout, "  %s: %s\n", entry.name, entry.duration.Round(time.Millisecond)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runRateLimited calls fn for every index in [0, count) from a bounded pool of
+// workers, starting at most one call per interval. Results are stored by index
+// so callers can process them in input order. The first error for which fatal
+// returns true cancels the remaining calls; its index is returned, or -1 if none.
+func runRateLimited(
+	ctx context.Context,
+	count int,
+	workers int,
+	interval time.Duration,
+	fn func(ctx context.Context, index int) error,
+	fatal func(error) bool,
+) ([]error, int) {
+	results := make([]error, count)
+	fatalIndex := -1
+	if count == 0 {
+		return results, fatalIndex
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+	)
+	jobs := make(chan int)
+	for range min(workers, count) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				if err := ctx.Err(); err != nil {
+					results[index] = err
+					continue
+				}
+
+				err := fn(ctx, index)
+				results[index] = err
+				if err != nil && fatal(err) {
+					once.Do(func() {
+						fatalIndex = index
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+	for index := 0; index < count; index++ {
+		if index > 0 {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+			}
+		}
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, fatalIndex
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchGroupReturnsFirstErrorAndCancelsOthers(t *testing.T) {
+	t.Parallel()
+
+	group, ctx := newFetchGroup(context.Background())
+	firstErr := errors.New("first failure")
+
+	group.Go(func() error {
+		return firstErr
+	})
+	group.Go(func() error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return errors.New("fetch was not cancelled")
+		}
+	})
+
+	if err := group.Wait(); !errors.Is(err, firstErr) {
+		t.Fatalf("expected first error, got %v", err)
+	}
+}
+
+func TestFetchGroupWithoutErrors(t *testing.T) {
+	t.Parallel()
+
+	group, _ := newFetchGroup(context.Background())
+	var calls int32
+	for range 3 {
+		group.Go(func() error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRunRateLimitedKeepsResultsInInputOrder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		active    int32
+		maxActive int32
+	)
+	nonFatal := errors.New("non-fatal")
+	results, fatalIndex := runRateLimited(
+		context.Background(),
+		6,
+		2,
+		time.Millisecond,
+		func(ctx context.Context, index int) error {
+			current := atomic.AddInt32(&active, 1)
+			for {
+				observed := atomic.LoadInt32(&maxActive)
+				if current <= observed || atomic.CompareAndSwapInt32(&maxActive, observed, current) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(6-index) * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+
+			if index%2 == 1 {
+				return nonFatal
+			}
+			return nil
+		},
+		func(error) bool { return false },
+	)
+
+	if fatalIndex != -1 {
+		t.Fatalf("expected no fatal error, got index %d", fatalIndex)
+	}
+	for index, err := range results {
+		if index%2 == 1 && !errors.Is(err, nonFatal) {
+			t.Fatalf("result %d: expected non-fatal error, got %v", index, err)
+		}
+		if index%2 == 0 && err != nil {
+			t.Fatalf("result %d: expected success, got %v", index, err)
+		}
+	}
+	if maxActive > 2 {
+		t.Fatalf("expected at most 2 concurrent calls, got %d", maxActive)
+	}
+}
+
+func TestRunRateLimitedStopsOnFatalError(t *testing.T) {
+	t.Parallel()
+
+	fatalErr := errors.New("fatal")
+	var calls int32
+	results, fatalIndex := runRateLimited(
+		context.Background(),
+		20,
+		1,
+		time.Millisecond,
+		func(ctx context.Context, index int) error {
+			atomic.AddInt32(&calls, 1)
+			if index == 2 {
+				return fatalErr
+			}
+			return nil
+		},
+		func(err error) bool { return errors.Is(err, fatalErr) },
+	)
+
+	if fatalIndex != 2 {
+		t.Fatalf("expected fatal index 2, got %d", fatalIndex)
+	}
+	if !errors.Is(results[2], fatalErr) {
+		t.Fatalf("expected fatal error at index 2, got %v", results[2])
+	}
+	if calls != 3 {
+		t.Fatalf("expected calls to stop after fatal error, got %d", calls)
+	}
+	if !errors.Is(results[19], context.Canceled) {
+		t.Fatalf("expected remaining calls to be cancelled, got %v", results[19])
+	}
+}
+
+func TestPhaseTimingsWriteInStartOrder(t *testing.T) {
+	t.Parallel()
+
+	timings := &phaseTimings{}
+	_ = timings.measure("first", func() error { return nil })
+	_ = timings.measure("second", func() error { return nil })
+
+	var output bytes.Buffer
+	if err := timings.write(&output); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	text := output.String()
+	if !strings.HasPrefix(text, "Timing breakdown:\n  first: ") || !strings.Contains(text, "\n  second: ") {
+		t.Fatalf("unexpected timing output %q", text)
+	}
+}
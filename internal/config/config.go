@@ -7,23 +7,196 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"sonar-gitlab-commenter/internal/i18n"
 	"sonar-gitlab-commenter/internal/sonar"
 )
 
+const (
+	// IssueScopeProject fetches every project issue and filters them by the MR diff.
+	IssueScopeProject = "project"
+	// IssueScopeDiff fetches issues only for the files changed in the MR.
+	IssueScopeDiff = "diff"
+)
+
+// CommandTriage is the subcommand that only applies /sonar commands from MR
+// discussions.
+const CommandTriage = "triage"
+
+// DefaultSonarCommandRole is the lowest project role allowed to run /sonar
+// commands.
+const DefaultSonarCommandRole = "developer"
+
+// roleAccessLevels maps GitLab project roles to their access levels.
+var roleAccessLevels = map[string]int{
+	"guest":      10,
+	"reporter":   20,
+	"developer":  30,
+	"maintainer": 40,
+	"owner":      50,
+}
+
+// RoleAccessLevel returns the GitLab access level of a project role.
+func RoleAccessLevel(role string) int {
+	return roleAccessLevels[role]
+}
+
+// DefaultFetchTimeout bounds the read phase. Narrowed issue searches of large
+// projects take many requests, so it is well above the time of a single call.
+const DefaultFetchTimeout = 5 * time.Minute
+
+// DefaultWriteTimeout bounds the posting of inline discussions and /sonar command
+// replies.
+const DefaultWriteTimeout = 2 * time.Minute
+
+// DefaultSummaryFileRows is the number of per-file summary rows shown before the
+// table is collapsed.
+const DefaultSummaryFileRows = 10
+
+// Default reply keywords that choose the SonarQube transition of a discussion
+// resolved by a reviewer.
+const (
+	DefaultAcceptKeyword        = "won't fix"
+	DefaultFalsePositiveKeyword = "false positive"
+)
+
+const (
+	// InlineGroupingNone posts one discussion per issue.
+	InlineGroupingNone = "none"
+	// InlineGroupingLine merges issues reported on the same file line.
+	InlineGroupingLine = "line"
+	// InlineGroupingRuleFile merges issues of the same rule within a file.
+	InlineGroupingRuleFile = "rule-file"
+)
+
+const (
+	// ResolvePolicyAlways resolves every tool discussion whose issues are gone.
+	ResolvePolicyAlways = "always"
+	// ResolvePolicySkipDiscussed leaves discussions with human replies unresolved.
+	ResolvePolicySkipDiscussed = "skip-discussed"
+	// ResolvePolicyReopen also reopens discussions that a person resolved while
+	// their issues are still reported.
+	ResolvePolicyReopen = "reopen"
+)
+
+const (
+	// CreateIssuesPerIssue creates one GitLab issue per SonarQube issue.
+	CreateIssuesPerIssue = "issue"
+	// CreateIssuesPerRule creates one GitLab issue per violated rule.
+	CreateIssuesPerRule = "rule"
+)
+
+// Defaults of the GitLab issues created for findings that stay in the summary.
+const (
+	DefaultCreateIssuesSeverity = "BLOCKER"
+	DefaultCreateIssuesLabels   = "sonarqube"
+)
+
+// DefaultCommitStatusName is the name of the commit status set with --commit-status.
+const DefaultCommitStatusName = "sonarqube/quality-gate"
+
+// commitStatusStates are the states GitLab accepts for commit statuses.
+var commitStatusStates = []string{"pending", "running", "success", "failed", "canceled"}
+
+// DefaultCommitStatusStates maps quality gate statuses to commit status states.
+func DefaultCommitStatusStates() map[string]string {
+	return map[string]string{"passed": "success", "failed": "failed", "warning": "success"}
+}
+
 type Config struct {
-	SonarURL          string
-	SonarToken        string
-	SonarProjectKey   string
-	SeverityThreshold string
-	DryRun            bool
-	Logs              bool
-	GitLabURL         string
-	GitLabToken       string
-	GitLabProjectID   int
-	GitLabMRIID       int
+	SonarURL        string
+	SonarToken      string
+	SonarProjectKey string
+	// SonarOrganization is required by SonarCloud and ignored by SonarQube.
+	SonarOrganization string
+	// SonarAuthScheme is either sonar.AuthSchemeBasic or sonar.AuthSchemeBearer.
+	SonarAuthScheme string
+	// SonarBranch and SonarPullRequest select the analysis that issues, the quality
+	// gate and links to the SonarQube UI come from.
+	SonarBranch      string
+	SonarPullRequest string
+	// CompareTargetBranch posts only issues missing from the target branch analysis,
+	// read from SonarTargetProjectKey or, when empty, from TargetBranch of the project.
+	CompareTargetBranch   bool
+	TargetBranch          string
+	SonarTargetProjectKey string
+	SeverityThreshold     string
+	// ImpactSeverityThresholds maps a software quality to the minimum impact severity.
+	ImpactSeverityThresholds map[string]string
+	IssueScope               string
+	InlineGrouping           string
+	// MaxInlineComments and MaxCommentsPerFile cap inline discussions; 0 means no limit.
+	MaxInlineComments  int
+	MaxCommentsPerFile int
+	SummaryFileRows    int
+	// SyncResolutions transitions SonarQube issues of discussions resolved by a
+	// reviewer whose reply starts with AcceptKeyword or FalsePositiveKeyword.
+	SyncResolutions      bool
+	AcceptKeyword        string
+	FalsePositiveKeyword string
+	// SonarCommands applies /sonar commands replied to tool discussions; Triage
+	// (the triage subcommand) does only that. Commands of people below
+	// SonarCommandRole in the project are rejected.
+	SonarCommands    bool
+	Triage           bool
+	SonarCommandRole string
+	// MirrorReplies copies human replies in tool discussions to SonarQube issue
+	// comments.
+	MirrorReplies bool
+	// AssignAuthor assigns the issues posted inline to the SonarQube login of the
+	// MR author, looked up in SonarUserMapPath first. /sonar assign maps its
+	// GitLab username through the same file.
+	AssignAuthor     bool
+	SonarUserMapPath string
+	// CreateIssues opens GitLab issues, per issue or per rule, for summary-only
+	// findings of at least CreateIssuesSeverity and, when set, of CreateIssuesTypes.
+	// Empty disables it.
+	CreateIssues         string
+	CreateIssuesSeverity string
+	CreateIssuesTypes    []string
+	// CreateIssuesLabels is a comma-separated GitLab label list.
+	CreateIssuesLabels string
+	// CreateIssuesDueDays sets the due date that many days ahead; 0 sets none.
+	CreateIssuesDueDays int
+	// CommitStatus sets a commit status named CommitStatusName on the MR head
+	// commit; CommitStatusStates maps quality gate statuses to its state.
+	CommitStatus       bool
+	CommitStatusName   string
+	CommitStatusStates map[string]string
+	// StatusCheckName selects the external status check of the MR that receives
+	// the quality gate result; empty disables it.
+	StatusCheckName string
+	// LabelRules set and remove merge request labels from the analysis results.
+	LabelRules []LabelRule
+	// ResolvePolicy decides how tool discussions with human participation are
+	// resolved; discussions with human replies still get the explanatory reply.
+	ResolvePolicy string
+	// PipelineID and PipelineURL identify the pipeline in replies to fixed issues.
+	PipelineID  string
+	PipelineURL string
+	// Template paths override the built-in Markdown; empty means the default.
+	InlineTemplatePath   string
+	SummaryTemplatePath  string
+	OverflowTemplatePath string
+	ResolvedTemplatePath string
+	// FetchTimeout bounds the concurrent read of GitLab and SonarQube data.
+	FetchTimeout time.Duration
+	// WriteTimeout bounds posting inline discussions and /sonar command replies;
+	// whatever is left when it runs out is skipped and reported.
+	WriteTimeout time.Duration
+	// Locale selects the i18n catalog for comments and the action log.
+	Locale          string
+	DryRun          bool
+	DraftNotes      bool
+	Logs            bool
+	GitLabURL       string
+	GitLabToken     string
+	GitLabProjectID int
+	GitLabMRIID     int
 }
 
 type HelpError struct {
@@ -36,14 +209,28 @@ func (e *HelpError) Error() string {
 
 func Parse(args []string, getenv func(string) string) (Config, error) {
 	cfg := Config{
-		SonarURL:        strings.TrimSpace(getenv("SONAR_HOST_URL")),
-		SonarToken:      strings.TrimSpace(getenv("SONAR_TOKEN")),
-		SonarProjectKey: strings.TrimSpace(getenv("SONAR_PROJECT_KEY")),
-		GitLabURL:       strings.TrimSpace(getenv("GITLAB_URL")),
-		GitLabToken:     strings.TrimSpace(getenv("GITLAB_TOKEN")),
+		SonarURL:              strings.TrimSpace(getenv("SONAR_HOST_URL")),
+		SonarToken:            strings.TrimSpace(getenv("SONAR_TOKEN")),
+		SonarProjectKey:       strings.TrimSpace(getenv("SONAR_PROJECT_KEY")),
+		SonarOrganization:     strings.TrimSpace(getenv("SONAR_ORGANIZATION")),
+		SonarAuthScheme:       strings.TrimSpace(getenv("SONAR_AUTH_SCHEME")),
+		SonarBranch:           strings.TrimSpace(getenv("SONAR_BRANCH")),
+		SonarPullRequest:      strings.TrimSpace(getenv("SONAR_PULL_REQUEST")),
+		TargetBranch:          strings.TrimSpace(getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME")),
+		SonarTargetProjectKey: strings.TrimSpace(getenv("SONAR_TARGET_PROJECT_KEY")),
+		PipelineID:            strings.TrimSpace(getenv("CI_PIPELINE_ID")),
+		PipelineURL:           strings.TrimSpace(getenv("CI_PIPELINE_URL")),
+		Locale:                strings.TrimSpace(getenv("COMMENTER_LOCALE")),
+		GitLabURL:             strings.TrimSpace(getenv("GITLAB_URL")),
+		GitLabToken:           strings.TrimSpace(getenv("GITLAB_TOKEN")),
 	}
 	projectID := strings.TrimSpace(getenv("CI_PROJECT_ID"))
 	mrIID := strings.TrimSpace(getenv("CI_MERGE_REQUEST_IID"))
+	impactSeverityThreshold := ""
+	keepDiscussedOpen := false
+	createIssuesTypes := ""
+	labelRules := ""
+	commitStatusStates := ""
 	var usageBuffer bytes.Buffer
 
 	fs := flag.NewFlagSet("sonar-gitlab-commenter", flag.ContinueOnError)
@@ -55,14 +242,62 @@ func Parse(args []string, getenv func(string) string) (Config, error) {
 	fs.StringVar(&cfg.SonarURL, "sonar-url", cfg.SonarURL, "SonarQube server URL (env: SONAR_HOST_URL)")
 	fs.StringVar(&cfg.SonarToken, "sonar-token", cfg.SonarToken, "SonarQube access token (env: SONAR_TOKEN)")
 	fs.StringVar(&cfg.SonarProjectKey, "sonar-project-key", cfg.SonarProjectKey, "SonarQube project key (env: SONAR_PROJECT_KEY)")
+	fs.StringVar(&cfg.SonarOrganization, "sonar-organization", cfg.SonarOrganization, "SonarCloud organization key (env: SONAR_ORGANIZATION)")
+	fs.StringVar(&cfg.SonarAuthScheme, "sonar-auth-scheme", cfg.SonarAuthScheme, "SonarQube token auth scheme: basic or bearer (env: SONAR_AUTH_SCHEME)")
+	fs.StringVar(&cfg.SonarBranch, "sonar-branch", cfg.SonarBranch, "SonarQube branch analysis to read issues and the quality gate from (env: SONAR_BRANCH)")
+	fs.StringVar(&cfg.SonarPullRequest, "sonar-pull-request", cfg.SonarPullRequest, "SonarQube pull request analysis to read issues and the quality gate from (env: SONAR_PULL_REQUEST)")
+	fs.BoolVar(&cfg.CompareTargetBranch, "compare-target-branch", false, "Post only issues that the target branch analysis does not have")
+	fs.StringVar(&cfg.TargetBranch, "target-branch", cfg.TargetBranch, "Merge request target branch (env: CI_MERGE_REQUEST_TARGET_BRANCH_NAME)")
+	fs.StringVar(&cfg.SonarTargetProjectKey, "sonar-target-project-key", cfg.SonarTargetProjectKey, "SonarQube project key holding the target branch analysis (env: SONAR_TARGET_PROJECT_KEY)")
 	fs.StringVar(&cfg.SeverityThreshold, "severity-threshold", "", "Minimum SonarQube issue severity to include (INFO, MINOR, MAJOR, CRITICAL, BLOCKER)")
+	fs.StringVar(&impactSeverityThreshold, "impact-severity-threshold", "", "Minimum impact severity per software quality for SonarQube 10.2+ (HIGH or SECURITY:LOW,MAINTAINABILITY:HIGH)")
+	fs.StringVar(&cfg.IssueScope, "issue-scope", IssueScopeProject, "Which SonarQube issues to fetch: project or diff (only files changed in the MR)")
+	fs.StringVar(&cfg.InlineGrouping, "inline-grouping", InlineGroupingNone, "How to merge inline issues into discussions: none, line or rule-file")
+	fs.IntVar(&cfg.MaxInlineComments, "max-inline-comments", 0, "Maximum number of inline discussions per run, 0 for no limit")
+	fs.IntVar(&cfg.MaxCommentsPerFile, "max-comments-per-file", 0, "Maximum number of inline discussions per file, 0 for no limit")
+	fs.IntVar(&cfg.SummaryFileRows, "summary-file-rows", DefaultSummaryFileRows, "Collapse the per-file summary table when it has more rows, 0 to never collapse")
+	fs.BoolVar(&cfg.SyncResolutions, "sync-resolutions", false, "Transition SonarQube issues of discussions resolved by a reviewer with a keyword reply")
+	fs.StringVar(&cfg.AcceptKeyword, "accept-keyword", DefaultAcceptKeyword, "Reply keyword that accepts the issue in SonarQube (won't fix)")
+	fs.StringVar(&cfg.FalsePositiveKeyword, "false-positive-keyword", DefaultFalsePositiveKeyword, "Reply keyword that marks the issue as a false positive in SonarQube")
+	fs.BoolVar(&cfg.SonarCommands, "sonar-commands", false, "Apply /sonar commands replied to tool discussions")
+	fs.StringVar(&cfg.SonarCommandRole, "sonar-command-role", DefaultSonarCommandRole, "Lowest project role allowed to run /sonar commands: guest, reporter, developer, maintainer or owner")
+	fs.BoolVar(&cfg.MirrorReplies, "mirror-replies", false, "Copy human replies in tool discussions to SonarQube issue comments")
+	fs.BoolVar(&cfg.AssignAuthor, "assign-author", false, "Assign unassigned issues posted inline to the SonarQube user of the MR author")
+	fs.StringVar(&cfg.SonarUserMapPath, "sonar-user-map", "", "Path to a file mapping GitLab usernames to SonarQube logins, one gitlab=sonar pair per line")
+	fs.StringVar(&cfg.CreateIssues, "create-issues", "", "Create GitLab issues for matching summary-only findings: issue or rule")
+	fs.StringVar(&cfg.CreateIssuesSeverity, "create-issues-severity", DefaultCreateIssuesSeverity, "Minimum severity of findings that get a GitLab issue")
+	fs.StringVar(&createIssuesTypes, "create-issues-types", "", "Comma-separated issue types that get a GitLab issue, empty for all")
+	fs.StringVar(&cfg.CreateIssuesLabels, "create-issues-labels", DefaultCreateIssuesLabels, "Comma-separated labels of created GitLab issues")
+	fs.IntVar(&cfg.CreateIssuesDueDays, "create-issues-due-days", 0, "Due date of created GitLab issues in days from now, 0 for none")
+	fs.BoolVar(&cfg.CommitStatus, "commit-status", false, "Set a commit status with the quality gate on the MR head commit")
+	fs.StringVar(&cfg.CommitStatusName, "commit-status-name", DefaultCommitStatusName, "Name of the commit status")
+	fs.StringVar(&commitStatusStates, "commit-status-states", "", "Comma-separated gate=state overrides (default passed=success,failed=failed,warning=success)")
+	fs.StringVar(&cfg.StatusCheckName, "status-check", "", "Name of the MR external status check that receives the quality gate result")
+	fs.StringVar(&labelRules, "label-rules", "", "Comma-separated label=condition rules for merge request labels (sonar::gate-failed=gate-failed,sonar::coverage-low=new-coverage<80)")
+	fs.StringVar(&cfg.InlineTemplatePath, "inline-template", "", "Path to a Go text/template file for inline discussions")
+	fs.StringVar(&cfg.SummaryTemplatePath, "summary-template", "", "Path to a Go text/template file for the summary note")
+	fs.StringVar(&cfg.OverflowTemplatePath, "overflow-template", "", "Path to a Go text/template file for the summary overflow section")
+	fs.StringVar(&cfg.ResolvedTemplatePath, "resolved-template", "", "Path to a Go text/template file for the reply to discussions whose issues are gone")
+	fs.StringVar(&cfg.ResolvePolicy, "resolve-policy", ResolvePolicyAlways, "How to treat tool discussions with human participation: always, skip-discussed or reopen")
+	fs.BoolVar(&keepDiscussedOpen, "keep-discussed-open", false, "Alias of --resolve-policy=skip-discussed")
+	fs.StringVar(&cfg.PipelineID, "pipeline-id", cfg.PipelineID, "Pipeline ID mentioned in replies to fixed issues (env: CI_PIPELINE_ID)")
+	fs.StringVar(&cfg.PipelineURL, "pipeline-url", cfg.PipelineURL, "Pipeline URL linked in replies to fixed issues (env: CI_PIPELINE_URL)")
+	fs.DurationVar(&cfg.FetchTimeout, "fetch-timeout", DefaultFetchTimeout, "Time limit for reading GitLab and SonarQube data")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", DefaultWriteTimeout, "Time limit for posting inline discussions and /sonar command replies")
+	fs.StringVar(&cfg.Locale, "locale", cfg.Locale, "Language of comments and the action log: en or ru (env: COMMENTER_LOCALE)")
 	fs.BoolVar(&cfg.DryRun, "dry-run", false, "Run without resolving or posting GitLab comments")
+	fs.BoolVar(&cfg.DraftNotes, "draft-notes", false, "Create inline comments as GitLab draft notes and publish them as one review")
 	fs.BoolVar(&cfg.Logs, "logs", false, "Print detailed logs including fetched SonarQube issues")
 	fs.StringVar(&cfg.GitLabURL, "gitlab-url", cfg.GitLabURL, "GitLab server URL (env: GITLAB_URL)")
 	fs.StringVar(&cfg.GitLabToken, "gitlab-token", cfg.GitLabToken, "GitLab access token (env: GITLAB_TOKEN)")
 	fs.StringVar(&projectID, "project-id", projectID, "GitLab project ID (env: CI_PROJECT_ID)")
 	fs.StringVar(&mrIID, "mr-iid", mrIID, "GitLab merge request IID (env: CI_MERGE_REQUEST_IID)")
 
+	if len(args) > 0 && args[0] == CommandTriage {
+		cfg.Triage = true
+		args = args[1:]
+	}
+
 	if err := fs.Parse(args); err != nil {
 		if errors.Is(err, flag.ErrHelp) {
 			return Config{}, &HelpError{Message: helpOutput(&usageBuffer)}
@@ -77,6 +312,20 @@ func Parse(args []string, getenv func(string) string) (Config, error) {
 	cfg.SonarURL = strings.TrimSpace(cfg.SonarURL)
 	cfg.SonarToken = strings.TrimSpace(cfg.SonarToken)
 	cfg.SonarProjectKey = strings.TrimSpace(cfg.SonarProjectKey)
+	cfg.SonarOrganization = strings.TrimSpace(cfg.SonarOrganization)
+	cfg.SonarAuthScheme = strings.ToLower(strings.TrimSpace(cfg.SonarAuthScheme))
+	cfg.SonarBranch = strings.TrimSpace(cfg.SonarBranch)
+	cfg.SonarPullRequest = strings.TrimSpace(cfg.SonarPullRequest)
+	cfg.TargetBranch = strings.TrimSpace(cfg.TargetBranch)
+	cfg.SonarTargetProjectKey = strings.TrimSpace(cfg.SonarTargetProjectKey)
+	cfg.AcceptKeyword = strings.TrimSpace(cfg.AcceptKeyword)
+	cfg.FalsePositiveKeyword = strings.TrimSpace(cfg.FalsePositiveKeyword)
+	cfg.InlineTemplatePath = strings.TrimSpace(cfg.InlineTemplatePath)
+	cfg.SummaryTemplatePath = strings.TrimSpace(cfg.SummaryTemplatePath)
+	cfg.OverflowTemplatePath = strings.TrimSpace(cfg.OverflowTemplatePath)
+	cfg.ResolvedTemplatePath = strings.TrimSpace(cfg.ResolvedTemplatePath)
+	cfg.PipelineID = strings.TrimSpace(cfg.PipelineID)
+	cfg.PipelineURL = strings.TrimSpace(cfg.PipelineURL)
 	cfg.GitLabURL = strings.TrimSpace(cfg.GitLabURL)
 	cfg.GitLabToken = strings.TrimSpace(cfg.GitLabToken)
 	projectID = strings.TrimSpace(projectID)
@@ -109,6 +358,36 @@ func Parse(args []string, getenv func(string) string) (Config, error) {
 		return Config{}, fmt.Errorf("invalid GitLab URL %q: %w", cfg.GitLabURL, err)
 	}
 
+	isSonarCloud := sonar.IsSonarCloudURL(cfg.SonarURL)
+	if isSonarCloud && cfg.SonarOrganization == "" {
+		return Config{}, fmt.Errorf(
+			"missing required SonarCloud configuration: sonar-organization (set env var SONAR_ORGANIZATION or flag --sonar-organization)",
+		)
+	}
+	if cfg.SonarAuthScheme == "" {
+		cfg.SonarAuthScheme = sonar.AuthSchemeBasic
+		if isSonarCloud {
+			cfg.SonarAuthScheme = sonar.AuthSchemeBearer
+		}
+	}
+	if !sonar.IsValidAuthScheme(cfg.SonarAuthScheme) {
+		return Config{}, fmt.Errorf(
+			"invalid value for --sonar-auth-scheme: %q (allowed: %s, %s)",
+			cfg.SonarAuthScheme,
+			sonar.AuthSchemeBasic,
+			sonar.AuthSchemeBearer,
+		)
+	}
+
+	if cfg.SonarBranch != "" && cfg.SonarPullRequest != "" {
+		return Config{}, errors.New("--sonar-branch and --sonar-pull-request are mutually exclusive")
+	}
+	if cfg.CompareTargetBranch && cfg.TargetBranch == "" && cfg.SonarTargetProjectKey == "" {
+		return Config{}, errors.New(
+			"--compare-target-branch requires the target branch (set env var CI_MERGE_REQUEST_TARGET_BRANCH_NAME or flag --target-branch) or --sonar-target-project-key",
+		)
+	}
+
 	parsedProjectID, err := strconv.Atoi(projectID)
 	if err != nil || parsedProjectID <= 0 {
 		return Config{}, fmt.Errorf("invalid project ID %q: expected positive integer", projectID)
@@ -128,9 +407,183 @@ func Parse(args []string, getenv func(string) string) (Config, error) {
 		)
 	}
 
+	cfg.IssueScope = strings.ToLower(strings.TrimSpace(cfg.IssueScope))
+	if cfg.IssueScope != IssueScopeProject && cfg.IssueScope != IssueScopeDiff {
+		return Config{}, fmt.Errorf(
+			"invalid value for --issue-scope: %q (allowed: %s, %s)",
+			cfg.IssueScope,
+			IssueScopeProject,
+			IssueScopeDiff,
+		)
+	}
+
+	cfg.InlineGrouping = strings.ToLower(strings.TrimSpace(cfg.InlineGrouping))
+	switch cfg.InlineGrouping {
+	case InlineGroupingNone, InlineGroupingLine, InlineGroupingRuleFile:
+	default:
+		return Config{}, fmt.Errorf(
+			"invalid value for --inline-grouping: %q (allowed: %s, %s, %s)",
+			cfg.InlineGrouping,
+			InlineGroupingNone,
+			InlineGroupingLine,
+			InlineGroupingRuleFile,
+		)
+	}
+
+	cfg.ResolvePolicy = strings.ToLower(strings.TrimSpace(cfg.ResolvePolicy))
+	if keepDiscussedOpen {
+		if cfg.ResolvePolicy != ResolvePolicyAlways && cfg.ResolvePolicy != ResolvePolicySkipDiscussed {
+			return Config{}, fmt.Errorf("--keep-discussed-open conflicts with --resolve-policy=%s", cfg.ResolvePolicy)
+		}
+		cfg.ResolvePolicy = ResolvePolicySkipDiscussed
+	}
+	switch cfg.ResolvePolicy {
+	case ResolvePolicyAlways, ResolvePolicySkipDiscussed, ResolvePolicyReopen:
+	default:
+		return Config{}, fmt.Errorf(
+			"invalid value for --resolve-policy: %q (allowed: %s, %s, %s)",
+			cfg.ResolvePolicy,
+			ResolvePolicyAlways,
+			ResolvePolicySkipDiscussed,
+			ResolvePolicyReopen,
+		)
+	}
+
+	cfg.SonarCommandRole = strings.ToLower(strings.TrimSpace(cfg.SonarCommandRole))
+	if RoleAccessLevel(cfg.SonarCommandRole) == 0 {
+		return Config{}, fmt.Errorf(
+			"invalid value for --sonar-command-role: %q (allowed: guest, reporter, developer, maintainer, owner)",
+			cfg.SonarCommandRole,
+		)
+	}
+
+	if cfg.MaxInlineComments < 0 {
+		return Config{}, fmt.Errorf("invalid value for --max-inline-comments: %d (expected 0 or a positive integer)", cfg.MaxInlineComments)
+	}
+	if cfg.MaxCommentsPerFile < 0 {
+		return Config{}, fmt.Errorf("invalid value for --max-comments-per-file: %d (expected 0 or a positive integer)", cfg.MaxCommentsPerFile)
+	}
+
+	if cfg.FetchTimeout <= 0 {
+		return Config{}, fmt.Errorf("invalid value for --fetch-timeout: %s (expected a positive duration)", cfg.FetchTimeout)
+	}
+
+	if cfg.WriteTimeout <= 0 {
+		return Config{}, fmt.Errorf("invalid value for --write-timeout: %s (expected a positive duration)", cfg.WriteTimeout)
+	}
+
+	if cfg.Locale == "" {
+		cfg.Locale = i18n.LocaleEnglish
+	}
+	catalog, ok := i18n.Lookup(cfg.Locale)
+	if !ok {
+		return Config{}, fmt.Errorf(
+			"invalid value for --locale: %q (allowed: %s)",
+			cfg.Locale,
+			strings.Join(i18n.Locales(), ", "),
+		)
+	}
+	cfg.Locale = catalog.Locale
+
+	if cfg.SummaryFileRows < 0 {
+		return Config{}, fmt.Errorf("invalid value for --summary-file-rows: %d (expected 0 or a positive integer)", cfg.SummaryFileRows)
+	}
+
+	if cfg.SyncResolutions {
+		if cfg.AcceptKeyword == "" || cfg.FalsePositiveKeyword == "" {
+			return Config{}, errors.New("--sync-resolutions requires non-empty --accept-keyword and --false-positive-keyword")
+		}
+		if strings.EqualFold(cfg.AcceptKeyword, cfg.FalsePositiveKeyword) {
+			return Config{}, fmt.Errorf("--accept-keyword and --false-positive-keyword must differ, both are %q", cfg.AcceptKeyword)
+		}
+	}
+
+	cfg.SonarUserMapPath = strings.TrimSpace(cfg.SonarUserMapPath)
+	if cfg.SonarUserMapPath != "" && !cfg.AssignAuthor && !cfg.SonarCommands && !cfg.Triage {
+		return Config{}, errors.New("--sonar-user-map requires --assign-author, --sonar-commands or triage")
+	}
+
+	cfg.CreateIssues = strings.ToLower(strings.TrimSpace(cfg.CreateIssues))
+	switch cfg.CreateIssues {
+	case "", CreateIssuesPerIssue, CreateIssuesPerRule:
+	default:
+		return Config{}, fmt.Errorf(
+			"invalid value for --create-issues: %q (allowed: %s, %s)",
+			cfg.CreateIssues,
+			CreateIssuesPerIssue,
+			CreateIssuesPerRule,
+		)
+	}
+	cfg.CreateIssuesSeverity = sonar.NormalizeSeverity(cfg.CreateIssuesSeverity)
+	if !sonar.IsValidSeverity(cfg.CreateIssuesSeverity) {
+		return Config{}, fmt.Errorf(
+			"invalid value for --create-issues-severity: %q (allowed: %s)",
+			cfg.CreateIssuesSeverity,
+			strings.Join(sonar.AllowedSeverities(), ", "),
+		)
+	}
+	for _, issueType := range strings.Split(createIssuesTypes, ",") {
+		if issueType = strings.ToUpper(strings.TrimSpace(issueType)); issueType != "" {
+			cfg.CreateIssuesTypes = append(cfg.CreateIssuesTypes, issueType)
+		}
+	}
+	cfg.CreateIssuesLabels = strings.TrimSpace(cfg.CreateIssuesLabels)
+	if cfg.CreateIssuesDueDays < 0 {
+		return Config{}, fmt.Errorf("invalid value for --create-issues-due-days: %d (expected 0 or a positive integer)", cfg.CreateIssuesDueDays)
+	}
+
+	cfg.CommitStatusName = strings.TrimSpace(cfg.CommitStatusName)
+	if cfg.CommitStatusName == "" {
+		return Config{}, errors.New("--commit-status-name cannot be empty")
+	}
+	cfg.CommitStatusStates, err = parseCommitStatusStates(commitStatusStates)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid value for --commit-status-states: %w", err)
+	}
+
+	cfg.StatusCheckName = strings.TrimSpace(cfg.StatusCheckName)
+
+	cfg.LabelRules, err = parseLabelRules(labelRules)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid value for --label-rules: %w", err)
+	}
+
+	impactThresholds, err := sonar.ParseImpactThresholds(impactSeverityThreshold)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid value for --impact-severity-threshold: %w", err)
+	}
+	if len(impactThresholds) > 0 {
+		cfg.ImpactSeverityThresholds = impactThresholds
+	}
+
 	return cfg, nil
 }
 
+// parseCommitStatusStates applies comma-separated gate=state pairs over the
+// default mapping.
+func parseCommitStatusStates(value string) (map[string]string, error) {
+	states := DefaultCommitStatusStates()
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		gate, state, found := strings.Cut(part, "=")
+		gate = strings.ToLower(strings.TrimSpace(gate))
+		state = strings.ToLower(strings.TrimSpace(state))
+		if _, known := states[gate]; !found || !known {
+			return nil, fmt.Errorf("invalid pair %q (expected passed, failed or warning=state)", part)
+		}
+		if !slices.Contains(commitStatusStates, state) {
+			return nil, fmt.Errorf("unknown state %q (allowed: %s)", state, strings.Join(commitStatusStates, ", "))
+		}
+		states[gate] = state
+	}
+
+	return states, nil
+}
+
 func helpOutput(buffer *bytes.Buffer) string {
 	if buffer.Len() == 0 {
 		return helpText()
@@ -142,13 +595,72 @@ func helpOutput(buffer *bytes.Buffer) string {
 func helpText() string {
 	return `Usage:
   sonar-gitlab-commenter [flags]
+  sonar-gitlab-commenter triage [flags]    Only apply /sonar commands from MR discussions
 
 Flags:
   --sonar-url string             SonarQube server URL (env: SONAR_HOST_URL)
   --sonar-token string           SonarQube access token (env: SONAR_TOKEN)
   --sonar-project-key string     SonarQube project key (env: SONAR_PROJECT_KEY)
+  --sonar-organization string    SonarCloud organization key (env: SONAR_ORGANIZATION)
+  --sonar-auth-scheme string     Token auth scheme: basic or bearer (env: SONAR_AUTH_SCHEME)
+                                 Default: bearer for SonarCloud, basic otherwise
+  --sonar-branch string          Branch analysis to read issues and the quality gate from (env: SONAR_BRANCH)
+  --sonar-pull-request string    Pull request analysis to read issues and the quality gate from (env: SONAR_PULL_REQUEST)
+  --compare-target-branch        Post only issues missing from the target branch analysis (Community Edition)
+  --target-branch string         Target branch, analyzed as a branch of the project (env: CI_MERGE_REQUEST_TARGET_BRANCH_NAME)
+  --sonar-target-project-key string
+                                 Separate project holding the target branch analysis (env: SONAR_TARGET_PROJECT_KEY)
   --severity-threshold string    Minimum issue severity (INFO, MINOR, MAJOR, CRITICAL, BLOCKER)
+  --impact-severity-threshold string
+                                 Minimum impact severity per software quality, SonarQube 10.2+
+                                 (HIGH or SECURITY:LOW,RELIABILITY:MEDIUM,MAINTAINABILITY:HIGH)
+  --issue-scope string           Fetch issues for the whole project or only MR files (project, diff)
+  --inline-grouping string       Merge inline issues into one discussion (none, line, rule-file)
+  --max-inline-comments int      Maximum inline discussions per run, the rest go to the summary (0: no limit)
+  --max-comments-per-file int    Maximum inline discussions per file (0: no limit)
+  --summary-file-rows int        Collapse the per-file summary table above this many rows (default 10, 0: never)
+  --sync-resolutions             Transition SonarQube issues of discussions a reviewer resolved with a keyword reply
+  --accept-keyword string        Reply keyword that accepts the issue (default "won't fix")
+  --false-positive-keyword string
+                                 Reply keyword that marks the issue as false positive (default "false positive")
+  --sonar-commands               Apply /sonar falsepositive|accept|assign commands replied to tool discussions
+  --sonar-command-role string    Lowest project role allowed to run /sonar commands (default "developer")
+  --mirror-replies               Copy human replies in tool discussions to SonarQube issue comments
+  --assign-author                Assign unassigned issues posted inline to the SonarQube user of the MR author
+  --sonar-user-map string        File of gitlab-username=sonar-login lines for --assign-author and /sonar assign
+  --create-issues string         Create GitLab issues for matching summary-only findings (issue, rule)
+  --create-issues-severity string
+                                 Minimum severity of findings that get a GitLab issue (default BLOCKER)
+  --create-issues-types string   Comma-separated issue types that get a GitLab issue (default: all)
+  --create-issues-labels string  Comma-separated labels of created GitLab issues (default sonarqube)
+  --create-issues-due-days int   Due date of created GitLab issues in days from now (0: none)
+  --commit-status                Set a commit status with the quality gate on the MR head commit
+  --commit-status-name string    Name of the commit status (default sonarqube/quality-gate)
+  --commit-status-states string  Comma-separated gate=state overrides of passed=success,failed=failed,
+                                 warning=success; states are pending, running, success, failed, canceled
+  --status-check string          Name of the MR external status check that receives passed or failed
+                                 from the quality gate
+  --label-rules string           Comma-separated label=condition rules for MR labels; conditions are
+                                 gate-passed, gate-failed, gate-warning or <metric><op><number> with
+                                 metrics coverage, new-coverage, issues, blocker, critical, major,
+                                 minor, info and operators <, <=, >, >=
+  --inline-template string       Go text/template file for inline discussions
+  --summary-template string      Go text/template file for the summary note
+  --overflow-template string     Go text/template file for the summary overflow section
+  --resolved-template string     Go text/template file for the reply to discussions whose issues are gone
+  --resolve-policy string        Tool discussions with human participation (always, skip-discussed, reopen)
+                                 Default: always
+  --keep-discussed-open          Alias of --resolve-policy=skip-discussed
+  --pipeline-id string           Pipeline ID mentioned in replies to fixed issues (env: CI_PIPELINE_ID)
+  --pipeline-url string          Pipeline URL linked in replies to fixed issues (env: CI_PIPELINE_URL)
+  --fetch-timeout duration       Time limit for reading GitLab and SonarQube data, including narrowed
+                                 issue searches of large projects (default 5m)
+  --write-timeout duration       Time limit for posting inline discussions and /sonar command replies;
+                                 the rest are skipped and reported (default 2m)
+  --locale string                Language of comments and the action log: en, ru (env: COMMENTER_LOCALE)
+                                 Default: en
   --dry-run                      Run without resolving or posting GitLab comments
+  --draft-notes                  Create inline comments as draft notes and publish them as one review
   --logs                         Print detailed logs including fetched SonarQube issues
   --gitlab-url string            GitLab server URL (env: GITLAB_URL)
   --gitlab-token string          GitLab access token (env: GITLAB_TOKEN)
@@ -160,6 +672,15 @@ Environment Variables:
   SONAR_HOST_URL
   SONAR_TOKEN
   SONAR_PROJECT_KEY
+  SONAR_ORGANIZATION
+  SONAR_AUTH_SCHEME
+  SONAR_BRANCH
+  SONAR_PULL_REQUEST
+  SONAR_TARGET_PROJECT_KEY
+  CI_MERGE_REQUEST_TARGET_BRANCH_NAME
+  CI_PIPELINE_ID
+  CI_PIPELINE_URL
+  COMMENTER_LOCALE
   GITLAB_URL
   GITLAB_TOKEN
   CI_PROJECT_ID
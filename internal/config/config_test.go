@@ -2,8 +2,10 @@ package config
 
 import (
 	"errors"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseUsesEnvValues(t *testing.T) {
@@ -233,6 +235,457 @@ func TestParseSeverityThresholdRejectsUnsupportedValue(t *testing.T) {
 	}
 }
 
+func TestParseImpactSeverityThreshold(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]string{"--impact-severity-threshold=security:low,MAINTAINABILITY:HIGH"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if got := cfg.ImpactSeverityThresholds["SECURITY"]; got != "LOW" {
+		t.Fatalf("unexpected SECURITY threshold: %q", got)
+	}
+	if got := cfg.ImpactSeverityThresholds["MAINTAINABILITY"]; got != "HIGH" {
+		t.Fatalf("unexpected MAINTAINABILITY threshold: %q", got)
+	}
+	if _, ok := cfg.ImpactSeverityThresholds["RELIABILITY"]; ok {
+		t.Fatal("did not expect RELIABILITY threshold")
+	}
+}
+
+func TestParseImpactSeverityThresholdRejectsUnsupportedValue(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse([]string{"--impact-severity-threshold=SECURITY:SEVERE"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --impact-severity-threshold") {
+		t.Fatalf("expected impact threshold error, got %v", err)
+	}
+}
+
+func TestParseSonarAuthSchemeDefaultsToBasic(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.SonarAuthScheme != "basic" {
+		t.Fatalf("unexpected auth scheme: %q", cfg.SonarAuthScheme)
+	}
+	if cfg.SonarOrganization != "" {
+		t.Fatalf("unexpected organization: %q", cfg.SonarOrganization)
+	}
+}
+
+func TestParseSonarCloudDefaults(t *testing.T) {
+	t.Parallel()
+
+	env := baseEnv()
+	env["SONAR_HOST_URL"] = "https://sonarcloud.io"
+	env["SONAR_ORGANIZATION"] = "acme"
+
+	cfg, err := Parse(nil, mapGetenv(env))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.SonarOrganization != "acme" {
+		t.Fatalf("unexpected organization: %q", cfg.SonarOrganization)
+	}
+	if cfg.SonarAuthScheme != "bearer" {
+		t.Fatalf("expected bearer auth for SonarCloud, got %q", cfg.SonarAuthScheme)
+	}
+}
+
+func TestParseSonarCloudRequiresOrganization(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse([]string{"--sonar-url=https://sonarcloud.io"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "sonar-organization") {
+		t.Fatalf("expected missing organization error, got %v", err)
+	}
+}
+
+func TestParseSonarAuthSchemeRejectsUnsupportedValue(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse([]string{"--sonar-auth-scheme=digest"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --sonar-auth-scheme") {
+		t.Fatalf("expected auth scheme error, got %v", err)
+	}
+}
+
+func TestParseIssueScope(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.IssueScope != IssueScopeProject {
+		t.Fatalf("expected project issue scope by default, got %q", cfg.IssueScope)
+	}
+
+	cfg, err = Parse([]string{"--issue-scope=DIFF"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.IssueScope != IssueScopeDiff {
+		t.Fatalf("expected diff issue scope, got %q", cfg.IssueScope)
+	}
+
+	_, err = Parse([]string{"--issue-scope=repo"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --issue-scope") {
+		t.Fatalf("expected issue scope error, got %v", err)
+	}
+}
+
+func TestParseInlineGrouping(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.InlineGrouping != InlineGroupingNone {
+		t.Fatalf("expected no inline grouping by default, got %q", cfg.InlineGrouping)
+	}
+
+	cfg, err = Parse([]string{"--inline-grouping=Rule-File"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.InlineGrouping != InlineGroupingRuleFile {
+		t.Fatalf("expected rule-file inline grouping, got %q", cfg.InlineGrouping)
+	}
+
+	_, err = Parse([]string{"--inline-grouping=file"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --inline-grouping") {
+		t.Fatalf("expected inline grouping error, got %v", err)
+	}
+}
+
+func TestParseInlineCommentLimits(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]string{"--max-inline-comments=25", "--max-comments-per-file=3"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.MaxInlineComments != 25 || cfg.MaxCommentsPerFile != 3 {
+		t.Fatalf("unexpected limits: total=%d per-file=%d", cfg.MaxInlineComments, cfg.MaxCommentsPerFile)
+	}
+
+	_, err = Parse([]string{"--max-comments-per-file=-1"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --max-comments-per-file") {
+		t.Fatalf("expected per-file limit error, got %v", err)
+	}
+}
+
+func TestParseSummaryFileRows(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.SummaryFileRows != DefaultSummaryFileRows {
+		t.Fatalf("expected default summary file rows %d, got %d", DefaultSummaryFileRows, cfg.SummaryFileRows)
+	}
+
+	cfg, err = Parse([]string{"--summary-file-rows=0"}, mapGetenv(baseEnv()))
+	if err != nil || cfg.SummaryFileRows != 0 {
+		t.Fatalf("expected summary file rows 0, got %d (err %v)", cfg.SummaryFileRows, err)
+	}
+
+	_, err = Parse([]string{"--summary-file-rows=-2"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --summary-file-rows") {
+		t.Fatalf("expected summary file rows error, got %v", err)
+	}
+}
+
+func TestParseFetchTimeout(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.FetchTimeout != DefaultFetchTimeout {
+		t.Fatalf("expected default fetch timeout %s, got %s", DefaultFetchTimeout, cfg.FetchTimeout)
+	}
+
+	cfg, err = Parse([]string{"--fetch-timeout=90s"}, mapGetenv(baseEnv()))
+	if err != nil || cfg.FetchTimeout != 90*time.Second {
+		t.Fatalf("expected fetch timeout 1m30s, got %s (err %v)", cfg.FetchTimeout, err)
+	}
+
+	_, err = Parse([]string{"--fetch-timeout=0s"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --fetch-timeout") {
+		t.Fatalf("expected fetch timeout error, got %v", err)
+	}
+}
+
+func TestParseWriteTimeout(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.WriteTimeout != DefaultWriteTimeout {
+		t.Fatalf("expected default write timeout %s, got %s", DefaultWriteTimeout, cfg.WriteTimeout)
+	}
+
+	cfg, err = Parse([]string{"--write-timeout=10m"}, mapGetenv(baseEnv()))
+	if err != nil || cfg.WriteTimeout != 10*time.Minute {
+		t.Fatalf("expected write timeout 10m, got %s (err %v)", cfg.WriteTimeout, err)
+	}
+
+	_, err = Parse([]string{"--write-timeout=-1s"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --write-timeout") {
+		t.Fatalf("expected write timeout error, got %v", err)
+	}
+}
+
+func TestParseCommentTemplatePaths(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(
+		[]string{
+			"--inline-template= inline.tmpl ",
+			"--summary-template=summary.tmpl",
+			"--overflow-template=overflow.tmpl",
+			"--resolved-template=resolved.tmpl",
+		},
+		mapGetenv(baseEnv()),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.InlineTemplatePath != "inline.tmpl" || cfg.SummaryTemplatePath != "summary.tmpl" ||
+		cfg.OverflowTemplatePath != "overflow.tmpl" || cfg.ResolvedTemplatePath != "resolved.tmpl" {
+		t.Fatalf("unexpected template paths: %+v", cfg)
+	}
+}
+
+func TestParseResolvedReplySettings(t *testing.T) {
+	t.Parallel()
+
+	env := baseEnv()
+	env["CI_PIPELINE_ID"] = " 77 "
+	env["CI_PIPELINE_URL"] = "https://gitlab.example.com/group/app/-/pipelines/77"
+
+	cfg, err := Parse(nil, mapGetenv(env))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.PipelineID != "77" || cfg.PipelineURL != env["CI_PIPELINE_URL"] {
+		t.Fatalf("unexpected pipeline settings: %+v", cfg)
+	}
+
+	cfg, err = Parse([]string{"--pipeline-id=78"}, mapGetenv(env))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.PipelineID != "78" {
+		t.Fatalf("expected flag to override pipeline ID, got %q", cfg.PipelineID)
+	}
+}
+
+func TestParseResolvePolicy(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.ResolvePolicy != ResolvePolicyAlways {
+		t.Fatalf("expected always resolve policy by default, got %q", cfg.ResolvePolicy)
+	}
+
+	cfg, err = Parse([]string{"--resolve-policy= Skip-Discussed "}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.ResolvePolicy != ResolvePolicySkipDiscussed {
+		t.Fatalf("expected skip-discussed resolve policy, got %q", cfg.ResolvePolicy)
+	}
+
+	cfg, err = Parse([]string{"--keep-discussed-open"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.ResolvePolicy != ResolvePolicySkipDiscussed {
+		t.Fatalf("expected --keep-discussed-open to select skip-discussed, got %q", cfg.ResolvePolicy)
+	}
+
+	_, err = Parse([]string{"--keep-discussed-open", "--resolve-policy=reopen"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "--keep-discussed-open conflicts with --resolve-policy=reopen") {
+		t.Fatalf("expected conflicting policy error, got %v", err)
+	}
+
+	_, err = Parse([]string{"--resolve-policy=never"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --resolve-policy") {
+		t.Fatalf("expected resolve policy error, got %v", err)
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Locale != "en" {
+		t.Fatalf("expected en locale by default, got %q", cfg.Locale)
+	}
+
+	env := baseEnv()
+	env["COMMENTER_LOCALE"] = "ru_RU.UTF-8"
+	cfg, err = Parse(nil, mapGetenv(env))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Locale != "ru" {
+		t.Fatalf("expected ru locale from env, got %q", cfg.Locale)
+	}
+
+	cfg, err = Parse([]string{"--locale=en"}, mapGetenv(env))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Locale != "en" {
+		t.Fatalf("expected flag to override env locale, got %q", cfg.Locale)
+	}
+
+	_, err = Parse([]string{"--locale=de"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "invalid value for --locale") {
+		t.Fatalf("expected locale error, got %v", err)
+	}
+}
+
+func TestParseCompareTargetBranch(t *testing.T) {
+	t.Parallel()
+
+	env := baseEnv()
+	env["CI_MERGE_REQUEST_TARGET_BRANCH_NAME"] = " main "
+	cfg, err := Parse([]string{"--compare-target-branch"}, mapGetenv(env))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cfg.CompareTargetBranch || cfg.TargetBranch != "main" || cfg.SonarTargetProjectKey != "" {
+		t.Fatalf("unexpected target branch config: %+v", cfg)
+	}
+
+	cfg, err = Parse([]string{"--compare-target-branch", "--sonar-target-project-key=demo-main"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.SonarTargetProjectKey != "demo-main" {
+		t.Fatalf("unexpected target project key: %q", cfg.SonarTargetProjectKey)
+	}
+
+	_, err = Parse([]string{"--compare-target-branch"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "--compare-target-branch requires the target branch") {
+		t.Fatalf("expected missing target branch error, got %v", err)
+	}
+}
+
+func TestParseTriageSubcommand(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]string{"triage", "--logs"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cfg.Triage || !cfg.Logs || cfg.SonarCommands {
+		t.Fatalf("unexpected triage config: %+v", cfg)
+	}
+
+	cfg, err = Parse([]string{"--sonar-commands"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.Triage || !cfg.SonarCommands {
+		t.Fatalf("unexpected commands config: %+v", cfg)
+	}
+
+	if cfg.SonarCommandRole != DefaultSonarCommandRole {
+		t.Fatalf("expected default command role %q, got %q", DefaultSonarCommandRole, cfg.SonarCommandRole)
+	}
+
+	cfg, err = Parse([]string{"triage", "--sonar-command-role=Maintainer"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.SonarCommandRole != "maintainer" || RoleAccessLevel(cfg.SonarCommandRole) != 40 {
+		t.Fatalf("unexpected command role: %q", cfg.SonarCommandRole)
+	}
+
+	_, err = Parse([]string{"--sonar-command-role=admin"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), `invalid value for --sonar-command-role: "admin"`) {
+		t.Fatalf("expected command role error, got %v", err)
+	}
+
+	_, err = Parse([]string{"--logs", "triage"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "unexpected positional arguments: triage") {
+		t.Fatalf("expected positional argument error, got %v", err)
+	}
+}
+
+func TestParseSyncResolutions(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]string{"--sync-resolutions"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cfg.SyncResolutions || cfg.AcceptKeyword != DefaultAcceptKeyword || cfg.FalsePositiveKeyword != DefaultFalsePositiveKeyword {
+		t.Fatalf("unexpected sync config: %+v", cfg)
+	}
+
+	cfg, err = Parse([]string{"--sync-resolutions", "--accept-keyword= wontfix ", "--false-positive-keyword=fp"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.AcceptKeyword != "wontfix" || cfg.FalsePositiveKeyword != "fp" {
+		t.Fatalf("unexpected keywords: %q %q", cfg.AcceptKeyword, cfg.FalsePositiveKeyword)
+	}
+
+	_, err = Parse([]string{"--sync-resolutions", "--accept-keyword= "}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "non-empty --accept-keyword") {
+		t.Fatalf("expected empty keyword error, got %v", err)
+	}
+
+	_, err = Parse([]string{"--sync-resolutions", "--accept-keyword=FP", "--false-positive-keyword=fp"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "must differ") {
+		t.Fatalf("expected duplicate keyword error, got %v", err)
+	}
+}
+
+func TestParseSonarLinkScope(t *testing.T) {
+	t.Parallel()
+
+	env := baseEnv()
+	env["SONAR_PULL_REQUEST"] = "42"
+	cfg, err := Parse(nil, mapGetenv(env))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.SonarPullRequest != "42" || cfg.SonarBranch != "" {
+		t.Fatalf("unexpected link scope: branch=%q pull request=%q", cfg.SonarBranch, cfg.SonarPullRequest)
+	}
+
+	_, err = Parse([]string{"--sonar-branch=main"}, mapGetenv(env))
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("expected mutually exclusive error, got %v", err)
+	}
+}
+
 func TestParseDryRunFlag(t *testing.T) {
 	t.Parallel()
 
@@ -246,6 +699,161 @@ func TestParseDryRunFlag(t *testing.T) {
 	}
 }
 
+func TestParseMirrorRepliesFlag(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]string{"--mirror-replies"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !cfg.MirrorReplies {
+		t.Fatal("expected reply mirroring to be enabled")
+	}
+}
+
+func TestParseAssignAuthor(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]string{"--assign-author", "--sonar-user-map= users.txt "}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cfg.AssignAuthor || cfg.SonarUserMapPath != "users.txt" {
+		t.Fatalf("unexpected assignment settings: %+v", cfg)
+	}
+
+	cfg, err = Parse([]string{"--sonar-commands", "--sonar-user-map=users.txt"}, mapGetenv(baseEnv()))
+	if err != nil || cfg.SonarUserMapPath != "users.txt" {
+		t.Fatalf("expected the user map with --sonar-commands, got %+v, %v", cfg, err)
+	}
+
+	_, err = Parse([]string{"--sonar-user-map=users.txt"}, mapGetenv(baseEnv()))
+	if err == nil || !strings.Contains(err.Error(), "--sonar-user-map requires --assign-author, --sonar-commands or triage") {
+		t.Fatalf("expected user map error, got %v", err)
+	}
+}
+
+func TestParseCreateIssues(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.CreateIssues != "" || cfg.CreateIssuesSeverity != DefaultCreateIssuesSeverity || cfg.CreateIssuesLabels != DefaultCreateIssuesLabels {
+		t.Fatalf("unexpected issue creation defaults: %+v", cfg)
+	}
+
+	cfg, err = Parse(
+		[]string{"--create-issues=Rule", "--create-issues-severity=critical", "--create-issues-types= vulnerability, bug ", "--create-issues-due-days=14"},
+		mapGetenv(baseEnv()),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.CreateIssues != CreateIssuesPerRule || cfg.CreateIssuesSeverity != "CRITICAL" ||
+		!reflect.DeepEqual(cfg.CreateIssuesTypes, []string{"VULNERABILITY", "BUG"}) || cfg.CreateIssuesDueDays != 14 {
+		t.Fatalf("unexpected issue creation settings: %+v", cfg)
+	}
+
+	for _, args := range [][]string{
+		{"--create-issues=file"},
+		{"--create-issues-severity=HIGH"},
+		{"--create-issues-due-days=-1"},
+	} {
+		if _, err := Parse(args, mapGetenv(baseEnv())); err == nil || !strings.Contains(err.Error(), "invalid value for --create-issues") {
+			t.Fatalf("%v: expected issue creation error, got %v", args, err)
+		}
+	}
+}
+
+func TestParseCommitStatus(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(nil, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.CommitStatus || cfg.CommitStatusName != DefaultCommitStatusName || !reflect.DeepEqual(cfg.CommitStatusStates, DefaultCommitStatusStates()) {
+		t.Fatalf("unexpected commit status defaults: %+v", cfg)
+	}
+
+	cfg, err = Parse(
+		[]string{"--commit-status", "--commit-status-name=sonar/gate", "--commit-status-states=Warning=Failed, failed=canceled"},
+		mapGetenv(baseEnv()),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := map[string]string{"passed": "success", "failed": "canceled", "warning": "failed"}
+	if !cfg.CommitStatus || cfg.CommitStatusName != "sonar/gate" || !reflect.DeepEqual(cfg.CommitStatusStates, expected) {
+		t.Fatalf("unexpected commit status settings: %+v", cfg)
+	}
+
+	for _, args := range [][]string{
+		{"--commit-status-states=unknown=success"},
+		{"--commit-status-states=passed=green"},
+		{"--commit-status-states=passed"},
+		{"--commit-status-name= "},
+	} {
+		if _, err := Parse(args, mapGetenv(baseEnv())); err == nil || !strings.Contains(err.Error(), "--commit-status") {
+			t.Fatalf("%v: expected commit status error, got %v", args, err)
+		}
+	}
+}
+
+func TestParseStatusCheck(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]string{"--status-check= SonarQube gate "}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.StatusCheckName != "SonarQube gate" {
+		t.Fatalf("expected status check name %q, got %q", "SonarQube gate", cfg.StatusCheckName)
+	}
+}
+
+func TestParseLabelRules(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse(
+		[]string{"--label-rules=sonar-gate::failed=Gate-Failed, has-blockers=blocker>0,coverage-low=new-coverage<=79.5,,"},
+		mapGetenv(baseEnv()),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := []LabelRule{
+		{Label: "sonar-gate::failed", GateStatus: "failed"},
+		{Label: "has-blockers", Metric: "blocker", Operator: ">", Threshold: 0},
+		{Label: "coverage-low", Metric: LabelMetricNewCoverage, Operator: "<=", Threshold: 79.5},
+	}
+	if !reflect.DeepEqual(cfg.LabelRules, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, cfg.LabelRules)
+	}
+
+	for _, value := range []string{"gate-failed", "=blocker>0", "label=gate-red", "label=bugs>0", "label=issues>many", "label=issues!=0"} {
+		if _, err := Parse([]string{"--label-rules=" + value}, mapGetenv(baseEnv())); err == nil || !strings.Contains(err.Error(), "invalid value for --label-rules") {
+			t.Fatalf("%q: expected label rules error, got %v", value, err)
+		}
+	}
+}
+
+func TestParseDraftNotesFlag(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Parse([]string{"--draft-notes"}, mapGetenv(baseEnv()))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !cfg.DraftNotes {
+		t.Fatal("expected draft notes mode to be enabled")
+	}
+}
+
 func TestParseLogsFlag(t *testing.T) {
 	t.Parallel()
 
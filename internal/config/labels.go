@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Metrics that label rules compare with a threshold. Severity names count the
+// issues of that severity.
+const (
+	LabelMetricCoverage    = "coverage"
+	LabelMetricNewCoverage = "new-coverage"
+	LabelMetricIssues      = "issues"
+)
+
+// labelGatePrefix starts conditions on the quality gate status, like gate-failed.
+const labelGatePrefix = "gate-"
+
+var labelGateStatuses = []string{"passed", "failed", "warning"}
+
+var labelSeverityMetrics = []string{"blocker", "critical", "major", "minor", "info"}
+
+// labelOperators are checked in order, so that <= is not read as <.
+var labelOperators = []string{"<=", ">=", "<", ">"}
+
+// LabelRule sets Label on the merge request while its condition holds and
+// removes it otherwise. GateStatus is set for gate-<status> conditions; any other
+// rule compares Metric with Threshold using Operator.
+type LabelRule struct {
+	Label      string
+	GateStatus string
+	Metric     string
+	Operator   string
+	Threshold  float64
+}
+
+// parseLabelRules parses a comma-separated list of label=condition rules.
+func parseLabelRules(value string) ([]LabelRule, error) {
+	var rules []LabelRule
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		label, condition, found := strings.Cut(part, "=")
+		label = strings.TrimSpace(label)
+		condition = strings.ToLower(strings.TrimSpace(condition))
+		if !found || label == "" || condition == "" {
+			return nil, fmt.Errorf("invalid rule %q (expected label=condition)", part)
+		}
+
+		rule, err := parseLabelCondition(condition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", part, err)
+		}
+		rule.Label = label
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func parseLabelCondition(condition string) (LabelRule, error) {
+	if status, found := strings.CutPrefix(condition, labelGatePrefix); found {
+		for _, known := range labelGateStatuses {
+			if status == known {
+				return LabelRule{GateStatus: status}, nil
+			}
+		}
+		return LabelRule{}, fmt.Errorf("unknown quality gate status %q (allowed: %s)", status, strings.Join(labelGateStatuses, ", "))
+	}
+
+	for _, operator := range labelOperators {
+		metric, threshold, found := strings.Cut(condition, operator)
+		if !found {
+			continue
+		}
+
+		metric = strings.TrimSpace(metric)
+		if !isLabelMetric(metric) {
+			return LabelRule{}, fmt.Errorf(
+				"unknown metric %q (allowed: %s, %s, %s, %s)",
+				metric,
+				LabelMetricCoverage,
+				LabelMetricNewCoverage,
+				LabelMetricIssues,
+				strings.Join(labelSeverityMetrics, ", "),
+			)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(threshold), 64)
+		if err != nil {
+			return LabelRule{}, fmt.Errorf("invalid threshold %q", strings.TrimSpace(threshold))
+		}
+
+		return LabelRule{Metric: metric, Operator: operator, Threshold: value}, nil
+	}
+
+	return LabelRule{}, fmt.Errorf("expected gate-<status> or <metric><operator><number>")
+}
+
+func isLabelMetric(metric string) bool {
+	switch metric {
+	case LabelMetricCoverage, LabelMetricNewCoverage, LabelMetricIssues:
+		return true
+	}
+	for _, severity := range labelSeverityMetrics {
+		if metric == severity {
+			return true
+		}
+	}
+
+	return false
+}
@@ -18,6 +18,7 @@ const perPageLimit = 100
 
 var ErrUnauthorized = errors.New("unauthorized GitLab API request")
 var ErrInvalidInlinePosition = errors.New("invalid inline discussion position")
+var ErrNotFound = errors.New("GitLab resource not found")
 
 type Client struct {
 	baseURL    string
@@ -33,6 +34,9 @@ type DiffRefs struct {
 
 type MergeRequest struct {
 	IID      int
+	WebURL   string
+	Author   User
+	Labels   []string
 	DiffRefs DiffRefs
 }
 
@@ -50,7 +54,24 @@ type Discussion struct {
 }
 
 type DiscussionNote struct {
-	Body string
+	ID     int
+	Body   string
+	Author User
+	// ResolvedBy is the zero User when the note is unresolved or GitLab does not
+	// report who resolved it.
+	ResolvedBy User
+	// System is set for notes GitLab adds itself, such as "changed this line".
+	System bool
+	// NewPath is the file a diff note is attached to; it is empty for other notes.
+	NewPath string
+}
+
+type User struct {
+	ID       int
+	Username string
+	// Email is only set by GetUser: the private email for administrators,
+	// otherwise the public one.
+	Email string
 }
 
 type MergeRequestNote struct {
@@ -58,8 +79,55 @@ type MergeRequestNote struct {
 	Body string
 }
 
+// NewIssue describes a project issue to create. Labels is a comma-separated
+// list and DueDate uses the YYYY-MM-DD format; both are optional.
+type NewIssue struct {
+	Title       string
+	Description string
+	Labels      string
+	DueDate     string
+}
+
+// CommitStatus is an external status of a commit. State is one of pending,
+// running, success, failed or canceled; TargetURL is optional.
+type CommitStatus struct {
+	Name        string
+	State       string
+	Description string
+	TargetURL   string
+}
+
+// StatusCheck is an external status check of a merge request. Status is passed,
+// failed or pending for the MR head commit.
+type StatusCheck struct {
+	ID     int
+	Name   string
+	Status string
+}
+
+type Issue struct {
+	IID    int
+	WebURL string
+	// Description is only set by SearchIssueDescriptions.
+	Description string
+}
+
+// DraftNote is a pending review comment of the token user.
+type DraftNote struct {
+	ID   int
+	Note string
+}
+
+type draftNoteResponse struct {
+	ID   int    `json:"id"`
+	Note string `json:"note"`
+}
+
 type mergeRequestResponse struct {
 	IID      int                  `json:"iid"`
+	WebURL   string               `json:"web_url"`
+	Author   *userResponse        `json:"author"`
+	Labels   []string             `json:"labels"`
 	DiffRefs mergeRequestDiffRefs `json:"diff_refs"`
 }
 
@@ -87,7 +155,41 @@ type discussionResponse struct {
 }
 
 type discussionNoteResponse struct {
-	Body string `json:"body"`
+	ID         int           `json:"id"`
+	Body       string        `json:"body"`
+	Author     *userResponse `json:"author"`
+	ResolvedBy *userResponse `json:"resolved_by"`
+	System     bool          `json:"system"`
+	Position   *struct {
+		NewPath string `json:"new_path"`
+	} `json:"position"`
+}
+
+type userResponse struct {
+	ID          int    `json:"id"`
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	PublicEmail string `json:"public_email"`
+}
+
+type issueResponse struct {
+	IID         int    `json:"iid"`
+	WebURL      string `json:"web_url"`
+	Description string `json:"description"`
+}
+
+type commitStatusResponse struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+type statusCheckResponse struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
 }
 
 type mergeRequestNoteResponse struct {
@@ -120,33 +222,9 @@ func (c *Client) GetMergeRequest(ctx context.Context, projectID, mrIID int) (Mer
 	}
 
 	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d", projectID, mrIID)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, nil)
-	if err != nil {
-		return MergeRequest{}, fmt.Errorf("failed to create GitLab request: %w", err)
-	}
-
-	req.Header.Set("PRIVATE-TOKEN", c.token)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return MergeRequest{}, fmt.Errorf("failed to connect to GitLab at %s: %w", c.baseURL, err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return MergeRequest{}, fmt.Errorf("%w: HTTP %d from %s", ErrUnauthorized, resp.StatusCode, endpoint)
-	}
-
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyForError))
-		return MergeRequest{}, fmt.Errorf("GitLab API request failed for %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-
 	var payload mergeRequestResponse
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return MergeRequest{}, fmt.Errorf("failed to decode GitLab response from %s: %w", endpoint, err)
+	if _, err := c.getJSON(ctx, endpoint, nil, &payload); err != nil {
+		return MergeRequest{}, err
 	}
 
 	if payload.IID != mrIID {
@@ -154,7 +232,10 @@ func (c *Client) GetMergeRequest(ctx context.Context, projectID, mrIID int) (Mer
 	}
 
 	return MergeRequest{
-		IID: payload.IID,
+		IID:    payload.IID,
+		WebURL: payload.WebURL,
+		Author: payload.Author.user(),
+		Labels: payload.Labels,
 		DiffRefs: DiffRefs{
 			BaseSHA:  payload.DiffRefs.BaseSHA,
 			StartSHA: payload.DiffRefs.StartSHA,
@@ -180,89 +261,145 @@ func (c *Client) CreateInlineDiscussion(
 	if strings.TrimSpace(body) == "" {
 		return fmt.Errorf("discussion body cannot be empty")
 	}
-	oldPath = strings.TrimSpace(oldPath)
-	newPath = strings.TrimSpace(newPath)
-	if oldPath == "" && newPath == "" {
-		return fmt.Errorf("discussion paths cannot both be empty")
-	}
-	if oldLine <= 0 && newLine <= 0 {
-		return fmt.Errorf("discussion must have at least one line number")
-	}
 
-	normalizedDiffRefs := normalizeDiffRefs(diffRefs)
-	if err := validateDiffRefs(normalizedDiffRefs); err != nil {
+	form, err := inlinePositionForm(oldPath, newPath, oldLine, newLine, diffRefs)
+	if err != nil {
 		return err
 	}
+	form.Set("body", body)
 
 	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/discussions", projectID, mrIID)
-	form := url.Values{}
-	form.Set("body", body)
-	form.Set("position[position_type]", "text")
-	form.Set("position[base_sha]", normalizedDiffRefs.BaseSHA)
-	form.Set("position[start_sha]", normalizedDiffRefs.StartSHA)
-	form.Set("position[head_sha]", normalizedDiffRefs.HeadSHA)
-	form.Set("position[old_path]", oldPath)
-	form.Set("position[new_path]", newPath)
+	if err := c.postForm(ctx, endpoint, form); err != nil {
+		if isInvalidInlinePositionError(err) {
+			return fmt.Errorf("%w: %v", ErrInvalidInlinePosition, err)
+		}
 
-	// Set line numbers based on what's available
-	// For added lines: only new_line
-	// For deleted lines: only old_line
-	// For context lines: both old_line and new_line
-	if oldLine > 0 {
-		form.Set("position[old_line]", strconv.Itoa(oldLine))
+		return err
 	}
-	if newLine > 0 {
-		form.Set("position[new_line]", strconv.Itoa(newLine))
+
+	return nil
+}
+
+// CreateDraftNote creates an unpublished review comment with the same position
+// payload as CreateInlineDiscussion and returns the draft note ID.
+func (c *Client) CreateDraftNote(
+	ctx context.Context,
+	projectID,
+	mrIID int,
+	body,
+	oldPath,
+	newPath string,
+	oldLine,
+	newLine int,
+	diffRefs DiffRefs,
+) (int, error) {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
+		return 0, err
+	}
+	if strings.TrimSpace(body) == "" {
+		return 0, fmt.Errorf("draft note body cannot be empty")
 	}
 
-	if err := c.postForm(ctx, endpoint, form); err != nil {
+	form, err := inlinePositionForm(oldPath, newPath, oldLine, newLine, diffRefs)
+	if err != nil {
+		return 0, err
+	}
+	form.Set("note", body)
+
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/draft_notes", projectID, mrIID)
+	var payload draftNoteResponse
+	if err := c.sendFormJSON(ctx, http.MethodPost, endpoint, form, &payload); err != nil {
 		if isInvalidInlinePositionError(err) {
-			return fmt.Errorf("%w: %v", ErrInvalidInlinePosition, err)
+			return 0, fmt.Errorf("%w: %v", ErrInvalidInlinePosition, err)
 		}
 
+		return 0, err
+	}
+	if payload.ID <= 0 {
+		return 0, fmt.Errorf("GitLab API returned draft note without ID for %s", endpoint)
+	}
+
+	return payload.ID, nil
+}
+
+// BulkPublishDraftNotes publishes every pending draft note of the current user
+// as a single review.
+func (c *Client) BulkPublishDraftNotes(ctx context.Context, projectID, mrIID int) error {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
 		return err
 	}
 
-	return nil
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/draft_notes/bulk_publish", projectID, mrIID)
+	return c.postForm(ctx, endpoint, url.Values{})
 }
 
-func (c *Client) ListMergeRequestChanges(ctx context.Context, projectID, mrIID int) ([]MergeRequestChange, error) {
+// ListDraftNotes returns the pending draft notes of the token user.
+func (c *Client) ListDraftNotes(ctx context.Context, projectID, mrIID int) ([]DraftNote, error) {
 	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
 		return nil, err
 	}
 
-	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/changes", projectID, mrIID)
-	values := url.Values{}
-	values.Set("access_raw_diffs", "true")
-	requestURL := c.baseURL + endpoint + "?" + values.Encode()
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/draft_notes", projectID, mrIID)
+	page := "1"
+	drafts := make([]DraftNote, 0)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GitLab request: %w", err)
+	for {
+		var payload []draftNoteResponse
+		nextPage, err := c.getJSON(ctx, endpoint, paginationValues(page), &payload)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range payload {
+			drafts = append(drafts, DraftNote(item))
+		}
+
+		if nextPage == "" {
+			break
+		}
+		page = nextPage
 	}
 
-	req.Header.Set("PRIVATE-TOKEN", c.token)
+	return drafts, nil
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to GitLab at %s: %w", c.baseURL, err)
+// PublishDraftNote publishes a single draft note.
+func (c *Client) PublishDraftNote(ctx context.Context, projectID, mrIID, draftNoteID int) error {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
+		return err
+	}
+	if draftNoteID <= 0 {
+		return fmt.Errorf("draft note ID must be positive")
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
 
-	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return nil, fmt.Errorf("%w: HTTP %d from %s", ErrUnauthorized, resp.StatusCode, endpoint)
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/draft_notes/%d/publish", projectID, mrIID, draftNoteID)
+	return c.putForm(ctx, endpoint, url.Values{})
+}
+
+func (c *Client) DeleteDraftNote(ctx context.Context, projectID, mrIID, draftNoteID int) error {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
+		return err
+	}
+	if draftNoteID <= 0 {
+		return fmt.Errorf("draft note ID must be positive")
 	}
 
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyForError))
-		return nil, fmt.Errorf("GitLab API request failed for %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/draft_notes/%d", projectID, mrIID, draftNoteID)
+	return c.sendForm(ctx, http.MethodDelete, endpoint, url.Values{})
+}
+
+func (c *Client) ListMergeRequestChanges(ctx context.Context, projectID, mrIID int) ([]MergeRequestChange, error) {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
+		return nil, err
 	}
 
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/changes", projectID, mrIID)
+	values := url.Values{}
+	values.Set("access_raw_diffs", "true")
+
 	var payload mergeRequestChangesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return nil, fmt.Errorf("failed to decode GitLab response from %s: %w", endpoint, err)
+	if _, err := c.getJSON(ctx, endpoint, values, &payload); err != nil {
+		return nil, err
 	}
 
 	changes := make([]MergeRequestChange, 0, len(payload.Changes))
@@ -302,45 +439,26 @@ func (c *Client) ListMergeRequestDiscussions(ctx context.Context, projectID, mrI
 	discussions := make([]Discussion, 0)
 
 	for {
-		req, err := http.NewRequestWithContext(
-			ctx,
-			http.MethodGet,
-			c.baseURL+withPagination(endpoint, page),
-			nil,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create GitLab request: %w", err)
-		}
-		req.Header.Set("PRIVATE-TOKEN", c.token)
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to connect to GitLab at %s: %w", c.baseURL, err)
-		}
-
-		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-			_ = resp.Body.Close()
-			return nil, fmt.Errorf("%w: HTTP %d from %s", ErrUnauthorized, resp.StatusCode, endpoint)
-		}
-		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyForError))
-			_ = resp.Body.Close()
-			return nil, fmt.Errorf("GitLab API request failed for %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
-		}
-
 		var payload []discussionResponse
-		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-			_ = resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode GitLab response from %s: %w", endpoint, err)
+		nextPage, err := c.getJSON(ctx, endpoint, paginationValues(page), &payload)
+		if err != nil {
+			return nil, err
 		}
 
-		nextPage := strings.TrimSpace(resp.Header.Get("X-Next-Page"))
-		_ = resp.Body.Close()
-
 		for _, item := range payload {
 			notes := make([]DiscussionNote, 0, len(item.Notes))
 			for _, note := range item.Notes {
-				notes = append(notes, DiscussionNote(note))
+				decoded := DiscussionNote{
+					ID:         note.ID,
+					Body:       note.Body,
+					Author:     note.Author.user(),
+					ResolvedBy: note.ResolvedBy.user(),
+					System:     note.System,
+				}
+				if note.Position != nil {
+					decoded.NewPath = note.Position.NewPath
+				}
+				notes = append(notes, decoded)
 			}
 			discussions = append(discussions, Discussion{
 				ID:         item.ID,
@@ -359,7 +477,36 @@ func (c *Client) ListMergeRequestDiscussions(ctx context.Context, projectID, mrI
 	return discussions, nil
 }
 
+// CreateDiscussionNote replies to an existing merge request discussion.
+func (c *Client) CreateDiscussionNote(ctx context.Context, projectID, mrIID int, discussionID, body string) error {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
+		return err
+	}
+	discussionID = strings.TrimSpace(discussionID)
+	if discussionID == "" {
+		return fmt.Errorf("discussion ID cannot be empty")
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("note body cannot be empty")
+	}
+
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/discussions/%s/notes", projectID, mrIID, discussionID)
+	form := url.Values{}
+	form.Set("body", body)
+
+	return c.postForm(ctx, endpoint, form)
+}
+
 func (c *Client) ResolveMergeRequestDiscussion(ctx context.Context, projectID, mrIID int, discussionID string) error {
+	return c.setMergeRequestDiscussionResolved(ctx, projectID, mrIID, discussionID, true)
+}
+
+// ReopenMergeRequestDiscussion marks a resolved discussion as unresolved.
+func (c *Client) ReopenMergeRequestDiscussion(ctx context.Context, projectID, mrIID int, discussionID string) error {
+	return c.setMergeRequestDiscussionResolved(ctx, projectID, mrIID, discussionID, false)
+}
+
+func (c *Client) setMergeRequestDiscussionResolved(ctx context.Context, projectID, mrIID int, discussionID string, resolved bool) error {
 	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
 		return err
 	}
@@ -370,7 +517,7 @@ func (c *Client) ResolveMergeRequestDiscussion(ctx context.Context, projectID, m
 
 	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/discussions/%s", projectID, mrIID, discussionID)
 	form := url.Values{}
-	form.Set("resolved", "true")
+	form.Set("resolved", strconv.FormatBool(resolved))
 
 	return c.putForm(ctx, endpoint, form)
 }
@@ -385,41 +532,12 @@ func (c *Client) ListMergeRequestNotes(ctx context.Context, projectID, mrIID int
 	notes := make([]MergeRequestNote, 0)
 
 	for {
-		req, err := http.NewRequestWithContext(
-			ctx,
-			http.MethodGet,
-			c.baseURL+withPagination(endpoint, page),
-			nil,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create GitLab request: %w", err)
-		}
-		req.Header.Set("PRIVATE-TOKEN", c.token)
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("failed to connect to GitLab at %s: %w", c.baseURL, err)
-		}
-
-		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-			_ = resp.Body.Close()
-			return nil, fmt.Errorf("%w: HTTP %d from %s", ErrUnauthorized, resp.StatusCode, endpoint)
-		}
-		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-			body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyForError))
-			_ = resp.Body.Close()
-			return nil, fmt.Errorf("GitLab API request failed for %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
-		}
-
 		var payload []mergeRequestNoteResponse
-		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-			_ = resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode GitLab response from %s: %w", endpoint, err)
+		nextPage, err := c.getJSON(ctx, endpoint, paginationValues(page), &payload)
+		if err != nil {
+			return nil, err
 		}
 
-		nextPage := strings.TrimSpace(resp.Header.Get("X-Next-Page"))
-		_ = resp.Body.Close()
-
 		for _, item := range payload {
 			notes = append(notes, MergeRequestNote(item))
 		}
@@ -451,6 +569,315 @@ func (c *Client) UpdateMergeRequestNote(ctx context.Context, projectID, mrIID, n
 	return c.putForm(ctx, endpoint, form)
 }
 
+func (c *Client) DeleteMergeRequestNote(ctx context.Context, projectID, mrIID, noteID int) error {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
+		return err
+	}
+	if noteID <= 0 {
+		return fmt.Errorf("note ID must be positive")
+	}
+
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/notes/%d", projectID, mrIID, noteID)
+	return c.sendForm(ctx, http.MethodDelete, endpoint, url.Values{})
+}
+
+// UpdateMergeRequestLabels adds and removes merge request labels without
+// touching the others.
+func (c *Client) UpdateMergeRequestLabels(ctx context.Context, projectID, mrIID int, add, remove []string) error {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	if len(add) > 0 {
+		form.Set("add_labels", strings.Join(add, ","))
+	}
+	if len(remove) > 0 {
+		form.Set("remove_labels", strings.Join(remove, ","))
+	}
+
+	return c.putForm(ctx, fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d", projectID, mrIID), form)
+}
+
+// SetCommitStatus creates or updates the named status of a commit.
+func (c *Client) SetCommitStatus(ctx context.Context, projectID int, sha string, status CommitStatus) error {
+	if projectID <= 0 {
+		return fmt.Errorf("project ID must be positive")
+	}
+	if strings.TrimSpace(sha) == "" {
+		return fmt.Errorf("commit SHA cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Set("state", status.State)
+	form.Set("name", status.Name)
+	form.Set("description", status.Description)
+	if status.TargetURL != "" {
+		form.Set("target_url", status.TargetURL)
+	}
+
+	return c.postForm(ctx, fmt.Sprintf("/api/v4/projects/%d/statuses/%s", projectID, url.PathEscape(sha)), form)
+}
+
+// GetCommitStatus returns the latest status of a commit with the given name and
+// whether the commit has one. Statuses of older pipelines may share the name, so
+// the one with the highest ID wins; a missing commit counts as having none.
+func (c *Client) GetCommitStatus(ctx context.Context, projectID int, sha, name string) (CommitStatus, bool, error) {
+	if projectID <= 0 {
+		return CommitStatus{}, false, fmt.Errorf("project ID must be positive")
+	}
+	if strings.TrimSpace(sha) == "" {
+		return CommitStatus{}, false, fmt.Errorf("commit SHA cannot be empty")
+	}
+
+	var payload []commitStatusResponse
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/repository/commits/%s/statuses", projectID, url.PathEscape(sha))
+	query := url.Values{}
+	query.Set("name", name)
+	query.Set("order_by", "id")
+	query.Set("sort", "desc")
+	if _, err := c.getJSON(ctx, endpoint, query, &payload); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return CommitStatus{}, false, nil
+		}
+		return CommitStatus{}, false, err
+	}
+
+	var latest *commitStatusResponse
+	for i := range payload {
+		item := &payload[i]
+		if item.Name == name && (latest == nil || item.ID > latest.ID) {
+			latest = item
+		}
+	}
+	if latest == nil {
+		return CommitStatus{}, false, nil
+	}
+
+	return CommitStatus{Name: latest.Name, State: latest.Status, Description: latest.Description, TargetURL: latest.TargetURL}, true, nil
+}
+
+// ListMergeRequestStatusChecks returns the external status checks that apply to a
+// merge request.
+func (c *Client) ListMergeRequestStatusChecks(ctx context.Context, projectID, mrIID int) ([]StatusCheck, error) {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/status_checks", projectID, mrIID)
+	page := "1"
+	checks := make([]StatusCheck, 0)
+
+	for {
+		var payload []statusCheckResponse
+		nextPage, err := c.getJSON(ctx, endpoint, paginationValues(page), &payload)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range payload {
+			checks = append(checks, StatusCheck(item))
+		}
+
+		if nextPage == "" {
+			break
+		}
+		page = nextPage
+	}
+
+	return checks, nil
+}
+
+// SetStatusCheckResponse reports the status of an external status check for the
+// given merge request head commit.
+func (c *Client) SetStatusCheckResponse(ctx context.Context, projectID, mrIID, checkID int, sha, status string) error {
+	if err := validateMergeRequestCoordinates(projectID, mrIID); err != nil {
+		return err
+	}
+	if checkID <= 0 {
+		return fmt.Errorf("status check ID must be positive")
+	}
+	if strings.TrimSpace(sha) == "" {
+		return fmt.Errorf("commit SHA cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Set("sha", sha)
+	form.Set("external_status_check_id", strconv.Itoa(checkID))
+	form.Set("status", status)
+
+	return c.postForm(ctx, fmt.Sprintf("/api/v4/projects/%d/merge_requests/%d/status_check_responses", projectID, mrIID), form)
+}
+
+// CreateIssue opens a project issue and returns its IID and web URL.
+func (c *Client) CreateIssue(ctx context.Context, projectID int, issue NewIssue) (Issue, error) {
+	if projectID <= 0 {
+		return Issue{}, fmt.Errorf("project ID must be positive")
+	}
+	if strings.TrimSpace(issue.Title) == "" {
+		return Issue{}, fmt.Errorf("issue title cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Set("title", issue.Title)
+	form.Set("description", issue.Description)
+	if issue.Labels != "" {
+		form.Set("labels", issue.Labels)
+	}
+	if issue.DueDate != "" {
+		form.Set("due_date", issue.DueDate)
+	}
+
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/issues", projectID)
+	var payload issueResponse
+	if err := c.sendFormJSON(ctx, http.MethodPost, endpoint, form, &payload); err != nil {
+		return Issue{}, err
+	}
+	if payload.IID <= 0 {
+		return Issue{}, fmt.Errorf("GitLab API returned issue without IID for %s", endpoint)
+	}
+
+	return Issue{IID: payload.IID, WebURL: payload.WebURL}, nil
+}
+
+// SearchIssueDescriptions returns the project issues of any state whose
+// description contains search.
+func (c *Client) SearchIssueDescriptions(ctx context.Context, projectID int, search string) ([]Issue, error) {
+	if projectID <= 0 {
+		return nil, fmt.Errorf("project ID must be positive")
+	}
+
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/issues", projectID)
+	page := "1"
+	issues := make([]Issue, 0)
+
+	for {
+		query := paginationValues(page)
+		query.Set("search", search)
+		query.Set("in", "description")
+
+		var payload []issueResponse
+		nextPage, err := c.getJSON(ctx, endpoint, query, &payload)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range payload {
+			issues = append(issues, Issue{IID: item.IID, WebURL: item.WebURL, Description: item.Description})
+		}
+
+		if nextPage == "" {
+			break
+		}
+		page = nextPage
+	}
+
+	return issues, nil
+}
+
+// GetCurrentUser returns the user that owns the access token.
+func (c *Client) GetCurrentUser(ctx context.Context) (User, error) {
+	var payload userResponse
+	if _, err := c.getJSON(ctx, "/api/v4/user", nil, &payload); err != nil {
+		return User{}, err
+	}
+	if payload.ID <= 0 {
+		return User{}, fmt.Errorf("GitLab API returned no user ID for /api/v4/user")
+	}
+
+	return payload.user(), nil
+}
+
+// GetProjectAccessLevel returns the access level of a user in the project,
+// including inherited membership, or 0 for users who are not members.
+func (c *Client) GetProjectAccessLevel(ctx context.Context, projectID, userID int) (int, error) {
+	var payload struct {
+		AccessLevel int `json:"access_level"`
+	}
+	endpoint := fmt.Sprintf("/api/v4/projects/%d/members/all/%d", projectID, userID)
+	if _, err := c.getJSON(ctx, endpoint, nil, &payload); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return payload.AccessLevel, nil
+}
+
+// GetUser returns a user with the email visible to the token owner.
+func (c *Client) GetUser(ctx context.Context, userID int) (User, error) {
+	if userID <= 0 {
+		return User{}, fmt.Errorf("user ID must be a positive integer")
+	}
+
+	var payload userResponse
+	endpoint := fmt.Sprintf("/api/v4/users/%d", userID)
+	if _, err := c.getJSON(ctx, endpoint, nil, &payload); err != nil {
+		return User{}, err
+	}
+
+	user := payload.user()
+	user.Email = strings.TrimSpace(payload.Email)
+	if user.Email == "" {
+		user.Email = strings.TrimSpace(payload.PublicEmail)
+	}
+
+	return user, nil
+}
+
+func (u *userResponse) user() User {
+	if u == nil {
+		return User{}
+	}
+
+	return User{ID: u.ID, Username: u.Username}
+}
+
+// getJSON sends a GET request and decodes the JSON response into target. It
+// returns the X-Next-Page header, which is empty on the last page.
+func (c *Client) getJSON(ctx context.Context, endpoint string, query url.Values, target any) (string, error) {
+	requestURL := c.baseURL + endpoint
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitLab request: %w", err)
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to GitLab at %s: %w", c.baseURL, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return "", fmt.Errorf("%w: HTTP %d from %s", ErrUnauthorized, resp.StatusCode, endpoint)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyForError))
+		return "", fmt.Errorf("%w: GitLab API request failed for %s: HTTP %d: %s", ErrNotFound, endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyForError))
+		return "", fmt.Errorf("GitLab API request failed for %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return "", fmt.Errorf("failed to decode GitLab response from %s: %w", endpoint, err)
+	}
+
+	return strings.TrimSpace(resp.Header.Get("X-Next-Page")), nil
+}
+
 func (c *Client) postForm(ctx context.Context, endpoint string, form url.Values) error {
 	return c.sendForm(ctx, http.MethodPost, endpoint, form)
 }
@@ -460,6 +887,12 @@ func (c *Client) putForm(ctx context.Context, endpoint string, form url.Values)
 }
 
 func (c *Client) sendForm(ctx context.Context, method, endpoint string, form url.Values) error {
+	return c.sendFormJSON(ctx, method, endpoint, form, nil)
+}
+
+// sendFormJSON sends a form request and decodes the JSON response into target
+// unless target is nil.
+func (c *Client) sendFormJSON(ctx context.Context, method, endpoint string, form url.Values, target any) error {
 	req, err := http.NewRequestWithContext(
 		ctx,
 		method,
@@ -485,15 +918,71 @@ func (c *Client) sendForm(ctx context.Context, method, endpoint string, form url
 		return fmt.Errorf("%w: HTTP %d from %s", ErrUnauthorized, resp.StatusCode, endpoint)
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: HTTP %d from %s", ErrNotFound, resp.StatusCode, endpoint)
+	}
+
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyForError))
 		return fmt.Errorf("GitLab API request failed for %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
+	if target == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode GitLab response from %s: %w", endpoint, err)
+	}
+
 	return nil
 }
 
-func withPagination(endpoint, page string) string {
+// inlinePositionForm validates a diff position and encodes it as the position[...]
+// form fields shared by discussions and draft notes.
+func inlinePositionForm(
+	oldPath,
+	newPath string,
+	oldLine,
+	newLine int,
+	diffRefs DiffRefs,
+) (url.Values, error) {
+	oldPath = strings.TrimSpace(oldPath)
+	newPath = strings.TrimSpace(newPath)
+	if oldPath == "" && newPath == "" {
+		return nil, fmt.Errorf("discussion paths cannot both be empty")
+	}
+	if oldLine <= 0 && newLine <= 0 {
+		return nil, fmt.Errorf("discussion must have at least one line number")
+	}
+
+	normalizedDiffRefs := normalizeDiffRefs(diffRefs)
+	if err := validateDiffRefs(normalizedDiffRefs); err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("position[position_type]", "text")
+	form.Set("position[base_sha]", normalizedDiffRefs.BaseSHA)
+	form.Set("position[start_sha]", normalizedDiffRefs.StartSHA)
+	form.Set("position[head_sha]", normalizedDiffRefs.HeadSHA)
+	form.Set("position[old_path]", oldPath)
+	form.Set("position[new_path]", newPath)
+
+	// Set line numbers based on what's available
+	// For added lines: only new_line
+	// For deleted lines: only old_line
+	// For context lines: both old_line and new_line
+	if oldLine > 0 {
+		form.Set("position[old_line]", strconv.Itoa(oldLine))
+	}
+	if newLine > 0 {
+		form.Set("position[new_line]", strconv.Itoa(newLine))
+	}
+
+	return form, nil
+}
+
+func paginationValues(page string) url.Values {
 	page = strings.TrimSpace(page)
 	if page == "" {
 		page = "1"
@@ -503,7 +992,7 @@ func withPagination(endpoint, page string) string {
 	values.Set("per_page", strconv.Itoa(perPageLimit))
 	values.Set("page", page)
 
-	return endpoint + "?" + values.Encode()
+	return values
 }
 
 func validateMergeRequestCoordinates(projectID, mrIID int) error {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -95,7 +96,7 @@ func TestGetMergeRequestReturnsDiffRefs(t *testing.T) {
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		_, _ = w.Write([]byte(`{"iid":42,"web_url":"https://gitlab.example.com/group/app/-/merge_requests/42","author":{"id":7,"username":"alice"},"labels":["backend","sonar::gate-failed"],"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
 	}))
 	defer server.Close()
 
@@ -107,9 +108,54 @@ func TestGetMergeRequestReturnsDiffRefs(t *testing.T) {
 	if mr.IID != 42 {
 		t.Fatalf("unexpected IID: %d", mr.IID)
 	}
+	if mr.WebURL != "https://gitlab.example.com/group/app/-/merge_requests/42" {
+		t.Fatalf("unexpected web URL: %q", mr.WebURL)
+	}
 	if mr.DiffRefs.BaseSHA != "base" || mr.DiffRefs.StartSHA != "start" || mr.DiffRefs.HeadSHA != "head" {
 		t.Fatalf("unexpected diff refs: %+v", mr.DiffRefs)
 	}
+	if mr.Author != (User{ID: 7, Username: "alice"}) {
+		t.Fatalf("unexpected author: %+v", mr.Author)
+	}
+	if !reflect.DeepEqual(mr.Labels, []string{"backend", "sonar::gate-failed"}) {
+		t.Fatalf("unexpected labels: %q", mr.Labels)
+	}
+}
+
+func TestUpdateMergeRequestLabels(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v4/projects/100/merge_requests/42" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("add_labels"); got != "sonar::has-blockers,coverage-low" {
+			t.Fatalf("unexpected add_labels: %q", got)
+		}
+		if got := r.PostForm.Get("remove_labels"); got != "sonar::gate-failed" {
+			t.Fatalf("unexpected remove_labels: %q", got)
+		}
+		if _, ok := r.PostForm["labels"]; ok {
+			t.Fatalf("expected labels to be left unchanged")
+		}
+		_, _ = w.Write([]byte(`{"iid":42}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	err := client.UpdateMergeRequestLabels(
+		context.Background(),
+		100,
+		42,
+		[]string{"sonar::has-blockers", "coverage-low"},
+		[]string{"sonar::gate-failed"},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
 }
 
 func TestListMergeRequestChangesSuccess(t *testing.T) {
@@ -401,6 +447,29 @@ func TestResolveMergeRequestDiscussionSuccess(t *testing.T) {
 	}
 }
 
+func TestReopenMergeRequestDiscussion(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/api/v4/projects/100/merge_requests/42/discussions/discussion-1" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("resolved"); got != "false" {
+			t.Fatalf("unexpected resolved value: %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if err := client.ReopenMergeRequestDiscussion(context.Background(), 100, 42, "discussion-1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
 func TestListMergeRequestNotesWithPagination(t *testing.T) {
 	t.Parallel()
 
@@ -470,3 +539,559 @@ func TestUpdateMergeRequestNoteSuccess(t *testing.T) {
 		t.Fatalf("expected no error, got %v", err)
 	}
 }
+
+func TestCreateDraftNoteSuccess(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("unexpected method: %s", r.Method)
+		}
+		if r.URL.Path != "/api/v4/projects/100/merge_requests/42/draft_notes" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+
+		expected := map[string]string{
+			"note":                    "draft body",
+			"position[position_type]": "text",
+			"position[base_sha]":      "base",
+			"position[start_sha]":     "start",
+			"position[head_sha]":      "head",
+			"position[old_path]":      "src/main.go",
+			"position[new_path]":      "src/main.go",
+			"position[old_line]":      "7",
+			"position[new_line]":      "9",
+		}
+		for key, want := range expected {
+			if got := r.PostForm.Get(key); got != want {
+				t.Fatalf("unexpected %s: got %q want %q", key, got, want)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":77,"note":"draft body"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	draftID, err := client.CreateDraftNote(
+		context.Background(),
+		100,
+		42,
+		"draft body",
+		"src/main.go",
+		"src/main.go",
+		7,
+		9,
+		DiffRefs{BaseSHA: "base", StartSHA: "start", HeadSHA: "head"},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if draftID != 77 {
+		t.Fatalf("unexpected draft note ID: %d", draftID)
+	}
+}
+
+func TestBulkPublishDraftNotesSuccess(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("unexpected method: %s", r.Method)
+		}
+		if r.URL.Path != "/api/v4/projects/100/merge_requests/42/draft_notes/bulk_publish" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if err := client.BulkPublishDraftNotes(context.Background(), 100, 42); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestListAndPublishDraftNotes(t *testing.T) {
+	t.Parallel()
+
+	var published []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			_, _ = w.Write([]byte(`[{"id":3,"note":"pending"},{"id":4,"note":"mine"}]`))
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/publish"):
+			published = append(published, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	drafts, err := client.ListDraftNotes(context.Background(), 100, 42)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(drafts, []DraftNote{{ID: 3, Note: "pending"}, {ID: 4, Note: "mine"}}) {
+		t.Fatalf("expected two draft notes, got %+v", drafts)
+	}
+
+	if err := client.PublishDraftNote(context.Background(), 100, 42, 4); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(published, []string{"/api/v4/projects/100/merge_requests/42/draft_notes/4/publish"}) {
+		t.Fatalf("expected draft 4 to be published, got %q", published)
+	}
+}
+
+func TestDeleteDraftNoteNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Fatalf("unexpected method: %s", r.Method)
+		}
+		if r.URL.Path != "/api/v4/projects/100/merge_requests/42/draft_notes/77" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		http.Error(w, `{"message":"404 Not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	err := client.DeleteDraftNote(context.Background(), 100, 42, 77)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDeleteMergeRequestNote(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Fatalf("unexpected method: %s", r.Method)
+		}
+		if r.URL.Path != "/api/v4/projects/100/merge_requests/42/notes/15" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if err := client.DeleteMergeRequestNote(context.Background(), 100, 42, 15); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := client.DeleteMergeRequestNote(context.Background(), 100, 42, 0); err == nil {
+		t.Fatal("expected error for non-positive note ID")
+	}
+}
+
+func TestListMergeRequestDiscussionsDecodesAuthorAndResolver(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"d1","resolved":true,"resolvable":true,"notes":[` +
+			`{"id":7,"body":"first","author":{"id":1,"username":"bot"},"resolved_by":{"id":2,"username":"alice"},"position":{"new_path":"main.go"}},` +
+			`{"id":8,"body":"second","author":{"id":2,"username":"alice"},"resolved_by":null,"system":true}]}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	discussions, err := client.ListMergeRequestDiscussions(context.Background(), 100, 42)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(discussions) != 1 || len(discussions[0].Notes) != 2 {
+		t.Fatalf("expected 1 discussion with 2 notes, got %+v", discussions)
+	}
+
+	first := discussions[0].Notes[0]
+	if first.ID != 7 || first.Author != (User{ID: 1, Username: "bot"}) || first.ResolvedBy != (User{ID: 2, Username: "alice"}) || first.NewPath != "main.go" {
+		t.Fatalf("unexpected first note: %+v", first)
+	}
+	if second := discussions[0].Notes[1]; second.ResolvedBy != (User{}) || !second.System || second.NewPath != "" {
+		t.Fatalf("expected a system note without resolver, got %+v", second)
+	}
+}
+
+func TestCreateDiscussionNoteSuccess(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("unexpected method: %s", r.Method)
+		}
+		if r.URL.Path != "/api/v4/projects/100/merge_requests/42/discussions/discussion-1/notes" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("body"); got != "reply" {
+			t.Fatalf("unexpected body: %q", got)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if err := client.CreateDiscussionNote(context.Background(), 100, 42, "discussion-1", "reply"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestGetUserPrefersPrivateEmail(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/users/7":
+			_, _ = w.Write([]byte(`{"id":7,"username":"alice","email":"alice@corp.example.com","public_email":"alice@example.com"}`))
+		case "/api/v4/users/8":
+			_, _ = w.Write([]byte(`{"id":8,"username":"bob","public_email":"bob@example.com"}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	for id, expected := range map[int]User{
+		7: {ID: 7, Username: "alice", Email: "alice@corp.example.com"},
+		8: {ID: 8, Username: "bob", Email: "bob@example.com"},
+	} {
+		user, err := client.GetUser(context.Background(), id)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if user != expected {
+			t.Fatalf("expected %+v, got %+v", expected, user)
+		}
+	}
+}
+
+func TestSearchIssueDescriptionsFollowsPages(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v4/projects/100/issues" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("search") != "tracked" || query.Get("in") != "description" {
+			t.Fatalf("expected a description search, got %q", r.URL.RawQuery)
+		}
+		if query.Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+			_, _ = w.Write([]byte(`[{"iid":1,"web_url":"https://gitlab.example.com/issues/1","description":"first tracked"}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"iid":2,"description":"second tracked"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	issues, err := client.SearchIssueDescriptions(context.Background(), 100, "tracked")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []Issue{
+		{IID: 1, WebURL: "https://gitlab.example.com/issues/1", Description: "first tracked"},
+		{IID: 2, Description: "second tracked"},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, issues)
+	}
+}
+
+func TestGetProjectAccessLevel(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/projects/100/members/all/7":
+			_, _ = w.Write([]byte(`{"id":7,"username":"alice","access_level":30}`))
+		case "/api/v4/projects/100/members/all/8":
+			http.Error(w, `{"message":"404 Not found"}`, http.StatusNotFound)
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	for id, expected := range map[int]int{7: 30, 8: 0} {
+		level, err := client.GetProjectAccessLevel(context.Background(), 100, id)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if level != expected {
+			t.Fatalf("user %d: expected access level %d, got %d", id, expected, level)
+		}
+	}
+}
+
+func TestSetCommitStatus(t *testing.T) {
+	t.Parallel()
+
+	var forms []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v4/projects/100/statuses/head" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		forms = append(forms, r.PostForm.Encode())
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	status := CommitStatus{
+		Name:        "sonarqube/quality-gate",
+		State:       "success",
+		Description: "Quality gate passed",
+		TargetURL:   "https://sonar.example.com/dashboard?id=app",
+	}
+	if err := client.SetCommitStatus(context.Background(), 100, "head", status); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := "description=Quality+gate+passed&name=sonarqube%2Fquality-gate&state=success&target_url=https%3A%2F%2Fsonar.example.com%2Fdashboard%3Fid%3Dapp"
+	if forms[0] != expected {
+		t.Fatalf("expected form %q, got %q", expected, forms[0])
+	}
+}
+
+func TestGetCommitStatus(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v4/projects/100/repository/commits/head/statuses" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if name := r.URL.Query().Get("name"); name != "sonarqube/quality-gate" {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name":"sonarqube/quality-gate","status":"success","description":"Quality gate passed","target_url":"https://sonar.example.com"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	status, exists, err := client.GetCommitStatus(context.Background(), 100, "head", "sonarqube/quality-gate")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	expected := CommitStatus{Name: "sonarqube/quality-gate", State: "success", Description: "Quality gate passed", TargetURL: "https://sonar.example.com"}
+	if !exists || status != expected {
+		t.Fatalf("expected %+v, got %+v (exists %t)", expected, status, exists)
+	}
+
+	if _, exists, err := client.GetCommitStatus(context.Background(), 100, "head", "ci/other"); err != nil || exists {
+		t.Fatalf("expected no status, got exists %t and error %v", exists, err)
+	}
+}
+
+func TestGetCommitStatusPicksLatestPipeline(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("sort"); got != "desc" {
+			t.Fatalf("expected sort=desc, got %q", got)
+		}
+		if got := r.URL.Query().Get("order_by"); got != "id" {
+			t.Fatalf("expected order_by=id, got %q", got)
+		}
+		_, _ = w.Write([]byte(`[` +
+			`{"id":7,"pipeline_id":1,"name":"sonarqube/quality-gate","status":"failed","description":"Quality gate failed"},` +
+			`{"id":12,"pipeline_id":2,"name":"sonarqube/quality-gate","status":"success","description":"Quality gate passed"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	status, exists, err := client.GetCommitStatus(context.Background(), 100, "head", "sonarqube/quality-gate")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !exists || status.State != "success" || status.Description != "Quality gate passed" {
+		t.Fatalf("expected the status of the latest pipeline, got %+v (exists %t)", status, exists)
+	}
+}
+
+func TestGetCommitStatusNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"404 Commit Not Found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if _, exists, err := client.GetCommitStatus(context.Background(), 100, "head", "sonarqube/quality-gate"); err != nil || exists {
+		t.Fatalf("expected no status, got exists %t and error %v", exists, err)
+	}
+}
+
+func TestMergeRequestStatusChecks(t *testing.T) {
+	t.Parallel()
+
+	var response string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/status_checks":
+			_, _ = w.Write([]byte(`[{"id":2,"name":"SonarQube","external_url":"https://sonar.example.com/hook","status":"pending"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/status_check_responses":
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			response = r.PostForm.Encode()
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	checks, err := client.ListMergeRequestStatusChecks(context.Background(), 100, 42)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(checks, []StatusCheck{{ID: 2, Name: "SonarQube", Status: "pending"}}) {
+		t.Fatalf("unexpected status checks: %+v", checks)
+	}
+
+	if err := client.SetStatusCheckResponse(context.Background(), 100, 42, 2, "head", "passed"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if response != "external_status_check_id=2&sha=head&status=passed" {
+		t.Fatalf("unexpected status check response: %q", response)
+	}
+	if err := client.SetStatusCheckResponse(context.Background(), 100, 42, 0, "head", "passed"); err == nil {
+		t.Fatalf("expected error for a missing status check ID")
+	}
+}
+
+func TestListMergeRequestStatusChecksWithPagination(t *testing.T) {
+	t.Parallel()
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v4/projects/100/merge_requests/42/status_checks" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("per_page"); got != "100" {
+			t.Fatalf("unexpected per_page: %q", got)
+		}
+
+		switch page := r.URL.Query().Get("page"); page {
+		case "1":
+			w.Header().Set("X-Next-Page", "2")
+			_, _ = w.Write([]byte(`[{"id":1,"name":"Security","status":"passed"}]`))
+		case "2":
+			_, _ = w.Write([]byte(`[{"id":2,"name":"SonarQube","status":"pending"}]`))
+		default:
+			t.Fatalf("unexpected page: %s", page)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	checks, err := client.ListMergeRequestStatusChecks(context.Background(), 100, 42)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if requestCount != 2 {
+		t.Fatalf("expected 2 requests, got %d", requestCount)
+	}
+	expected := []StatusCheck{{ID: 1, Name: "Security", Status: "passed"}, {ID: 2, Name: "SonarQube", Status: "pending"}}
+	if !reflect.DeepEqual(checks, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, checks)
+	}
+}
+
+func TestCreateIssue(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v4/projects/100/issues" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("title"); got != "Fix it" {
+			t.Fatalf("expected title %q, got %q", "Fix it", got)
+		}
+		if got := r.PostForm.Get("labels"); got != "sonarqube,security" {
+			t.Fatalf("expected labels, got %q", got)
+		}
+		if got := r.PostForm.Get("due_date"); got != "2024-05-10" {
+			t.Fatalf("expected due date, got %q", got)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"iid":5,"web_url":"https://gitlab.example.com/group/app/-/issues/5"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	issue, err := client.CreateIssue(context.Background(), 100, NewIssue{
+		Title:       "Fix it",
+		Description: "details",
+		Labels:      "sonarqube,security",
+		DueDate:     "2024-05-10",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if issue != (Issue{IID: 5, WebURL: "https://gitlab.example.com/group/app/-/issues/5"}) {
+		t.Fatalf("expected issue 5, got %+v", issue)
+	}
+
+	if _, err := client.CreateIssue(context.Background(), 100, NewIssue{}); err == nil {
+		t.Fatalf("expected error for an empty title")
+	}
+}
+
+func TestGetCurrentUser(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Fatalf("unexpected method: %s", r.Method)
+		}
+		if r.URL.Path != "/api/v4/user" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("PRIVATE-TOKEN"); got != "secret-token" {
+			t.Fatalf("expected PRIVATE-TOKEN header, got %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "" {
+			t.Fatalf("expected a GET request without a form body, got Content-Type %q", got)
+		}
+		_, _ = w.Write([]byte(`{"id":5,"username":"sonar-bot","name":"Sonar Bot"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	user, err := client.GetCurrentUser(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if user != (User{ID: 5, Username: "sonar-bot"}) {
+		t.Fatalf("expected sonar-bot user, got %+v", user)
+	}
+}
@@ -0,0 +1,372 @@
+package i18n
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	LocaleEnglish = "en"
+	LocaleRussian = "ru"
+)
+
+// Catalog holds every user-facing phrase of MR comments and the action log.
+// Fields ending in a format verb are used with fmt or the template printf.
+type Catalog struct {
+	Locale string
+
+	// Inline discussions.
+	IssueTitle         string
+	IssuesTitle        string
+	Severity           string
+	Type               string
+	Message            string
+	RuleKey            string
+	Impacts            string
+	CleanCodeAttribute string
+	Line               string
+	Rule               string
+	RuleReference      string
+	OpenInSonar        string
+
+	// Summary note. SummaryHeading also identifies summary notes on later runs.
+	SummaryHeading     string
+	Dashboard          string
+	QualityGate        string
+	QualityGatePassed  string
+	QualityGateFailed  string
+	QualityGateWarning string
+	OverallCoverage    string
+	NewCodeCoverage    string
+	TotalIssues        string
+	NewInMergeRequest  string
+	// PreexistingInTouchedLines takes the target branch, PreexistingSummary the
+	// number of pre-existing issues.
+	PreexistingInTouchedLines string
+	PreexistingSummary        string
+	SinceLastAnalysis         string
+	Unchanged                 string
+	NewIssues                 string
+	FixedIssues               string
+	UnchangedIssues           string
+	IssuesBySeverity          string
+	IssuesBySoftwareQuality   string
+	IssuesByFile              string
+	// FilesWithIssues takes the number of files.
+	FilesWithIssues          string
+	File                     string
+	InlineColumn             string
+	SummaryOnly              string
+	TopRules                 string
+	IssuesWithoutLineBinding string
+	TrackedIssues            string
+	// OverflowSummary takes the number of demoted issues.
+	OverflowSummary string
+	// SummaryContinued heads continuation notes and takes the part number.
+	SummaryContinued string
+
+	// Discussion replies. TransitionRecorded takes the issue keys, the transition
+	// name and the username of the reviewer.
+	TransitionRecorded      string
+	TransitionAccepted      string
+	TransitionFalsePositive string
+	// Replies to /sonar commands. CommandTransitioned takes the issue keys, the
+	// transition name and the author; CommandAssigned the issue keys, the assignee
+	// and the author; CommandFailed and CommandNoOpenIssues the command;
+	// CommandFailed also the error; CommandUnknown the command; CommandNotAllowed
+	// the command, the author and the required role.
+	CommandTransitioned string
+	CommandAssigned     string
+	CommandFailed       string
+	CommandNoOpenIssues string
+	CommandUnknown      string
+	CommandNotAllowed   string
+	// CommandSonarComment is the SonarQube comment carrying a command reason; it
+	// takes the reason, the author and the merge request IID.
+	CommandSonarComment string
+	// MirroredReplySource credits a GitLab reply copied to SonarQube and takes the
+	// merge request IID.
+	MirroredReplySource string
+	// Reply to discussions whose issues are gone. NoLongerReported is followed by
+	// the commit link.
+	NoLongerReported    string
+	Pipeline            string
+	KeptOpenWithReplies string
+	// ReportedAgain replies to discussions whose issues came back after a resolved
+	// reply; it takes the short commit SHA.
+	ReportedAgain string
+
+	// GitLab issues created for findings. TrackedIssueTitle takes the issue
+	// message, TrackedRuleTitle the rule and the number of issues and
+	// TrackedIssueSource the merge request reference.
+	TrackedIssueTitle  string
+	TrackedRuleTitle   string
+	TrackedIssueSource string
+	// CommitStatusDescription takes the quality gate status, overall and new code
+	// coverage and the number of issues.
+	CommitStatusDescription string
+
+	// Action log lines, each ending with a newline.
+	LogDryRun             string
+	LogActionSummary      string
+	LogResolved           string
+	LogKeptOpen           string
+	LogReopened           string
+	LogPostedInline       string
+	LogDemoted            string
+	LogInlineSkipped      string
+	LogSummaryPosted      string
+	LogSummaryUpdated     string
+	LogSummarySkipped     string
+	LogSummarySplit       string
+	LogTransitioned       string
+	LogCommands           string
+	LogCommandsSkipped    string
+	LogMirrored           string
+	LogAssigned           string
+	LogUnmappedAuthor     string
+	LogCreatedIssues      string
+	LogLabelsUpdated      string
+	LogLabelsUnchanged    string
+	LogQualityReport      string
+	LogMergeRequestTarget string
+
+	// Commit status log lines take the status name, the short SHA and the state;
+	// LogCommitStatusPlanned also the description.
+	LogCommitStatus          string
+	LogCommitStatusUnchanged string
+	LogCommitStatusPlanned   string
+
+	// External status check log lines take the status and the check name.
+	LogStatusCheck          string
+	LogStatusCheckUnchanged string
+	LogStatusCheckPlanned   string
+}
+
+var catalogs = map[string]Catalog{
+	LocaleEnglish: {
+		Locale:                    LocaleEnglish,
+		IssueTitle:                "SonarQube issue",
+		IssuesTitle:               "SonarQube issues",
+		Severity:                  "Severity",
+		Type:                      "Type",
+		Message:                   "Message",
+		RuleKey:                   "Rule key",
+		Impacts:                   "Impacts",
+		CleanCodeAttribute:        "Clean code attribute",
+		Line:                      "Line",
+		Rule:                      "Rule",
+		RuleReference:             "rule",
+		OpenInSonar:               "Open in SonarQube",
+		SummaryHeading:            "SonarQube summary",
+		Dashboard:                 "SonarQube dashboard",
+		QualityGate:               "Quality gate",
+		QualityGatePassed:         "passed",
+		QualityGateFailed:         "failed",
+		QualityGateWarning:        "warning",
+		OverallCoverage:           "Overall coverage",
+		NewCodeCoverage:           "New code coverage",
+		TotalIssues:               "Total issues",
+		NewInMergeRequest:         "New in this MR",
+		PreexistingInTouchedLines: "Pre-existing in touched lines (already in `%s`)",
+		PreexistingSummary:        "%d pre-existing issues in touched lines",
+		SinceLastAnalysis:         "Since last analysis",
+		Unchanged:                 "unchanged",
+		NewIssues:                 "New issues",
+		FixedIssues:               "Fixed issues",
+		UnchangedIssues:           "Unchanged issues",
+		IssuesBySeverity:          "Issues by severity",
+		IssuesBySoftwareQuality:   "Issues by software quality",
+		IssuesByFile:              "Issues by file",
+		FilesWithIssues:           "%d files with issues",
+		File:                      "File",
+		InlineColumn:              "Inline",
+		SummaryOnly:               "Summary only",
+		TopRules:                  "Top violated rules",
+		IssuesWithoutLineBinding:  "SonarQube issues without line binding",
+		TrackedIssues:             "GitLab issues tracking findings",
+		OverflowSummary:           "%d more issues not posted inline (inline comment limit reached)",
+		SummaryContinued:          "SonarQube summary (continued, part %d)",
+		TransitionRecorded:        "Marked %s as **%s** in SonarQube because @%s resolved this discussion.",
+		TransitionAccepted:        "accepted",
+		TransitionFalsePositive:   "false positive",
+		CommandTransitioned:       "Marked %s as **%s** in SonarQube as requested by @%s.",
+		CommandAssigned:           "Assigned %s to `%s` in SonarQube as requested by @%s.",
+		CommandFailed:             "Could not apply `%s`: %s",
+		CommandNoOpenIssues:       "Skipped `%s`: the issues of this discussion are no longer open in SonarQube.",
+		CommandUnknown:            "Unknown command `%s`. Use `/sonar falsepositive <reason>`, `/sonar accept [reason]` or `/sonar assign @user`.",
+		CommandNotAllowed:         "Ignored `%s`: @%s needs at least the %s role in this project to run /sonar commands.",
+		CommandSonarComment:       "%s\n\n(@%s in GitLab merge request !%d)",
+		MirroredReplySource:       "GitLab merge request !%d",
+		NoLongerReported:          "No longer reported by SonarQube as of commit",
+		Pipeline:                  "pipeline",
+		KeptOpenWithReplies:       "Left open because the discussion has replies.",
+		ReportedAgain:             "Reported by SonarQube again as of commit `%s`.",
+		TrackedIssueTitle:         "[SonarQube] %s",
+		TrackedRuleTitle:          "[SonarQube] Rule %s: %d issues",
+		TrackedIssueSource:        "Reported by SonarQube in merge request %s.",
+		CommitStatusDescription:   "Quality gate %s, coverage %.2f%%, new code coverage %.2f%%, %d issues",
+		LogDryRun:                 "Dry-run enabled: skipping GitLab discussion resolution and comment publishing\n",
+		LogActionSummary:          "Action log: found %d issues, published %d comments\n",
+		LogResolved:               "Resolved %d previous SonarQube discussions in merge request %d\n",
+		LogKeptOpen:               "Kept %d fixed SonarQube discussions with human replies open\n",
+		LogReopened:               "Reopened %d SonarQube discussions resolved by reviewers while their issues are still reported\n",
+		LogPostedInline:           "Posted %d inline SonarQube discussions to merge request %d\n",
+		LogDemoted:                "Demoted %d inline SonarQube discussions to the summary: %d over --max-comments-per-file=%d, %d over --max-inline-comments=%d\n",
+		LogInlineSkipped:          "Skipped %d inline SonarQube discussions after --write-timeout=%s; their issues are listed in the summary\n",
+		LogSummaryPosted:          "Posted summary SonarQube note in merge request %d\n",
+		LogSummaryUpdated:         "Updated summary SonarQube note in merge request %d\n",
+		LogSummarySkipped:         "Skipped (dry-run) summary SonarQube note in merge request %d\n",
+		LogSummarySplit:           "Split the SonarQube summary into %d notes to fit the GitLab note size limit\n",
+		LogTransitioned:           "Transitioned %d SonarQube issues of %d discussions resolved in GitLab\n",
+		LogCommands:               "Applied %d of %d /sonar commands from merge request discussions\n",
+		LogCommandsSkipped:        "Skipped %d /sonar commands after --write-timeout=%s; they are applied on the next run\n",
+		LogMirrored:               "Copied %d GitLab replies to SonarQube issue comments\n",
+		LogAssigned:               "Assigned %d SonarQube issues to %s (GitLab @%s): %s\n",
+		LogUnmappedAuthor:         "Did not assign SonarQube issues: no SonarQube user found for GitLab @%s\n",
+		LogCreatedIssues:          "Created %d GitLab issues for SonarQube findings\n",
+		LogLabelsUpdated:          "Updated merge request labels (added: %s; removed: %s)\n",
+		LogLabelsUnchanged:        "Merge request labels are up to date\n",
+		LogCommitStatus:           "Set commit status %q on %s to %s\n",
+		LogCommitStatusUnchanged:  "Commit status %q on %s is already %s\n",
+		LogCommitStatusPlanned:    "Planned commit status %q on %s: %s, %s\n",
+		LogStatusCheck:            "Reported %s to external status check %q\n",
+		LogStatusCheckUnchanged:   "Skipped reporting %s: external status check %q already has it\n",
+		LogStatusCheckPlanned:     "Planned %s for external status check %q\n",
+		LogQualityReport:          "Quality gate: %s, coverage: %.2f%%, new code coverage: %.2f%%\n",
+		LogMergeRequestTarget:     "Resolved GitLab merge request: project_id=%d, mr_iid=%d\n",
+	},
+	LocaleRussian: {
+		Locale:                    LocaleRussian,
+		IssueTitle:                "Проблема SonarQube",
+		IssuesTitle:               "Проблемы SonarQube",
+		Severity:                  "Серьезность",
+		Type:                      "Тип",
+		Message:                   "Сообщение",
+		RuleKey:                   "Правило",
+		Impacts:                   "Влияние",
+		CleanCodeAttribute:        "Атрибут чистого кода",
+		Line:                      "Строка",
+		Rule:                      "Правило",
+		RuleReference:             "правило",
+		OpenInSonar:               "Открыть в SonarQube",
+		SummaryHeading:            "Сводка SonarQube",
+		Dashboard:                 "Дашборд SonarQube",
+		QualityGate:               "Quality gate",
+		QualityGatePassed:         "пройден",
+		QualityGateFailed:         "не пройден",
+		QualityGateWarning:        "предупреждение",
+		OverallCoverage:           "Общее покрытие",
+		NewCodeCoverage:           "Покрытие нового кода",
+		TotalIssues:               "Всего проблем",
+		NewInMergeRequest:         "Новые в этом MR",
+		PreexistingInTouchedLines: "Уже были в измененных строках (есть в `%s`)",
+		PreexistingSummary:        "Проблем, которые уже были в измененных строках: %d",
+		SinceLastAnalysis:         "С прошлого анализа",
+		Unchanged:                 "без изменений",
+		NewIssues:                 "Новые проблемы",
+		FixedIssues:               "Исправленные проблемы",
+		UnchangedIssues:           "Оставшиеся проблемы",
+		IssuesBySeverity:          "Проблемы по серьезности",
+		IssuesBySoftwareQuality:   "Проблемы по software quality",
+		IssuesByFile:              "Проблемы по файлам",
+		FilesWithIssues:           "Файлов с проблемами: %d",
+		File:                      "Файл",
+		InlineColumn:              "Inline",
+		SummaryOnly:               "Только в summary",
+		TopRules:                  "Самые частые правила",
+		IssuesWithoutLineBinding:  "Проблемы SonarQube без привязки к строке",
+		TrackedIssues:             "Задачи GitLab по проблемам",
+		OverflowSummary:           "Еще %d проблем не опубликованы inline (достигнут лимит комментариев)",
+		SummaryContinued:          "Сводка SonarQube (продолжение, часть %d)",
+		TransitionRecorded:        "Проблемы %s помечены в SonarQube как **%s**: дискуссию закрыл(а) @%s.",
+		TransitionAccepted:        "принято",
+		TransitionFalsePositive:   "ложное срабатывание",
+		CommandTransitioned:       "Проблемы %s помечены в SonarQube как **%s** по запросу @%s.",
+		CommandAssigned:           "Проблемы %s назначены в SonarQube на `%s` по запросу @%s.",
+		CommandFailed:             "Не удалось выполнить `%s`: %s",
+		CommandNoOpenIssues:       "Команда `%s` пропущена: проблем этой дискуссии больше нет среди открытых в SonarQube.",
+		CommandUnknown:            "Неизвестная команда `%s`. Используйте `/sonar falsepositive <причина>`, `/sonar accept [причина]` или `/sonar assign @user`.",
+		CommandNotAllowed:         "Команда `%s` проигнорирована: для команд /sonar у @%s должна быть роль не ниже %s в этом проекте.",
+		CommandSonarComment:       "%s\n\n(@%s в GitLab merge request !%d)",
+		MirroredReplySource:       "GitLab merge request !%d",
+		NoLongerReported:          "SonarQube больше не сообщает об этой проблеме начиная с коммита",
+		Pipeline:                  "пайплайн",
+		KeptOpenWithReplies:       "Дискуссия оставлена открытой, так как в ней есть ответы.",
+		ReportedAgain:             "SonarQube снова сообщает об этой проблеме начиная с коммита `%s`.",
+		TrackedIssueTitle:         "[SonarQube] %s",
+		TrackedRuleTitle:          "[SonarQube] Правило %s: проблем %d",
+		TrackedIssueSource:        "Найдено SonarQube в merge request %s.",
+		CommitStatusDescription:   "Quality gate %s, покрытие %.2f%%, покрытие нового кода %.2f%%, проблем: %d",
+		LogDryRun:                 "Включен dry-run: резолв дискуссий и публикация комментариев в GitLab пропущены\n",
+		LogActionSummary:          "Журнал действий: найдено проблем %d, опубликовано комментариев %d\n",
+		LogResolved:               "Закрыто предыдущих дискуссий SonarQube: %d в merge request %d\n",
+		LogKeptOpen:               "Оставлено открытыми исправленных дискуссий SonarQube с ответами людей: %d\n",
+		LogReopened:               "Переоткрыто дискуссий SonarQube, закрытых ревьюерами при неисправленных проблемах: %d\n",
+		LogPostedInline:           "Опубликовано inline-дискуссий SonarQube: %d в merge request %d\n",
+		LogDemoted:                "Перенесено в summary inline-дискуссий SonarQube: %d (по --max-comments-per-file: %d при лимите %d, по --max-inline-comments: %d при лимите %d)\n",
+		LogInlineSkipped:          "Пропущено inline-дискуссий SonarQube: %d (истек --write-timeout=%s), их проблемы перечислены в summary\n",
+		LogSummaryPosted:          "Summary-комментарий SonarQube опубликован в merge request %d\n",
+		LogSummaryUpdated:         "Summary-комментарий SonarQube обновлен в merge request %d\n",
+		LogSummarySkipped:         "Summary-комментарий SonarQube пропущен (dry-run) в merge request %d\n",
+		LogSummarySplit:           "Summary SonarQube разбит на %d комментариев из-за лимита размера заметки GitLab\n",
+		LogTransitioned:           "Переведено проблем SonarQube: %d по %d дискуссиям, закрытым в GitLab\n",
+		LogCommands:               "Выполнено команд /sonar из дискуссий merge request: %d из %d\n",
+		LogCommandsSkipped:        "Пропущено команд /sonar: %d (истек --write-timeout=%s), они будут выполнены при следующем запуске\n",
+		LogMirrored:               "Скопировано ответов из GitLab в комментарии SonarQube: %d\n",
+		LogAssigned:               "Назначено проблем SonarQube: %d на %s (GitLab @%s): %s\n",
+		LogUnmappedAuthor:         "Проблемы SonarQube не назначены: не найден пользователь SonarQube для GitLab @%s\n",
+		LogCreatedIssues:          "Создано задач GitLab по проблемам SonarQube: %d\n",
+		LogLabelsUpdated:          "Обновлены метки merge request (добавлены: %s; удалены: %s)\n",
+		LogLabelsUnchanged:        "Метки merge request не изменились\n",
+		LogCommitStatus:           "Статус коммита %q на %s: %s\n",
+		LogCommitStatusUnchanged:  "Статус коммита %q на %s уже %s\n",
+		LogCommitStatusPlanned:    "Запланирован статус коммита %q на %s: %s, %s\n",
+		LogStatusCheck:            "Отправлен результат %s во внешнюю проверку статуса %q\n",
+		LogStatusCheckUnchanged:   "Результат %s не отправлен: внешняя проверка статуса %q уже его содержит\n",
+		LogStatusCheckPlanned:     "Запланирован результат %s для внешней проверки статуса %q\n",
+		LogQualityReport:          "Quality gate: %s, покрытие: %.2f%%, покрытие нового кода: %.2f%%\n",
+		LogMergeRequestTarget:     "Merge request GitLab: project_id=%d, mr_iid=%d\n",
+	},
+}
+
+// Lookup returns the catalog for a locale such as "ru", "ru_RU.UTF-8" or "en-US".
+func Lookup(locale string) (Catalog, bool) {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if catalog, ok := catalogs[locale]; ok {
+		return catalog, true
+	}
+
+	language, _, _ := strings.Cut(locale, "_")
+	language, _, _ = strings.Cut(language, "-")
+	language, _, _ = strings.Cut(language, ".")
+	catalog, ok := catalogs[language]
+	return catalog, ok
+}
+
+// English returns the default catalog.
+func English() Catalog {
+	return catalogs[LocaleEnglish]
+}
+
+// Locales returns the supported locale codes in sorted order.
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// SummaryHeadings returns the summary heading of every locale, so that a summary
+// posted in one locale is still found after the locale changes.
+func SummaryHeadings() []string {
+	headings := make([]string, 0, len(catalogs))
+	for _, locale := range Locales() {
+		headings = append(headings, catalogs[locale].SummaryHeading)
+	}
+
+	return headings
+}
@@ -0,0 +1,58 @@
+package i18n
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestLookupNormalizesLocale(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]string{
+		"en":          LocaleEnglish,
+		" RU ":        LocaleRussian,
+		"ru_RU.UTF-8": LocaleRussian,
+		"en-US":       LocaleEnglish,
+	} {
+		catalog, ok := Lookup(input)
+		if !ok || catalog.Locale != expected {
+			t.Fatalf("Lookup(%q) = %q, %v; want %q", input, catalog.Locale, ok, expected)
+		}
+	}
+
+	if _, ok := Lookup("de"); ok {
+		t.Fatal("did not expect catalog for unsupported locale")
+	}
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	t.Parallel()
+
+	verbRegex := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	english := reflect.ValueOf(English())
+	for _, locale := range Locales() {
+		catalog := reflect.ValueOf(catalogs[locale])
+		for i := range catalog.NumField() {
+			name := catalog.Type().Field(i).Name
+			value := catalog.Field(i).String()
+			if value == "" {
+				t.Fatalf("%s: missing %s", locale, name)
+			}
+
+			expectedVerbs := verbRegex.FindAllString(english.Field(i).String(), -1)
+			if verbs := verbRegex.FindAllString(value, -1); !reflect.DeepEqual(verbs, expectedVerbs) {
+				t.Fatalf("%s: %s has format verbs %v, want %v", locale, name, verbs, expectedVerbs)
+			}
+		}
+	}
+}
+
+func TestSummaryHeadingsCoverAllLocales(t *testing.T) {
+	t.Parallel()
+
+	headings := SummaryHeadings()
+	if !reflect.DeepEqual(headings, []string{"SonarQube summary", "Сводка SonarQube"}) {
+		t.Fatalf("unexpected summary headings: %v", headings)
+	}
+}
@@ -17,20 +17,77 @@ const maxResponseBodyForError = 512
 
 var ErrUnauthorized = errors.New("unauthorized SonarQube API request")
 
+const (
+	AuthSchemeBasic  = "basic"
+	AuthSchemeBearer = "bearer"
+)
+
+var sonarCloudHosts = []string{"sonarcloud.io", "sonarqube.us"}
+
 type Client struct {
-	baseURL    string
-	token      string
-	httpClient *http.Client
+	baseURL      string
+	token        string
+	authScheme   string
+	organization string
+	branch       string
+	pullRequest  string
+	httpClient   *http.Client
+}
+
+// Option customizes a Client created by NewClient.
+type Option func(*Client)
+
+// WithOrganization sets the organization sent to SonarCloud endpoints that require it.
+func WithOrganization(organization string) Option {
+	return func(c *Client) {
+		c.organization = strings.TrimSpace(organization)
+	}
+}
+
+// WithBranch scopes issue searches and the quality report to the analysis of a
+// branch.
+func WithBranch(branch string) Option {
+	return func(c *Client) {
+		c.branch = strings.TrimSpace(branch)
+	}
+}
+
+// WithPullRequest scopes issue searches and the quality report to the analysis
+// of a pull request.
+func WithPullRequest(pullRequest string) Option {
+	return func(c *Client) {
+		c.pullRequest = strings.TrimSpace(pullRequest)
+	}
+}
+
+// WithAuthScheme selects how the token is sent: AuthSchemeBasic uses it as the
+// basic auth username, AuthSchemeBearer sends an Authorization: Bearer header.
+func WithAuthScheme(scheme string) Option {
+	return func(c *Client) {
+		c.authScheme = strings.ToLower(strings.TrimSpace(scheme))
+	}
 }
 
 type Issue struct {
-	Key      string
-	Rule     string
-	Type     string
-	Severity string
-	Message  string
-	FilePath string
-	Line     int
+	Key                        string
+	Rule                       string
+	Type                       string
+	Severity                   string
+	Message                    string
+	FilePath                   string
+	Line                       int
+	Impacts                    []Impact
+	CleanCodeAttribute         string
+	CleanCodeAttributeCategory string
+	// Hash is the checksum of the issue line content, stable across line moves.
+	Hash string
+	// Assignee is the login of the assigned user, empty when unassigned.
+	Assignee string
+}
+
+type Impact struct {
+	SoftwareQuality string
+	Severity        string
 }
 
 type QualityReport struct {
@@ -59,13 +116,24 @@ type issuesSearchResponse struct {
 }
 
 type apiIssue struct {
-	Key       string `json:"key"`
-	Rule      string `json:"rule"`
-	Type      string `json:"type"`
-	Severity  string `json:"severity"`
-	Message   string `json:"message"`
-	Component string `json:"component"`
-	Line      int    `json:"line"`
+	Key                        string      `json:"key"`
+	Rule                       string      `json:"rule"`
+	Type                       string      `json:"type"`
+	Severity                   string      `json:"severity"`
+	Message                    string      `json:"message"`
+	Component                  string      `json:"component"`
+	Line                       int         `json:"line"`
+	Impacts                    []apiImpact `json:"impacts"`
+	CleanCodeAttribute         string      `json:"cleanCodeAttribute"`
+	CleanCodeAttributeCategory string      `json:"cleanCodeAttributeCategory"`
+	CreationDate               string      `json:"creationDate"`
+	Hash                       string      `json:"hash"`
+	Assignee                   string      `json:"assignee"`
+}
+
+type apiImpact struct {
+	SoftwareQuality string `json:"softwareQuality"`
+	Severity        string `json:"severity"`
 }
 
 type qualityGateProjectStatusResponse struct {
@@ -85,18 +153,50 @@ type apiMeasure struct {
 	Value  string `json:"value"`
 }
 
-func NewClient(baseURL, token string, httpClient *http.Client) *Client {
+func NewClient(baseURL, token string, httpClient *http.Client, opts ...Option) *Client {
 	normalizedURL := strings.TrimRight(strings.TrimSpace(baseURL), "/")
 
 	if httpClient == nil {
 		httpClient = &http.Client{Timeout: 20 * time.Second}
 	}
 
-	return &Client{
+	client := &Client{
 		baseURL:    normalizedURL,
 		token:      strings.TrimSpace(token),
+		authScheme: AuthSchemeBasic,
 		httpClient: httpClient,
 	}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// IsSonarCloudURL reports whether the URL points to a SonarCloud instance.
+func IsSonarCloudURL(rawURL string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, cloudHost := range sonarCloudHosts {
+		if host == cloudHost || strings.HasSuffix(host, "."+cloudHost) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsValidAuthScheme(scheme string) bool {
+	switch strings.ToLower(strings.TrimSpace(scheme)) {
+	case AuthSchemeBasic, AuthSchemeBearer:
+		return true
+	default:
+		return false
+	}
 }
 
 func (c *Client) ValidateAuthentication(ctx context.Context) error {
@@ -120,43 +220,21 @@ func (c *Client) FetchProjectIssues(ctx context.Context, projectKey string) ([]I
 		return nil, err
 	}
 
-	const pageSize = 500
-	var (
-		allIssues []Issue
-		page      = 1
-	)
-
-	for {
-		values := url.Values{}
-		values.Set("componentKeys", projectKey)
-		values.Set("p", strconv.Itoa(page))
-		values.Set("ps", strconv.Itoa(pageSize))
+	query := url.Values{}
+	query.Set("componentKeys", projectKey)
+	c.setSearchScope(query)
 
-		var payload issuesSearchResponse
-		if err := c.getJSON(ctx, "/api/issues/search", values, &payload); err != nil {
-			return nil, err
-		}
-
-		for _, issue := range payload.Issues {
-			allIssues = append(allIssues, Issue{
-				Key:      issue.Key,
-				Rule:     issue.Rule,
-				Type:     issue.Type,
-				Severity: issue.Severity,
-				Message:  issue.Message,
-				FilePath: extractFilePath(issue.Component),
-				Line:     issue.Line,
-			})
-		}
-
-		if payload.Paging.PageSize <= 0 || page*payload.Paging.PageSize >= payload.Paging.Total {
-			break
-		}
+	return c.searchIssues(ctx, query)
+}
 
-		page++
+// FetchServerVersion returns the plain-text version reported by /api/server/version.
+func (c *Client) FetchServerVersion(ctx context.Context) (string, error) {
+	body, err := c.getText(ctx, "/api/server/version", url.Values{})
+	if err != nil {
+		return "", err
 	}
 
-	return allIssues, nil
+	return strings.TrimSpace(body), nil
 }
 
 func (c *Client) FetchQualityReport(ctx context.Context, projectKey string) (QualityReport, error) {
@@ -185,6 +263,7 @@ func (c *Client) FetchQualityReport(ctx context.Context, projectKey string) (Qua
 func (c *Client) fetchQualityGateStatus(ctx context.Context, projectKey string) (string, error) {
 	values := url.Values{}
 	values.Set("projectKey", projectKey)
+	c.setAnalysisScope(values)
 
 	var payload qualityGateProjectStatusResponse
 	if err := c.getJSON(ctx, "/api/qualitygates/project_status", values, &payload); err != nil {
@@ -198,6 +277,7 @@ func (c *Client) fetchCoverageMetrics(ctx context.Context, projectKey string) (f
 	values := url.Values{}
 	values.Set("component", projectKey)
 	values.Set("metricKeys", "coverage,new_coverage")
+	c.setAnalysisScope(values)
 
 	var payload measuresComponentResponse
 	if err := c.getJSON(ctx, "/api/measures/component", values, &payload); err != nil {
@@ -249,41 +329,144 @@ func parseMetricFloat(value string) (float64, error) {
 }
 
 func (c *Client) getJSON(ctx context.Context, endpoint string, query url.Values, target any) error {
+	resp, err := c.get(ctx, endpoint, query)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	decoder := json.NewDecoder(resp.Body)
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("failed to decode SonarQube response from %s: %w", endpoint, err)
+	}
+
+	return nil
+}
+
+func (c *Client) getText(ctx context.Context, endpoint string, query url.Values) (string, error) {
+	resp, err := c.get(ctx, endpoint, query)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read SonarQube response from %s: %w", endpoint, err)
+	}
+
+	return string(body), nil
+}
+
+func (c *Client) get(ctx context.Context, endpoint string, query url.Values) (*http.Response, error) {
 	requestURL := c.baseURL + endpoint
 	if len(query) > 0 {
 		requestURL += "?" + query.Encode()
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SonarQube request: %w", err)
+	}
+
+	return c.do(req, endpoint)
+}
+
+// postForm sends a form to a SonarQube web service that changes data.
+func (c *Client) postForm(ctx context.Context, endpoint string, form url.Values) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+endpoint, strings.NewReader(form.Encode()))
 	if err != nil {
 		return fmt.Errorf("failed to create SonarQube request: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	req.SetBasicAuth(c.token, "")
+	resp, err := c.do(req, endpoint)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+func (c *Client) do(req *http.Request, endpoint string) (*http.Response, error) {
+	c.authorize(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to connect to SonarQube at %s: %w", c.baseURL, err)
+		return nil, fmt.Errorf("failed to connect to SonarQube at %s: %w", c.baseURL, err)
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
 
 	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return fmt.Errorf("%w: HTTP %d from %s", ErrUnauthorized, resp.StatusCode, endpoint)
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: HTTP %d from %s", ErrUnauthorized, resp.StatusCode, endpoint)
 	}
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyForError))
-		return fmt.Errorf("SonarQube API request failed for %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("SonarQube API request failed for %s: HTTP %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
-	decoder := json.NewDecoder(resp.Body)
-	if err := decoder.Decode(target); err != nil {
-		return fmt.Errorf("failed to decode SonarQube response from %s: %w", endpoint, err)
+	return resp, nil
+}
+
+func (c *Client) authorize(req *http.Request) {
+	if c.authScheme == AuthSchemeBearer {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		return
 	}
 
-	return nil
+	req.SetBasicAuth(c.token, "")
+}
+
+// setSearchScope limits an issue search to unresolved issues of the organization
+// and analysis. Without resolved=false the search also returns fixed, accepted
+// and false-positive issues.
+func (c *Client) setSearchScope(values url.Values) {
+	values.Set("resolved", "false")
+	if c.organization != "" {
+		values.Set("organization", c.organization)
+	}
+	c.setAnalysisScope(values)
+}
+
+// setAnalysisScope selects the branch or pull request analysis of the project.
+func (c *Client) setAnalysisScope(values url.Values) {
+	switch {
+	case c.pullRequest != "":
+		values.Set("pullRequest", c.pullRequest)
+	case c.branch != "":
+		values.Set("branch", c.branch)
+	}
+}
+
+func convertIssue(issue apiIssue) Issue {
+	var impacts []Impact
+	for _, impact := range issue.Impacts {
+		impacts = append(impacts, Impact{
+			SoftwareQuality: NormalizeSeverity(impact.SoftwareQuality),
+			Severity:        NormalizeSeverity(impact.Severity),
+		})
+	}
+
+	return Issue{
+		Key:                        issue.Key,
+		Rule:                       issue.Rule,
+		Type:                       issue.Type,
+		Severity:                   issue.Severity,
+		Message:                    issue.Message,
+		FilePath:                   extractFilePath(issue.Component),
+		Line:                       issue.Line,
+		Impacts:                    impacts,
+		CleanCodeAttribute:         issue.CleanCodeAttribute,
+		CleanCodeAttributeCategory: issue.CleanCodeAttributeCategory,
+		Hash:                       issue.Hash,
+		Assignee:                   issue.Assignee,
+	}
 }
 
 func extractFilePath(component string) string {
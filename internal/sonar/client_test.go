@@ -32,6 +32,125 @@ func TestValidateAuthenticationSuccess(t *testing.T) {
 	}
 }
 
+func TestClientAgainstSonarCloudCompatibleAPI(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret-token" {
+			t.Fatalf("expected bearer token, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case "/api/issues/search":
+			if got := r.URL.Query().Get("organization"); got != "acme" {
+				t.Fatalf("unexpected organization query for issues: %q", got)
+			}
+			_, _ = w.Write([]byte(`{
+				"issues":[{"key":"A","rule":"go:S100","type":"BUG","severity":"MAJOR","message":"Issue A","component":"acme_demo:src/a.go","line":4}],
+				"paging":{"pageIndex":1,"pageSize":500,"total":1}
+			}`))
+		case "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"ERROR"}}`))
+		case "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"50"},{"metric":"new_coverage","value":"40"}]}}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		server.URL,
+		"secret-token",
+		server.Client(),
+		WithOrganization("acme"),
+		WithAuthScheme(AuthSchemeBearer),
+	)
+
+	if err := client.ValidateAuthentication(context.Background()); err != nil {
+		t.Fatalf("expected no authentication error, got %v", err)
+	}
+
+	issues, err := client.FetchProjectIssues(context.Background(), "acme_demo")
+	if err != nil {
+		t.Fatalf("expected no issues error, got %v", err)
+	}
+	if len(issues) != 1 || issues[0].FilePath != "src/a.go" {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+
+	report, err := client.FetchQualityReport(context.Background(), "acme_demo")
+	if err != nil {
+		t.Fatalf("expected no quality report error, got %v", err)
+	}
+	if report.QualityGateStatus != "failed" || report.OverallCoverage != 50 || report.NewCodeCoverage != 40 {
+		t.Fatalf("unexpected quality report: %+v", report)
+	}
+}
+
+func TestFetchProjectIssuesOmitsOrganizationByDefault(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["organization"]; ok {
+			t.Fatalf("did not expect organization query, got %q", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":500,"total":0}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if _, err := client.FetchProjectIssues(context.Background(), "demo"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestFetchProjectIssuesWithBranch(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("branch"); got != "main" {
+			t.Fatalf("unexpected branch query: %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"issues":[{"key":"A","rule":"go:S1","component":"demo:main.go","line":3,"hash":"abc123","assignee":"alice"}],"paging":{"pageIndex":1,"pageSize":500,"total":1}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client(), WithBranch(" main "))
+	issues, err := client.FetchProjectIssues(context.Background(), "demo")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(issues) != 1 || issues[0].Hash != "abc123" || issues[0].Assignee != "alice" {
+		t.Fatalf("expected issue with line hash and assignee, got %+v", issues)
+	}
+}
+
+func TestIsSonarCloudURL(t *testing.T) {
+	t.Parallel()
+
+	for rawURL, expected := range map[string]bool{
+		"https://sonarcloud.io":          true,
+		"https://SonarCloud.io/":         true,
+		"https://api.sonarcloud.io":      true,
+		"https://sonarqube.us":           true,
+		"https://sonar.example.com":      false,
+		"https://notsonarcloud.io":       false,
+		"https://sonarcloud.io.evil.com": false,
+	} {
+		if got := IsSonarCloudURL(rawURL); got != expected {
+			t.Fatalf("IsSonarCloudURL(%q): got %t want %t", rawURL, got, expected)
+		}
+	}
+}
+
 func TestValidateAuthenticationRejected(t *testing.T) {
 	t.Parallel()
 
@@ -104,6 +223,72 @@ func TestFetchProjectIssuesPaginationAndBinding(t *testing.T) {
 	}
 }
 
+func TestFetchProjectIssuesDecodesCleanCodeTaxonomy(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"issues":[
+				{
+					"key":"A","rule":"go:S2259","type":"BUG","severity":"MAJOR","message":"Issue A",
+					"component":"demo:src/a.go","line":3,
+					"cleanCodeAttribute":"LOGICAL","cleanCodeAttributeCategory":"INTENTIONAL",
+					"impacts":[{"softwareQuality":"RELIABILITY","severity":"HIGH"},{"softwareQuality":"security","severity":"low"}]
+				}
+			],
+			"paging":{"pageIndex":1,"pageSize":500,"total":1}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	issues, err := client.FetchProjectIssues(context.Background(), "demo")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %d", len(issues))
+	}
+
+	issue := issues[0]
+	if issue.CleanCodeAttribute != "LOGICAL" || issue.CleanCodeAttributeCategory != "INTENTIONAL" {
+		t.Fatalf("unexpected clean code attribute: %+v", issue)
+	}
+	if len(issue.Impacts) != 2 {
+		t.Fatalf("expected 2 impacts, got %+v", issue.Impacts)
+	}
+	if issue.Impacts[0] != (Impact{SoftwareQuality: "RELIABILITY", Severity: "HIGH"}) {
+		t.Fatalf("unexpected first impact: %+v", issue.Impacts[0])
+	}
+	if issue.Impacts[1] != (Impact{SoftwareQuality: "SECURITY", Severity: "LOW"}) {
+		t.Fatalf("unexpected second impact: %+v", issue.Impacts[1])
+	}
+}
+
+func TestFetchServerVersion(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/server/version" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("10.4.1.88267\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	version, err := client.FetchServerVersion(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if version != "10.4.1.88267" {
+		t.Fatalf("unexpected version: %q", version)
+	}
+}
+
 func TestFetchProjectIssuesUnauthorized(t *testing.T) {
 	t.Parallel()
 
@@ -175,6 +360,44 @@ func TestFetchQualityReport(t *testing.T) {
 	}
 }
 
+func TestPullRequestScopesIssuesAndQualityReport(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("pullRequest"); got != "42" {
+			t.Fatalf("unexpected pullRequest query for %s: %q", r.URL.Path, got)
+		}
+		if got := r.URL.Query().Get("branch"); got != "" {
+			t.Fatalf("did not expect branch query for %s, got %q", r.URL.Path, got)
+		}
+		paths = append(paths, r.URL.Path)
+
+		switch r.URL.Path {
+		case "/api/issues/search":
+			_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":500,"total":0}}`))
+		case "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80"},{"metric":"new_coverage","value":"70"}]}}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client(), WithBranch("feature"), WithPullRequest("42"))
+	if _, err := client.FetchProjectIssues(context.Background(), "demo"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := client.FetchQualityReport(context.Background(), "demo"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("expected issue, quality gate and measures requests, got %v", paths)
+	}
+}
+
 func TestFetchQualityReportWarningStatus(t *testing.T) {
 	t.Parallel()
 
@@ -291,3 +514,22 @@ func TestFetchQualityReportUnauthorized(t *testing.T) {
 		t.Fatalf("expected ErrUnauthorized, got %v", err)
 	}
 }
+
+func TestFetchProjectIssuesSkipsResolvedIssues(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("resolved"); got != "false" {
+			t.Fatalf("expected resolved=false, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":500,"total":0}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if _, err := client.FetchProjectIssues(context.Background(), "demo"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
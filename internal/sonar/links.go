@@ -0,0 +1,69 @@
+package sonar
+
+import (
+	"net/url"
+	"strings"
+)
+
+// UILinks builds links to the SonarQube web UI for one project, optionally scoped
+// to a branch or a pull request analysis.
+type UILinks struct {
+	BaseURL      string
+	ProjectKey   string
+	Organization string
+	Branch       string
+	PullRequest  string
+}
+
+// Issue links to the project issues page with the given issue opened.
+func (links UILinks) Issue(issueKey string) string {
+	if links.BaseURL == "" || issueKey == "" {
+		return ""
+	}
+
+	query := links.scopeQuery()
+	query.Set("open", issueKey)
+	return links.base() + "/project/issues?" + query.Encode()
+}
+
+// Rule links to the rule description. SonarCloud serves rules per organization.
+func (links UILinks) Rule(ruleKey string) string {
+	if links.BaseURL == "" || ruleKey == "" {
+		return ""
+	}
+
+	query := url.Values{}
+	query.Set("open", ruleKey)
+	query.Set("rule_key", ruleKey)
+	if IsSonarCloudURL(links.BaseURL) && links.Organization != "" {
+		return links.base() + "/organizations/" + url.PathEscape(links.Organization) + "/rules?" + query.Encode()
+	}
+
+	return links.base() + "/coding_rules?" + query.Encode()
+}
+
+// Dashboard links to the project overview of the branch or pull request.
+func (links UILinks) Dashboard() string {
+	if links.BaseURL == "" || links.ProjectKey == "" {
+		return ""
+	}
+
+	return links.base() + "/dashboard?" + links.scopeQuery().Encode()
+}
+
+func (links UILinks) base() string {
+	return strings.TrimRight(links.BaseURL, "/")
+}
+
+func (links UILinks) scopeQuery() url.Values {
+	query := url.Values{}
+	query.Set("id", links.ProjectKey)
+	switch {
+	case links.PullRequest != "":
+		query.Set("pullRequest", links.PullRequest)
+	case links.Branch != "":
+		query.Set("branch", links.Branch)
+	}
+
+	return query
+}
@@ -0,0 +1,30 @@
+package sonar
+
+import "testing"
+
+func TestUILinks(t *testing.T) {
+	t.Parallel()
+
+	links := UILinks{BaseURL: "https://sonar.example.com/", ProjectKey: "app", PullRequest: "42"}
+	if got := links.Issue("AX-1"); got != "https://sonar.example.com/project/issues?id=app&open=AX-1&pullRequest=42" {
+		t.Fatalf("unexpected issue link: %q", got)
+	}
+	if got := links.Rule("go:S100"); got != "https://sonar.example.com/coding_rules?open=go%3AS100&rule_key=go%3AS100" {
+		t.Fatalf("unexpected rule link: %q", got)
+	}
+	if got := links.Dashboard(); got != "https://sonar.example.com/dashboard?id=app&pullRequest=42" {
+		t.Fatalf("unexpected dashboard link: %q", got)
+	}
+
+	links = UILinks{BaseURL: "https://sonarcloud.io", ProjectKey: "acme_app", Organization: "acme", Branch: "feature/x"}
+	if got := links.Dashboard(); got != "https://sonarcloud.io/dashboard?branch=feature%2Fx&id=acme_app" {
+		t.Fatalf("unexpected branch dashboard link: %q", got)
+	}
+	if got := links.Rule("go:S100"); got != "https://sonarcloud.io/organizations/acme/rules?open=go%3AS100&rule_key=go%3AS100" {
+		t.Fatalf("unexpected SonarCloud rule link: %q", got)
+	}
+
+	if got := (UILinks{}).Issue("AX-1"); got != "" {
+		t.Fatalf("expected no link without base URL, got %q", got)
+	}
+}
@@ -0,0 +1,363 @@
+package sonar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSearchableIssues is the hard limit of /api/issues/search: SonarQube refuses
+// to return results beyond p*ps > 10000 for a single query.
+const maxSearchableIssues = 10000
+
+const issuesPageSize = 500
+
+const sonarDateTimeLayout = "2006-01-02T15:04:05-0700"
+
+var issueTypeFacet = []string{"BUG", "VULNERABILITY", "CODE_SMELL"}
+var issueSeverityFacet = []string{"BLOCKER", "CRITICAL", "MAJOR", "MINOR", "INFO"}
+
+// issueCollector accumulates search results in first-seen order and drops
+// duplicates returned by overlapping narrowed queries.
+type issueCollector struct {
+	seen   map[string]struct{}
+	issues []Issue
+}
+
+func newIssueCollector() *issueCollector {
+	return &issueCollector{seen: make(map[string]struct{})}
+}
+
+func (c *issueCollector) add(issue Issue) {
+	if issue.Key != "" {
+		if _, exists := c.seen[issue.Key]; exists {
+			return
+		}
+		c.seen[issue.Key] = struct{}{}
+	}
+
+	c.issues = append(c.issues, issue)
+}
+
+// searchIssues runs /api/issues/search for the query and narrows it by type,
+// severity and creation date windows whenever the result exceeds maxSearchableIssues.
+func (c *Client) searchIssues(ctx context.Context, query url.Values) ([]Issue, error) {
+	collector := newIssueCollector()
+	if err := c.collectIssues(ctx, query, collector); err != nil {
+		return nil, err
+	}
+
+	return collector.issues, nil
+}
+
+func (c *Client) collectIssues(ctx context.Context, query url.Values, collector *issueCollector) error {
+	firstPage, err := c.fetchIssuesPage(ctx, query, 1, issuesPageSize)
+	if err != nil {
+		return err
+	}
+
+	if firstPage.Paging.Total > maxSearchableIssues {
+		return c.collectNarrowedIssues(ctx, query, collector)
+	}
+
+	payload := firstPage
+	for page := 1; ; page++ {
+		if page > 1 {
+			payload, err = c.fetchIssuesPage(ctx, query, page, issuesPageSize)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, issue := range payload.Issues {
+			collector.add(convertIssue(issue))
+		}
+
+		if payload.Paging.PageSize <= 0 || page*payload.Paging.PageSize >= payload.Paging.Total {
+			return nil
+		}
+	}
+}
+
+func (c *Client) collectNarrowedIssues(ctx context.Context, query url.Values, collector *issueCollector) error {
+	for _, facet := range []struct {
+		param  string
+		values []string
+	}{
+		{param: "types", values: issueTypeFacet},
+		{param: "severities", values: issueSeverityFacet},
+	} {
+		if query.Get(facet.param) != "" {
+			continue
+		}
+
+		for _, value := range facet.values {
+			narrowed := cloneValues(query)
+			narrowed.Set(facet.param, value)
+			if err := c.collectIssues(ctx, narrowed, collector); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return c.collectIssuesByCreationDate(ctx, query, collector)
+}
+
+func (c *Client) collectIssuesByCreationDate(ctx context.Context, query url.Values, collector *issueCollector) error {
+	after, before, err := c.creationDateWindow(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	return c.collectIssuesInWindow(ctx, query, after, before, collector)
+}
+
+// collectIssuesInWindow fetches issues created in [after, before) and bisects the
+// window until every part fits under maxSearchableIssues.
+func (c *Client) collectIssuesInWindow(
+	ctx context.Context,
+	query url.Values,
+	after time.Time,
+	before time.Time,
+	collector *issueCollector,
+) error {
+	windowed := cloneValues(query)
+	windowed.Set("createdAfter", after.Format(sonarDateTimeLayout))
+	windowed.Set("createdBefore", before.Format(sonarDateTimeLayout))
+
+	firstPage, err := c.fetchIssuesPage(ctx, windowed, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	if firstPage.Paging.Total <= maxSearchableIssues {
+		if firstPage.Paging.Total == 0 {
+			return nil
+		}
+		return c.collectIssues(ctx, windowed, collector)
+	}
+
+	if before.Sub(after) <= time.Second {
+		return fmt.Errorf(
+			"cannot narrow SonarQube issue search below %d results: %d issues created between %s and %s",
+			maxSearchableIssues,
+			firstPage.Paging.Total,
+			after.Format(sonarDateTimeLayout),
+			before.Format(sonarDateTimeLayout),
+		)
+	}
+
+	middle := after.Add(before.Sub(after) / 2).Truncate(time.Second)
+	if !middle.After(after) {
+		middle = after.Add(time.Second)
+	}
+
+	if err := c.collectIssuesInWindow(ctx, query, after, middle, collector); err != nil {
+		return err
+	}
+
+	return c.collectIssuesInWindow(ctx, query, middle, before, collector)
+}
+
+// creationDateWindow returns a half-open window covering the oldest and newest
+// issue matching the query.
+func (c *Client) creationDateWindow(ctx context.Context, query url.Values) (time.Time, time.Time, error) {
+	oldest, err := c.issueCreationDateBound(ctx, query, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	newest, err := c.issueCreationDateBound(ctx, query, false)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return oldest, newest.Add(time.Second), nil
+}
+
+func (c *Client) issueCreationDateBound(ctx context.Context, query url.Values, ascending bool) (time.Time, error) {
+	sorted := cloneValues(query)
+	sorted.Set("s", "CREATION_DATE")
+	sorted.Set("asc", strconv.FormatBool(ascending))
+
+	payload, err := c.fetchIssuesPage(ctx, sorted, 1, 1)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(payload.Issues) == 0 {
+		return time.Time{}, fmt.Errorf("SonarQube issue search returned no issues to determine creation date range")
+	}
+
+	creationDate, err := time.Parse(sonarDateTimeLayout, payload.Issues[0].CreationDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse SonarQube issue creation date %q: %w", payload.Issues[0].CreationDate, err)
+	}
+
+	return creationDate, nil
+}
+
+func (c *Client) fetchIssuesPage(ctx context.Context, query url.Values, page, pageSize int) (issuesSearchResponse, error) {
+	values := cloneValues(query)
+	values.Set("p", strconv.Itoa(page))
+	values.Set("ps", strconv.Itoa(pageSize))
+
+	var payload issuesSearchResponse
+	if err := c.getJSON(ctx, "/api/issues/search", values, &payload); err != nil {
+		return issuesSearchResponse{}, err
+	}
+
+	return payload, nil
+}
+
+func cloneValues(values url.Values) url.Values {
+	cloned := make(url.Values, len(values))
+	for key, items := range values {
+		cloned[key] = append([]string(nil), items...)
+	}
+
+	return cloned
+}
+
+// fileIssuesBatchSize bounds the number of component keys per request so the
+// query string stays well below common proxy URL limits.
+const fileIssuesBatchSize = 50
+
+// fileIssuesWorkers bounds the number of concurrent /api/issues/search requests.
+const fileIssuesWorkers = 4
+
+// FetchFileIssues fetches issues only for the given repository paths of the project.
+// Paths are sent as component keys in batches that run concurrently; the result keeps
+// the batch order and is deduplicated by issue key.
+func (c *Client) FetchFileIssues(ctx context.Context, projectKey string, filePaths []string) ([]Issue, error) {
+	projectKey, err := normalizeProjectKey(projectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	batches := componentKeyBatches(projectKey, filePaths, fileIssuesBatchSize)
+	if len(batches) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]Issue, len(batches))
+	jobs := make(chan int)
+	errs := make(chan error, len(batches))
+	var wg sync.WaitGroup
+
+	workers := min(fileIssuesWorkers, len(batches))
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				query := url.Values{}
+				query.Set("componentKeys", strings.Join(batches[index], ","))
+				c.setSearchScope(query)
+
+				issues, err := c.searchIssues(ctx, query)
+				if err != nil {
+					errs <- err
+					cancel()
+					continue
+				}
+				results[index] = issues
+			}
+		}()
+	}
+
+dispatch:
+	for index := range batches {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+
+	collector := newIssueCollector()
+	for _, issues := range results {
+		for _, issue := range issues {
+			collector.add(issue)
+		}
+	}
+
+	return collector.issues, nil
+}
+
+// CountProjectIssues returns the total number of project issues reported by a
+// single one-item search page.
+func (c *Client) CountProjectIssues(ctx context.Context, projectKey string) (int, error) {
+	projectKey, err := normalizeProjectKey(projectKey)
+	if err != nil {
+		return 0, err
+	}
+
+	query := url.Values{}
+	query.Set("componentKeys", projectKey)
+	c.setSearchScope(query)
+
+	payload, err := c.fetchIssuesPage(ctx, query, 1, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return payload.Paging.Total, nil
+}
+
+// ProjectIssuesPageCount returns how many search requests a full-project fetch of
+// total issues needs without narrowing.
+func ProjectIssuesPageCount(total int) int {
+	if total <= 0 {
+		return 1
+	}
+
+	return (total + issuesPageSize - 1) / issuesPageSize
+}
+
+func componentKeyBatches(projectKey string, filePaths []string, batchSize int) [][]string {
+	seen := make(map[string]struct{}, len(filePaths))
+	var (
+		batches [][]string
+		current []string
+	)
+
+	for _, path := range filePaths {
+		path = strings.Trim(strings.TrimSpace(path), "/")
+		if path == "" {
+			continue
+		}
+
+		componentKey := projectKey + ":" + path
+		if _, exists := seen[componentKey]; exists {
+			continue
+		}
+		seen[componentKey] = struct{}{}
+
+		current = append(current, componentKey)
+		if len(current) == batchSize {
+			batches = append(batches, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+
+	return batches
+}
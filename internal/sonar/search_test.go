@@ -0,0 +1,254 @@
+package sonar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchProjectIssuesNarrowsByFacetsAboveSearchCap(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case query.Get("types") == "":
+			_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":500,"total":10500}}`))
+		case query.Get("types") == "CODE_SMELL" && query.Get("severities") == "":
+			_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":500,"total":10001}}`))
+		case query.Get("types") == "CODE_SMELL" && query.Get("severities") == "MAJOR":
+			_, _ = w.Write([]byte(`{
+				"issues":[
+					{"key":"SMELL-1","severity":"MAJOR","type":"CODE_SMELL","component":"demo:a.go","line":1},
+					{"key":"DUP","severity":"MAJOR","type":"CODE_SMELL","component":"demo:a.go","line":2}
+				],
+				"paging":{"pageIndex":1,"pageSize":500,"total":2}
+			}`))
+		case query.Get("types") == "BUG":
+			_, _ = w.Write([]byte(`{
+				"issues":[
+					{"key":"BUG-1","severity":"CRITICAL","type":"BUG","component":"demo:b.go","line":3},
+					{"key":"DUP","severity":"MAJOR","type":"CODE_SMELL","component":"demo:a.go","line":2}
+				],
+				"paging":{"pageIndex":1,"pageSize":500,"total":2}
+			}`))
+		default:
+			_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":500,"total":0}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	issues, err := client.FetchProjectIssues(context.Background(), "demo")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	keys := issueKeys(issues)
+	sort.Strings(keys)
+	want := []string{"BUG-1", "DUP", "SMELL-1"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected issue keys: got %v want %v", keys, want)
+	}
+}
+
+func TestFetchProjectIssuesNarrowsByCreationDate(t *testing.T) {
+	t.Parallel()
+
+	oldest := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newest := time.Date(2024, 1, 1, 0, 0, 40, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+
+		if query.Get("s") == "CREATION_DATE" {
+			date := oldest
+			if query.Get("asc") == "false" {
+				date = newest
+			}
+			_, _ = fmt.Fprintf(w, `{"issues":[{"key":"BOUND","creationDate":%q}],"paging":{"pageIndex":1,"pageSize":1,"total":20000}}`, date.Format(sonarDateTimeLayout))
+			return
+		}
+
+		after := query.Get("createdAfter")
+		if after == "" {
+			_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":500,"total":20000}}`))
+			return
+		}
+
+		afterTime, err := time.Parse(sonarDateTimeLayout, after)
+		if err != nil {
+			t.Fatalf("unexpected createdAfter %q: %v", after, err)
+		}
+		beforeTime, err := time.Parse(sonarDateTimeLayout, query.Get("createdBefore"))
+		if err != nil {
+			t.Fatalf("unexpected createdBefore %q: %v", query.Get("createdBefore"), err)
+		}
+
+		// Every second holds 1000 issues for the size probe; the full fetch only
+		// returns one marker issue per window.
+		total := 1
+		if query.Get("ps") == "1" {
+			total = int(beforeTime.Sub(afterTime)/time.Second) * 1000
+		}
+		_, _ = fmt.Fprintf(
+			w,
+			`{"issues":[{"key":"W-%d","component":"demo:a.go","line":1}],"paging":{"pageIndex":1,"pageSize":500,"total":%d}}`,
+			afterTime.Unix(),
+			total,
+		)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	query := url.Values{
+		"componentKeys": {"demo"},
+		"types":         {"CODE_SMELL"},
+		"severities":    {"MAJOR"},
+	}
+	issues, err := client.searchIssues(context.Background(), query)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	// The 41 second range must be bisected into windows of at most 10 seconds.
+	if len(issues) < 5 {
+		t.Fatalf("expected issues from several creation date windows, got %v", issueKeys(issues))
+	}
+	for _, issue := range issues {
+		if !strings.HasPrefix(issue.Key, "W-") {
+			t.Fatalf("unexpected issue %+v", issue)
+		}
+	}
+}
+
+func TestIssueCollectorDeduplicatesByKey(t *testing.T) {
+	t.Parallel()
+
+	collector := newIssueCollector()
+	collector.add(Issue{Key: "A", Message: "first"})
+	collector.add(Issue{Key: "B"})
+	collector.add(Issue{Key: "A", Message: "second"})
+
+	if len(collector.issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(collector.issues))
+	}
+	if collector.issues[0].Message != "first" {
+		t.Fatalf("expected first occurrence to win, got %+v", collector.issues[0])
+	}
+}
+
+func issueKeys(issues []Issue) []string {
+	keys := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+
+	return keys
+}
+
+func TestFetchFileIssuesBatchesComponentKeys(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		batches []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		components := r.URL.Query().Get("componentKeys")
+		if !strings.HasPrefix(components, "demo:") {
+			t.Fatalf("expected file component keys, got %q", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("components") != "" {
+			t.Fatalf("did not expect components query, got %q", r.URL.RawQuery)
+		}
+
+		mu.Lock()
+		batches = append(batches, components)
+		mu.Unlock()
+
+		keys := strings.Split(components, ",")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(
+			w,
+			`{"issues":[{"key":%q,"component":%q,"line":1},{"key":"SHARED","component":"demo:shared.go","line":1}],"paging":{"pageIndex":1,"pageSize":500,"total":2}}`,
+			keys[0],
+			keys[0],
+		)
+	}))
+	defer server.Close()
+
+	paths := make([]string, 0, 120)
+	for i := range 120 {
+		paths = append(paths, fmt.Sprintf("src/file%03d.go", i))
+	}
+	paths = append(paths, "src/file000.go", "")
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	issues, err := client.FetchFileIssues(context.Background(), "demo", paths)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(batches) != 3 {
+		t.Fatalf("expected 3 batched requests, got %d", len(batches))
+	}
+	sort.Strings(batches)
+	if got := len(strings.Split(batches[0], ",")); got != fileIssuesBatchSize {
+		t.Fatalf("expected first batch of %d components, got %d", fileIssuesBatchSize, got)
+	}
+
+	keys := issueKeys(issues)
+	want := []string{"demo:src/file000.go", "SHARED", "demo:src/file050.go", "demo:src/file100.go"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected issue keys: got %v want %v", keys, want)
+	}
+}
+
+func TestFetchFileIssuesReturnsBatchError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	_, err := client.FetchFileIssues(context.Background(), "demo", []string{"a.go", "b.go"})
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestFetchFileIssuesWithoutPathsSkipsRequests(t *testing.T) {
+	t.Parallel()
+
+	client := NewClient("http://127.0.0.1:1", "secret-token", nil)
+	issues, err := client.FetchFileIssues(context.Background(), "demo", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("expected no issues, got %+v", issues)
+	}
+}
+
+func TestProjectIssuesPageCount(t *testing.T) {
+	t.Parallel()
+
+	for total, expected := range map[int]int{0: 1, 1: 1, 500: 1, 501: 2, 10000: 20} {
+		if got := ProjectIssuesPageCount(total); got != expected {
+			t.Fatalf("ProjectIssuesPageCount(%d): got %d want %d", total, got, expected)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package sonar
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 var severityRanks = map[string]int{
 	"INFO":     0,
@@ -55,3 +58,174 @@ func FilterIssuesBySeverity(issues []Issue, threshold string) []Issue {
 
 	return filtered
 }
+
+const (
+	SoftwareQualityMaintainability = "MAINTAINABILITY"
+	SoftwareQualityReliability     = "RELIABILITY"
+	SoftwareQualitySecurity        = "SECURITY"
+)
+
+var softwareQualityOrder = []string{
+	SoftwareQualitySecurity,
+	SoftwareQualityReliability,
+	SoftwareQualityMaintainability,
+}
+
+var impactSeverityRanks = map[string]int{
+	"INFO":    0,
+	"LOW":     1,
+	"MEDIUM":  2,
+	"HIGH":    3,
+	"BLOCKER": 4,
+}
+
+var impactSeverityOrder = []string{"INFO", "LOW", "MEDIUM", "HIGH", "BLOCKER"}
+
+// legacyTypeQualities and legacySeverityImpacts follow the mapping SonarQube uses
+// to derive impacts from the legacy type and severity.
+var legacyTypeQualities = map[string]string{
+	"BUG":           SoftwareQualityReliability,
+	"VULNERABILITY": SoftwareQualitySecurity,
+	"CODE_SMELL":    SoftwareQualityMaintainability,
+}
+
+var legacySeverityImpacts = map[string]string{
+	"INFO":     "INFO",
+	"MINOR":    "LOW",
+	"MAJOR":    "MEDIUM",
+	"CRITICAL": "HIGH",
+	"BLOCKER":  "BLOCKER",
+}
+
+func SoftwareQualities() []string {
+	qualities := make([]string, len(softwareQualityOrder))
+	copy(qualities, softwareQualityOrder)
+
+	return qualities
+}
+
+func AllowedImpactSeverities() []string {
+	allowed := make([]string, len(impactSeverityOrder))
+	copy(allowed, impactSeverityOrder)
+
+	return allowed
+}
+
+func IsValidImpactSeverity(severity string) bool {
+	_, ok := impactSeverityRanks[NormalizeSeverity(severity)]
+	return ok
+}
+
+func IsValidSoftwareQuality(quality string) bool {
+	quality = NormalizeSeverity(quality)
+	for _, known := range softwareQualityOrder {
+		if quality == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseImpactThresholds parses a comma-separated list of QUALITY:SEVERITY pairs.
+// A bare severity applies to every software quality.
+func ParseImpactThresholds(value string) (map[string]string, error) {
+	thresholds := make(map[string]string)
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		quality, severity, hasQuality := strings.Cut(part, ":")
+		if !hasQuality {
+			severity = quality
+			quality = ""
+		}
+
+		severity = NormalizeSeverity(severity)
+		if !IsValidImpactSeverity(severity) {
+			return nil, fmt.Errorf(
+				"unknown impact severity %q (allowed: %s)",
+				severity,
+				strings.Join(impactSeverityOrder, ", "),
+			)
+		}
+
+		if !hasQuality {
+			for _, known := range softwareQualityOrder {
+				thresholds[known] = severity
+			}
+			continue
+		}
+
+		quality = NormalizeSeverity(quality)
+		if !IsValidSoftwareQuality(quality) {
+			return nil, fmt.Errorf(
+				"unknown software quality %q (allowed: %s)",
+				quality,
+				strings.Join(softwareQualityOrder, ", "),
+			)
+		}
+		thresholds[quality] = severity
+	}
+
+	return thresholds, nil
+}
+
+// EffectiveImpacts returns the impacts of an issue. An issue without impacts, as
+// reported by some plugins and external analyzers, gets the one derived from its
+// legacy type and severity; security hotspots and unknown values get none.
+func EffectiveImpacts(issue Issue) []Impact {
+	if len(issue.Impacts) > 0 {
+		return issue.Impacts
+	}
+
+	quality, knownType := legacyTypeQualities[NormalizeSeverity(issue.Type)]
+	severity, knownSeverity := legacySeverityImpacts[NormalizeSeverity(issue.Severity)]
+	if !knownType || !knownSeverity {
+		return nil
+	}
+
+	return []Impact{{SoftwareQuality: quality, Severity: severity}}
+}
+
+// FilterIssuesByImpact keeps issues with at least one impact that reaches the
+// threshold of its software quality. Qualities without a threshold are not
+// filtered, and issues without impacts are judged by EffectiveImpacts.
+func FilterIssuesByImpact(issues []Issue, thresholds map[string]string) []Issue {
+	if len(thresholds) == 0 {
+		all := make([]Issue, len(issues))
+		copy(all, issues)
+		return all
+	}
+
+	filtered := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		if issueMeetsImpactThresholds(issue, thresholds) {
+			filtered = append(filtered, issue)
+		}
+	}
+
+	return filtered
+}
+
+func issueMeetsImpactThresholds(issue Issue, thresholds map[string]string) bool {
+	for _, impact := range EffectiveImpacts(issue) {
+		impactRank, known := impactSeverityRanks[NormalizeSeverity(impact.Severity)]
+		if !known {
+			continue
+		}
+
+		threshold, hasThreshold := thresholds[NormalizeSeverity(impact.SoftwareQuality)]
+		if !hasThreshold {
+			return true
+		}
+		if impactRank >= impactSeverityRanks[threshold] {
+			return true
+		}
+	}
+
+	return false
+}
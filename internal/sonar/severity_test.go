@@ -1,6 +1,9 @@
 package sonar
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestFilterIssuesBySeverity(t *testing.T) {
 	t.Parallel()
@@ -42,7 +45,7 @@ func TestFilterIssuesBySeverityReturnsAllWithoutThreshold(t *testing.T) {
 	}
 
 	for i := range issues {
-		if filtered[i] != issues[i] {
+		if !reflect.DeepEqual(filtered[i], issues[i]) {
 			t.Fatalf("unexpected issue at index %d: got %+v want %+v", i, filtered[i], issues[i])
 		}
 	}
@@ -58,3 +61,84 @@ func TestIsValidSeverity(t *testing.T) {
 		t.Fatal("expected SEVERE to be invalid")
 	}
 }
+
+func TestParseImpactThresholds(t *testing.T) {
+	t.Parallel()
+
+	thresholds, err := ParseImpactThresholds("medium, security:low")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := map[string]string{
+		"SECURITY":        "LOW",
+		"RELIABILITY":     "MEDIUM",
+		"MAINTAINABILITY": "MEDIUM",
+	}
+	if !reflect.DeepEqual(thresholds, want) {
+		t.Fatalf("unexpected thresholds: got %v want %v", thresholds, want)
+	}
+
+	if _, err := ParseImpactThresholds("SECURITY:SEVERE"); err == nil {
+		t.Fatal("expected error for unknown impact severity")
+	}
+	if _, err := ParseImpactThresholds("PERFORMANCE:HIGH"); err == nil {
+		t.Fatal("expected error for unknown software quality")
+	}
+}
+
+func TestFilterIssuesByImpact(t *testing.T) {
+	t.Parallel()
+
+	issues := []Issue{
+		{Key: "S-LOW", Impacts: []Impact{{SoftwareQuality: "SECURITY", Severity: "LOW"}}},
+		{Key: "S-HIGH", Impacts: []Impact{{SoftwareQuality: "SECURITY", Severity: "HIGH"}}},
+		{Key: "M-LOW", Impacts: []Impact{{SoftwareQuality: "MAINTAINABILITY", Severity: "LOW"}}},
+		{Key: "MIXED", Impacts: []Impact{
+			{SoftwareQuality: "SECURITY", Severity: "LOW"},
+			{SoftwareQuality: "RELIABILITY", Severity: "LOW"},
+		}},
+		{Key: "NONE"},
+	}
+
+	filtered := FilterIssuesByImpact(issues, map[string]string{
+		"SECURITY":        "HIGH",
+		"MAINTAINABILITY": "MEDIUM",
+	})
+
+	got := make([]string, 0, len(filtered))
+	for _, issue := range filtered {
+		got = append(got, issue.Key)
+	}
+	want := []string{"S-HIGH", "MIXED"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected filtered issues: got %v want %v", got, want)
+	}
+}
+
+func TestFilterIssuesByImpactFallsBackToLegacySeverity(t *testing.T) {
+	t.Parallel()
+
+	issues := []Issue{
+		{Key: "VULN-CRITICAL", Type: "VULNERABILITY", Severity: "CRITICAL"},
+		{Key: "VULN-MAJOR", Type: "VULNERABILITY", Severity: "MAJOR"},
+		{Key: "SMELL-MAJOR", Type: "CODE_SMELL", Severity: "MAJOR"},
+		{Key: "BUG-MINOR", Type: "BUG", Severity: "MINOR"},
+		{Key: "HOTSPOT", Type: "SECURITY_HOTSPOT", Severity: "BLOCKER"},
+		{Key: "IMPACT-LOW", Type: "CODE_SMELL", Severity: "BLOCKER", Impacts: []Impact{{SoftwareQuality: "MAINTAINABILITY", Severity: "LOW"}}},
+	}
+
+	filtered := FilterIssuesByImpact(issues, map[string]string{
+		"SECURITY":        "HIGH",
+		"MAINTAINABILITY": "MEDIUM",
+	})
+
+	got := make([]string, 0, len(filtered))
+	for _, issue := range filtered {
+		got = append(got, issue.Key)
+	}
+	want := []string{"VULN-CRITICAL", "SMELL-MAJOR", "BUG-MINOR"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected filtered issues: got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package sonar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Issue workflow transitions accepted by /api/issues/do_transition.
+const (
+	TransitionAccept        = "accept"
+	TransitionWontFix       = "wontfix"
+	TransitionFalsePositive = "falsepositive"
+)
+
+// AcceptTransition returns the transition that accepts an issue on a server
+// version: SonarQube 10.4 replaced "wontfix" with "accept". Unparseable versions
+// use "wontfix", matching the legacy taxonomy fallback.
+func AcceptTransition(version string) string {
+	major, minor, ok := parseMajorMinor(version)
+	if !ok || major < 10 || (major == 10 && minor < 4) {
+		return TransitionWontFix
+	}
+
+	return TransitionAccept
+}
+
+// DoTransition applies a workflow transition such as TransitionFalsePositive to
+// an issue.
+func (c *Client) DoTransition(ctx context.Context, issueKey, transition string) error {
+	issueKey = strings.TrimSpace(issueKey)
+	if issueKey == "" {
+		return fmt.Errorf("issue key cannot be empty")
+	}
+	transition = strings.TrimSpace(transition)
+	if transition == "" {
+		return fmt.Errorf("transition cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Set("issue", issueKey)
+	form.Set("transition", transition)
+
+	return c.postForm(ctx, "/api/issues/do_transition", form)
+}
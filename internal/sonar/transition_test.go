@@ -0,0 +1,78 @@
+package sonar
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAcceptTransition(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		version  string
+		expected string
+	}{
+		{version: "9.9.4.87374", expected: TransitionWontFix},
+		{version: "10.3.0.82913", expected: TransitionWontFix},
+		{version: "10.4.1.88267", expected: TransitionAccept},
+		{version: "2025.1.0.102418", expected: TransitionAccept},
+		{version: "", expected: TransitionWontFix},
+		{version: "unknown", expected: TransitionWontFix},
+	}
+
+	for _, tc := range testCases {
+		if got := AcceptTransition(tc.version); got != tc.expected {
+			t.Fatalf("version %q: got %q want %q", tc.version, got, tc.expected)
+		}
+	}
+}
+
+func TestDoTransition(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("unexpected method: %s", r.Method)
+		}
+		if r.URL.Path != "/api/issues/do_transition" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if user, _, ok := r.BasicAuth(); !ok || user != "secret-token" {
+			t.Fatalf("expected basic auth token, got %q", user)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("issue"); got != "AX-1" {
+			t.Fatalf("unexpected issue: %q", got)
+		}
+		if got := r.PostForm.Get("transition"); got != TransitionFalsePositive {
+			t.Fatalf("unexpected transition: %q", got)
+		}
+		_, _ = w.Write([]byte(`{"issue":{"key":"AX-1"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if err := client.DoTransition(context.Background(), "AX-1", TransitionFalsePositive); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestDoTransitionUnauthorized(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "insufficient privileges", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	err := client.DoTransition(context.Background(), "AX-1", TransitionAccept)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}
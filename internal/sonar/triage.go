@@ -0,0 +1,43 @@
+package sonar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AddComment adds a Markdown comment to an issue.
+func (c *Client) AddComment(ctx context.Context, issueKey, text string) error {
+	issueKey = strings.TrimSpace(issueKey)
+	if issueKey == "" {
+		return fmt.Errorf("issue key cannot be empty")
+	}
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("comment text cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Set("issue", issueKey)
+	form.Set("text", text)
+
+	return c.postForm(ctx, "/api/issues/add_comment", form)
+}
+
+// AssignIssue assigns an issue to a SonarQube user login.
+func (c *Client) AssignIssue(ctx context.Context, issueKey, login string) error {
+	issueKey = strings.TrimSpace(issueKey)
+	if issueKey == "" {
+		return fmt.Errorf("issue key cannot be empty")
+	}
+	login = strings.TrimSpace(login)
+	if login == "" {
+		return fmt.Errorf("assignee login cannot be empty")
+	}
+
+	form := url.Values{}
+	form.Set("issue", issueKey)
+	form.Set("assignee", login)
+
+	return c.postForm(ctx, "/api/issues/assign", form)
+}
@@ -0,0 +1,64 @@
+package sonar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAddComment(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/issues/add_comment" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("issue"); got != "AX-1" {
+			t.Fatalf("unexpected issue: %q", got)
+		}
+		if got := r.PostForm.Get("text"); got != "generated code" {
+			t.Fatalf("unexpected text: %q", got)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if err := client.AddComment(context.Background(), "AX-1", "generated code"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestAssignIssue(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/issues/assign" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("issue"); got != "AX-1" {
+			t.Fatalf("unexpected issue: %q", got)
+		}
+		if got := r.PostForm.Get("assignee"); got != "alice" {
+			t.Fatalf("unexpected assignee: %q", got)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	if err := client.AssignIssue(context.Background(), "AX-1", " alice "); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := client.AssignIssue(context.Background(), "AX-1", " "); err == nil {
+		t.Fatal("expected error for empty assignee")
+	}
+}
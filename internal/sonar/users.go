@@ -0,0 +1,48 @@
+package sonar
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// User is a SonarQube account. Email is only returned to administrators.
+type User struct {
+	Login string
+	Name  string
+	Email string
+}
+
+type usersSearchResponse struct {
+	Users []struct {
+		Login string `json:"login"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"users"`
+}
+
+// SearchUsers returns the first page of active users whose login, name or email
+// contains the query.
+func (c *Client) SearchUsers(ctx context.Context, query string) ([]User, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("user search query cannot be empty")
+	}
+
+	values := url.Values{}
+	values.Set("q", query)
+	values.Set("ps", "50")
+
+	var payload usersSearchResponse
+	if err := c.getJSON(ctx, "/api/users/search", values, &payload); err != nil {
+		return nil, err
+	}
+
+	users := make([]User, 0, len(payload.Users))
+	for _, user := range payload.Users {
+		users = append(users, User{Login: user.Login, Name: user.Name, Email: user.Email})
+	}
+
+	return users, nil
+}
@@ -0,0 +1,35 @@
+package sonar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSearchUsers(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/users/search" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("q"); got != "alice@example.com" {
+			t.Fatalf("unexpected query: %q", got)
+		}
+		_, _ = w.Write([]byte(`{"users":[{"login":"alice","name":"Alice","email":"alice@example.com"},{"login":"bob","name":"Bob"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret-token", server.Client())
+	users, err := client.SearchUsers(context.Background(), " alice@example.com ")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []User{{Login: "alice", Name: "Alice", Email: "alice@example.com"}, {Login: "bob", Name: "Bob"}}
+	if !reflect.DeepEqual(users, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, users)
+	}
+}
@@ -0,0 +1,49 @@
+package sonar
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Taxonomy selects how issues are classified in comments and filters.
+type Taxonomy string
+
+const (
+	// TaxonomyLegacy uses the severity and type fields of SonarQube 9.x and older.
+	TaxonomyLegacy Taxonomy = "legacy"
+	// TaxonomyCleanCode uses software quality impacts introduced in SonarQube 10.2.
+	TaxonomyCleanCode Taxonomy = "clean-code"
+)
+
+// TaxonomyForVersion detects the taxonomy supported by a SonarQube server version
+// such as "10.4.1.88267". Unparseable versions fall back to the legacy taxonomy.
+func TaxonomyForVersion(version string) Taxonomy {
+	major, minor, ok := parseMajorMinor(version)
+	if !ok {
+		return TaxonomyLegacy
+	}
+
+	if major > 10 || (major == 10 && minor >= 2) {
+		return TaxonomyCleanCode
+	}
+
+	return TaxonomyLegacy
+}
+
+func parseMajorMinor(version string) (int, int, bool) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return major, minor, true
+}
@@ -0,0 +1,26 @@
+package sonar
+
+import "testing"
+
+func TestTaxonomyForVersion(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		version  string
+		expected Taxonomy
+	}{
+		{version: "9.9.4.87374", expected: TaxonomyLegacy},
+		{version: "10.1.0.73491", expected: TaxonomyLegacy},
+		{version: "10.2.1.78527", expected: TaxonomyCleanCode},
+		{version: "10.7", expected: TaxonomyCleanCode},
+		{version: "2025.1.0.102418", expected: TaxonomyCleanCode},
+		{version: "", expected: TaxonomyLegacy},
+		{version: "unknown", expected: TaxonomyLegacy},
+	}
+
+	for _, tc := range testCases {
+		if got := TaxonomyForVersion(tc.version); got != tc.expected {
+			t.Fatalf("version %q: got %q want %q", tc.version, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// labelChanges lists the rule labels to add to and remove from the merge request.
+type labelChanges struct {
+	add    []string
+	remove []string
+}
+
+func (changes labelChanges) empty() bool {
+	return len(changes.add) == 0 && len(changes.remove) == 0
+}
+
+// planLabelChanges compares the labels whose rules hold with the current merge
+// request labels. A label shared by several rules is kept while any of them
+// holds; labels without a rule are left alone. Labels are matched
+// case-insensitively, as GitLab does.
+func planLabelChanges(rules []config.LabelRule, current []string, report sonar.QualityReport, issues []sonar.Issue) labelChanges {
+	var labels []string
+	wanted := make(map[string]bool)
+	for _, rule := range rules {
+		key := strings.ToLower(rule.Label)
+		if _, seen := wanted[key]; !seen {
+			labels = append(labels, rule.Label)
+		}
+		wanted[key] = wanted[key] || labelRuleHolds(rule, report, issues)
+	}
+
+	var changes labelChanges
+	for _, label := range labels {
+		present := slices.ContainsFunc(current, func(value string) bool { return strings.EqualFold(value, label) })
+		switch want := wanted[strings.ToLower(label)]; {
+		case want && !present:
+			changes.add = append(changes.add, label)
+		case !want && present:
+			changes.remove = append(changes.remove, label)
+		}
+	}
+
+	return changes
+}
+
+func labelRuleHolds(rule config.LabelRule, report sonar.QualityReport, issues []sonar.Issue) bool {
+	if rule.GateStatus != "" {
+		return strings.EqualFold(strings.TrimSpace(report.QualityGateStatus), rule.GateStatus)
+	}
+
+	var value float64
+	switch rule.Metric {
+	case config.LabelMetricCoverage:
+		value = report.OverallCoverage
+	case config.LabelMetricNewCoverage:
+		value = report.NewCodeCoverage
+	case config.LabelMetricIssues:
+		value = float64(len(issues))
+	default:
+		for _, issue := range issues {
+			if strings.EqualFold(sonar.NormalizeSeverity(issue.Severity), rule.Metric) {
+				value++
+			}
+		}
+	}
+
+	switch rule.Operator {
+	case "<":
+		return value < rule.Threshold
+	case "<=":
+		return value <= rule.Threshold
+	case ">":
+		return value > rule.Threshold
+	case ">=":
+		return value >= rule.Threshold
+	}
+
+	return false
+}
+
+// formatLabelList renders labels for the action log.
+func formatLabelList(labels []string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestPlanLabelChanges(t *testing.T) {
+	t.Parallel()
+
+	rules := []config.LabelRule{
+		{Label: "sonar::gate-failed", GateStatus: "failed"},
+		{Label: "has-blockers", Metric: "blocker", Operator: ">", Threshold: 0},
+		{Label: "coverage-low", Metric: config.LabelMetricCoverage, Operator: "<", Threshold: 60},
+		{Label: "coverage-low", Metric: config.LabelMetricNewCoverage, Operator: "<", Threshold: 80},
+		{Label: "many-issues", Metric: config.LabelMetricIssues, Operator: ">=", Threshold: 3},
+	}
+	report := sonar.QualityReport{QualityGateStatus: "passed", OverallCoverage: 75, NewCodeCoverage: 70}
+	issues := []sonar.Issue{{Key: "A", Severity: "blocker"}, {Key: "B", Severity: "MAJOR"}}
+
+	changes := planLabelChanges(rules, []string{"backend", "SONAR::gate-failed", "many-issues"}, report, issues)
+
+	expected := labelChanges{
+		add:    []string{"has-blockers", "coverage-low"},
+		remove: []string{"sonar::gate-failed", "many-issues"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, changes)
+	}
+
+	changes = planLabelChanges(rules, []string{"has-blockers", "Coverage-Low"}, report, issues)
+	if !changes.empty() {
+		t.Fatalf("expected no label changes, got %+v", changes)
+	}
+}
+
+func TestRunWithLabelRulesUpdatesMergeRequestLabels(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		labels  string
+		updates []string
+		log     string
+	}{
+		{
+			name:    "changed",
+			labels:  `["backend","sonar::gate-failed"]`,
+			updates: []string{"add_labels=has-blockers&remove_labels=sonar%3A%3Agate-failed"},
+			log:     "Updated merge request labels (added: has-blockers; removed: sonar::gate-failed)",
+		},
+		{
+			name:   "unchanged",
+			labels: `["backend","has-blockers"]`,
+			log:    "Merge request labels are up to date",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu      sync.Mutex
+				updates []string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = r.ParseForm()
+				mu.Lock()
+				defer mu.Unlock()
+
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+					_, _ = w.Write([]byte(`{"iid":42,"labels":` + tc.labels + `,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+				case r.Method == http.MethodPut && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+					updates = append(updates, r.PostForm.Encode())
+					_, _ = w.Write([]byte(`{"iid":42}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+					_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}]}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+					_, _ = w.Write([]byte(`[]`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+					_, _ = w.Write([]byte(`[]`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+					_, _ = w.Write([]byte(`[]`))
+				case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/draft_notes"):
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"id":1}`))
+				case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+					w.WriteHeader(http.StatusCreated)
+				case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+					_, _ = w.Write([]byte(`{"valid":true}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+					_, _ = w.Write([]byte(`10.6.0.92116`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+					_, _ = w.Write([]byte(`{"issues":[{"key":"ISSUE-1","rule":"go:S100","type":"BUG","severity":"BLOCKER","message":"first","component":"project:main.go","line":12}],"paging":{"pageIndex":1,"pageSize":500,"total":1}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+					_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+					_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+					http.Error(w, "unexpected", http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			var output bytes.Buffer
+			err := runWith(
+				append(draftNotesTestArgs(server.URL), "--label-rules=sonar::gate-failed=gate-failed,has-blockers=blocker>0"),
+				func(string) string { return "" },
+				&output,
+			)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !reflect.DeepEqual(updates, tc.updates) {
+				t.Fatalf("expected label updates %q, got %q", tc.updates, updates)
+			}
+			assertCommentContains(t, output.String(), tc.log)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// inlineTypeOrder ranks issue types for inline comment limits, most important first.
+var inlineTypeOrder = []string{"VULNERABILITY", "BUG", "SECURITY_HOTSPOT", "CODE_SMELL"}
+
+// inlineLimitResult splits planned discussions into the ones to post and the ones
+// demoted to the summary, by the limit that demoted them.
+type inlineLimitResult struct {
+	kept      []inlinePost
+	overFile  []inlinePost
+	overTotal []inlinePost
+}
+
+func (result inlineLimitResult) demoted() []inlinePost {
+	return append(slices.Clone(result.overFile), result.overTotal...)
+}
+
+// limitInlinePosts keeps at most maxPerFile discussions per file and maxTotal
+// overall, picking them by severity, then type, then file order and line. Open
+// tool discussions, given by file in openPaths, count against both limits. Kept
+// discussions retain their planned order. A zero limit disables it.
+func limitInlinePosts(posts []inlinePost, paths []string, openPaths []string, maxTotal int, maxPerFile int) inlineLimitResult {
+	if maxTotal <= 0 && maxPerFile <= 0 {
+		return inlineLimitResult{kept: posts}
+	}
+
+	fileOrder := make(map[string]int, len(paths))
+	for position, path := range paths {
+		fileOrder[path] = position
+	}
+
+	ranked := make([]int, len(posts))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	slices.SortStableFunc(ranked, func(a, b int) int {
+		return compareInlinePosts(posts[a], posts[b], fileOrder)
+	})
+
+	var result inlineLimitResult
+	keep := make([]bool, len(posts))
+	perFile := make(map[string]int)
+	for _, path := range openPaths {
+		perFile[path]++
+	}
+	kept := len(openPaths)
+	for _, position := range ranked {
+		post := posts[position]
+		path := normalizeRepoPath(post.path.newPath)
+		switch {
+		case maxPerFile > 0 && perFile[path] >= maxPerFile:
+			result.overFile = append(result.overFile, post)
+		case maxTotal > 0 && kept >= maxTotal:
+			result.overTotal = append(result.overTotal, post)
+		default:
+			keep[position] = true
+			perFile[path]++
+			kept++
+		}
+	}
+
+	for position, post := range posts {
+		if keep[position] {
+			result.kept = append(result.kept, post)
+		}
+	}
+
+	return result
+}
+
+func compareInlinePosts(a, b inlinePost, fileOrder map[string]int) int {
+	if diff := inlinePostSeverityRank(a) - inlinePostSeverityRank(b); diff != 0 {
+		return diff
+	}
+	if diff := inlinePostTypeRank(a) - inlinePostTypeRank(b); diff != 0 {
+		return diff
+	}
+	if diff := fileOrder[normalizeRepoPath(a.path.newPath)] - fileOrder[normalizeRepoPath(b.path.newPath)]; diff != 0 {
+		return diff
+	}
+
+	return a.issues[0].Line - b.issues[0].Line
+}
+
+// inlinePostSeverityRank returns the rank of the most severe issue in the group.
+func inlinePostSeverityRank(post inlinePost) int {
+	best := len(summarySeverityOrder)
+	for _, issue := range post.issues {
+		if rank := slices.Index(summarySeverityOrder, sonar.NormalizeSeverity(issue.Severity)); rank >= 0 {
+			best = min(best, rank)
+		}
+	}
+
+	return best
+}
+
+func inlinePostTypeRank(post inlinePost) int {
+	best := len(inlineTypeOrder)
+	for _, issue := range post.issues {
+		if rank := slices.Index(inlineTypeOrder, strings.ToUpper(strings.TrimSpace(issue.Type))); rank >= 0 {
+			best = min(best, rank)
+		}
+	}
+
+	return best
+}
+
+// overflowIssue is an inline issue demoted to the summary because of a limit.
+type overflowIssue struct {
+	issue sonar.Issue
+	link  string
+}
+
+func overflowIssues(posts []inlinePost, index diffLineIndex, mergeRequestURL string) []overflowIssue {
+	var result []overflowIssue
+	for _, post := range posts {
+		for _, issue := range post.issues {
+			result = append(result, overflowIssue{
+				issue: issue,
+				link:  diffLineLink(mergeRequestURL, index, issue),
+			})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestLimitInlinePostsRanksBySeverityTypeAndFile(t *testing.T) {
+	t.Parallel()
+
+	post := func(key, severity, issueType, path string, line int) inlinePost {
+		return inlinePost{
+			issues: []sonar.Issue{{Key: key, Severity: severity, Type: issueType, FilePath: path, Line: line}},
+			path:   pathInfo{oldPath: path, newPath: path},
+		}
+	}
+	posts := []inlinePost{
+		post("minor", "MINOR", "BUG", "a.go", 1),
+		post("smell", "CRITICAL", "CODE_SMELL", "a.go", 2),
+		post("bug-b", "CRITICAL", "BUG", "b.go", 1),
+		post("bug-a", "CRITICAL", "BUG", "a.go", 9),
+		post("blocker", "BLOCKER", "CODE_SMELL", "b.go", 5),
+	}
+
+	result := limitInlinePosts(posts, []string{"a.go", "b.go"}, nil, 3, 0)
+	if keys := postKeys(result.kept); !reflect.DeepEqual(keys, []string{"bug-b", "bug-a", "blocker"}) {
+		t.Fatalf("unexpected kept discussions: %v", keys)
+	}
+	if keys := postKeys(result.overTotal); !reflect.DeepEqual(keys, []string{"smell", "minor"}) {
+		t.Fatalf("unexpected demoted discussions: %v", keys)
+	}
+
+	result = limitInlinePosts(posts, []string{"a.go", "b.go"}, nil, 3, 1)
+	if keys := postKeys(result.kept); !reflect.DeepEqual(keys, []string{"bug-a", "blocker"}) {
+		t.Fatalf("unexpected kept discussions with per-file limit: %v", keys)
+	}
+	if keys := postKeys(result.overFile); !reflect.DeepEqual(keys, []string{"bug-b", "smell", "minor"}) {
+		t.Fatalf("unexpected per-file demotions: %v", keys)
+	}
+	if len(result.overTotal) != 0 {
+		t.Fatalf("did not expect total limit demotions, got %v", postKeys(result.overTotal))
+	}
+}
+
+func TestLimitInlinePostsWithoutLimitsKeepsEverything(t *testing.T) {
+	t.Parallel()
+
+	posts := []inlinePost{{issues: []sonar.Issue{{Key: "A"}}}, {issues: []sonar.Issue{{Key: "B"}}}}
+	result := limitInlinePosts(posts, nil, []string{"a.go"}, 0, 0)
+	if len(result.kept) != 2 || len(result.demoted()) != 0 {
+		t.Fatalf("unexpected limit result: %+v", result)
+	}
+}
+
+func TestLimitInlinePostsCountsOpenDiscussions(t *testing.T) {
+	t.Parallel()
+
+	post := func(key, severity, path string, line int) inlinePost {
+		return inlinePost{
+			issues: []sonar.Issue{{Key: key, Severity: severity, Type: "BUG", FilePath: path, Line: line}},
+			path:   pathInfo{oldPath: path, newPath: path},
+		}
+	}
+	posts := []inlinePost{post("a1", "BLOCKER", "a.go", 1), post("b1", "MAJOR", "b.go", 1), post("b2", "MINOR", "b.go", 2)}
+	openPaths := []string{"a.go", "c.go"}
+
+	result := limitInlinePosts(posts, []string{"a.go", "b.go"}, openPaths, 3, 2)
+	if keys := postKeys(result.kept); !reflect.DeepEqual(keys, []string{"a1"}) {
+		t.Fatalf("unexpected kept discussions: %v", keys)
+	}
+	if keys := postKeys(result.overTotal); !reflect.DeepEqual(keys, []string{"b1", "b2"}) {
+		t.Fatalf("unexpected total limit demotions: %v", keys)
+	}
+
+	result = limitInlinePosts(posts, []string{"a.go", "b.go"}, openPaths, 3, 1)
+	if keys := postKeys(result.kept); !reflect.DeepEqual(keys, []string{"b1"}) {
+		t.Fatalf("unexpected kept discussions with per-file limit: %v", keys)
+	}
+	if keys := postKeys(result.overFile); !reflect.DeepEqual(keys, []string{"a1", "b2"}) {
+		t.Fatalf("unexpected per-file demotions: %v", keys)
+	}
+}
+
+func TestRunWithMaxInlineCommentsHoldsAcrossRuns(t *testing.T) {
+	t.Parallel()
+
+	type note struct {
+		ID       int    `json:"id"`
+		Body     string `json:"body"`
+		Position struct {
+			NewPath string `json:"new_path"`
+		} `json:"position"`
+	}
+	type discussion struct {
+		ID         string `json:"id"`
+		Resolvable bool   `json:"resolvable"`
+		Notes      []note `json:"notes"`
+	}
+
+	var (
+		mu          sync.Mutex
+		discussions []discussion
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,3 @@\n+one\n+two\n+three"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_ = json.NewEncoder(w).Encode(discussions)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			created := note{ID: len(discussions) + 1, Body: r.PostForm.Get("body")}
+			created.Position.NewPath = r.PostForm.Get("position[new_path]")
+			discussions = append(discussions, discussion{ID: created.Body, Resolvable: true, Notes: []note{created}})
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`10.6.0.92116`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			_, _ = w.Write([]byte(`{"issues":[` +
+				`{"key":"ISSUE-1","rule":"go:S1","type":"BUG","severity":"BLOCKER","message":"one","component":"project:main.go","line":12},` +
+				`{"key":"ISSUE-2","rule":"go:S2","type":"BUG","severity":"MAJOR","message":"two","component":"project:main.go","line":13},` +
+				`{"key":"ISSUE-3","rule":"go:S3","type":"BUG","severity":"MINOR","message":"three","component":"project:main.go","line":14}` +
+				`],"paging":{"pageIndex":1,"pageSize":500,"total":3}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	args := []string{
+		"--sonar-url=" + server.URL,
+		"--sonar-token=token",
+		"--sonar-project-key=project",
+		"--gitlab-url=" + server.URL,
+		"--gitlab-token=token",
+		"--project-id=100",
+		"--mr-iid=42",
+		"--max-inline-comments=2",
+	}
+	for run := 1; run <= 2; run++ {
+		var output bytes.Buffer
+		if err := runWith(args, func(string) string { return "" }, &output); err != nil {
+			t.Fatalf("run %d: expected no error, got %v", run, err)
+		}
+		if len(discussions) != 2 {
+			t.Fatalf("run %d: expected 2 inline discussions in total, got %d", run, len(discussions))
+		}
+	}
+}
+
+func postKeys(posts []inlinePost) []string {
+	var keys []string
+	for _, post := range posts {
+		keys = append(keys, post.issueKeys()...)
+	}
+
+	return keys
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// commentLinks builds the URLs rendered into comments. A zero value renders no links.
+type commentLinks struct {
+	sonar sonar.UILinks
+	// projectURL is the GitLab project web URL, derived from the MR web URL.
+	projectURL string
+	headSHA    string
+}
+
+func newCommentLinks(cfg config.Config, mergeRequest gitlab.MergeRequest) commentLinks {
+	projectURL, _, found := strings.Cut(mergeRequest.WebURL, "/-/merge_requests/")
+	if !found {
+		projectURL = ""
+	}
+
+	return commentLinks{
+		sonar: sonar.UILinks{
+			BaseURL:      cfg.SonarURL,
+			ProjectKey:   cfg.SonarProjectKey,
+			Organization: cfg.SonarOrganization,
+			Branch:       cfg.SonarBranch,
+			PullRequest:  cfg.SonarPullRequest,
+		},
+		projectURL: projectURL,
+		headSHA:    mergeRequest.DiffRefs.HeadSHA,
+	}
+}
+
+// blob links to a file line at the MR head commit.
+func (links commentLinks) blob(path string, line int) string {
+	path = normalizeRepoPath(path)
+	if links.projectURL == "" || links.headSHA == "" || path == "" {
+		return ""
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	link := fmt.Sprintf("%s/-/blob/%s/%s", links.projectURL, links.headSHA, strings.Join(segments, "/"))
+	if line > 0 {
+		link += fmt.Sprintf("#L%d", line)
+	}
+
+	return link
+}
+
+// commit links to the MR head commit.
+func (links commentLinks) commit() string {
+	if links.projectURL == "" || links.headSHA == "" {
+		return ""
+	}
+
+	return links.projectURL + "/-/commit/" + links.headSHA
+}
+
+// diffLineLink points to the issue line on the merge request "Changes" tab. GitLab
+// anchors diff lines by sha1(path)_oldLine_newLine.
+func diffLineLink(mergeRequestURL string, index diffLineIndex, issue sonar.Issue) string {
+	if mergeRequestURL == "" {
+		return ""
+	}
+
+	path := normalizeRepoPath(issue.FilePath)
+	info, ok := index.lines[path][issue.Line]
+	if !ok {
+		return ""
+	}
+
+	hash := sha1.Sum([]byte(index.pathMap[path].newPath))
+	return fmt.Sprintf(
+		"%s/diffs#%s_%d_%d",
+		strings.TrimRight(mergeRequestURL, "/"),
+		hex.EncodeToString(hash[:]),
+		info.oldPosition,
+		info.newLine,
+	)
+}
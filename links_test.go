@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestNewCommentLinksBlob(t *testing.T) {
+	t.Parallel()
+
+	links := newCommentLinks(
+		config.Config{SonarURL: "https://sonar.example.com", SonarProjectKey: "app"},
+		gitlab.MergeRequest{
+			WebURL:   "https://gitlab.example.com/group/app/-/merge_requests/42",
+			DiffRefs: gitlab.DiffRefs{HeadSHA: "abc123"},
+		},
+	)
+
+	if got := links.blob("./src/my file.go", 12); got != "https://gitlab.example.com/group/app/-/blob/abc123/src/my%20file.go#L12" {
+		t.Fatalf("unexpected blob link: %q", got)
+	}
+	if got := links.blob("go.mod", 0); got != "https://gitlab.example.com/group/app/-/blob/abc123/go.mod" {
+		t.Fatalf("unexpected file link: %q", got)
+	}
+	if got := links.sonar.Dashboard(); got != "https://sonar.example.com/dashboard?id=app" {
+		t.Fatalf("unexpected dashboard link: %q", got)
+	}
+
+	if got := newCommentLinks(config.Config{}, gitlab.MergeRequest{}).blob("main.go", 1); got != "" {
+		t.Fatalf("expected no blob link without MR web URL, got %q", got)
+	}
+}
+
+func TestDiffLineLink(t *testing.T) {
+	t.Parallel()
+
+	index := buildDiffLineIndex([]gitlab.MergeRequestChange{
+		{OldPath: "src/main.go", NewPath: "src/main.go", Diff: "@@ -4,1 +4,2 @@\n context\n+added"},
+	})
+
+	link := diffLineLink("https://gitlab.example.com/group/app/-/merge_requests/42/", index, sonar.Issue{FilePath: "src/main.go", Line: 5})
+	if link != "https://gitlab.example.com/group/app/-/merge_requests/42/diffs#6754859f1b1fa7b9acb69cb0c297fd6cabae7803_5_5" {
+		t.Fatalf("unexpected diff line link: %q", link)
+	}
+
+	if link := diffLineLink("", index, sonar.Issue{FilePath: "src/main.go", Line: 5}); link != "" {
+		t.Fatalf("expected no link without merge request URL, got %q", link)
+	}
+}
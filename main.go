@@ -5,22 +5,28 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"sonar-gitlab-commenter/internal/config"
 	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
 	"sonar-gitlab-commenter/internal/sonar"
 )
 
 const commentMarker = "<!-- sonar-gitlab-commenter -->"
-const summaryHeading = "**SonarQube summary**"
+const issueKeysMarkerPrefix = "<!-- sonar-issues: "
 
 var summarySeverityOrder = []string{"BLOCKER", "CRITICAL", "MAJOR", "MINOR", "INFO"}
+var summaryImpactSeverityOrder = []string{"BLOCKER", "HIGH", "MEDIUM", "LOW", "INFO"}
 var diffHunkHeaderRegex = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+var issueKeysMarkerRegex = regexp.MustCompile(`<!-- sonar-issues: ([^ ]*) -->`)
 
 func main() {
 	if err := run(); err != nil {
@@ -48,30 +54,175 @@ func runWith(args []string, getenv func(string) string, stdout io.Writer) error
 		return err
 	}
 
+	catalog, _ := i18n.Lookup(cfg.Locale)
+	if cfg.Triage {
+		return runTriage(cfg, catalog, stdout)
+	}
+
+	templates, err := loadCommentTemplates(
+		catalog,
+		cfg.InlineTemplatePath,
+		cfg.SummaryTemplatePath,
+		cfg.OverflowTemplatePath,
+		cfg.ResolvedTemplatePath,
+	)
+	if err != nil {
+		return err
+	}
+	templates.fileRows = cfg.SummaryFileRows
+	userMap, err := loadUserMap(cfg.SonarUserMapPath)
+	if err != nil {
+		return err
+	}
+
 	gitlabClient := gitlab.NewClient(cfg.GitLabURL, cfg.GitLabToken, nil)
-	client := sonar.NewClient(cfg.SonarURL, cfg.SonarToken, nil)
+	client := sonar.NewClient(
+		cfg.SonarURL,
+		cfg.SonarToken,
+		nil,
+		sonar.WithOrganization(cfg.SonarOrganization),
+		sonar.WithAuthScheme(cfg.SonarAuthScheme),
+		sonar.WithBranch(cfg.SonarBranch),
+		sonar.WithPullRequest(cfg.SonarPullRequest),
+	)
+	targetClient := sonar.NewClient(
+		cfg.SonarURL,
+		cfg.SonarToken,
+		nil,
+		sonar.WithOrganization(cfg.SonarOrganization),
+		sonar.WithAuthScheme(cfg.SonarAuthScheme),
+		sonar.WithBranch(targetBranchScope(cfg)),
+	)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	readCtx, cancelRead := context.WithTimeout(context.Background(), cfg.FetchTimeout)
+	defer cancelRead()
 
-	mergeRequest, err := gitlabClient.GetMergeRequest(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID)
-	if err != nil {
-		if errors.Is(err, gitlab.ErrUnauthorized) {
-			return fmt.Errorf("failed to authenticate in GitLab API: %w", err)
-		}
+	timings := &phaseTimings{}
+	var (
+		mergeRequest  gitlab.MergeRequest
+		diffLineIndex diffLineIndex
+		serverVersion string
+		versionErr    error
+		issueFetch    issueFetchResult
+		qualityReport sonar.QualityReport
+		discussions   []gitlab.Discussion
+		notes         []gitlab.MergeRequestNote
+		targetIssues  []sonar.Issue
+		botUser       gitlab.User
+	)
 
-		return fmt.Errorf("failed to connect to GitLab API: %w", err)
+	// Read phase: every fetch below is independent except the diff-scoped issue
+	// search, which needs the MR changes and therefore runs in the same goroutine.
+	group, fetchCtx := newFetchGroup(readCtx)
+	fetchTarget := func(paths []string) {
+		group.Go(func() error {
+			return timings.measure("sonar: target branch issues", func() error {
+				var err error
+				targetIssues, err = fetchTargetIssues(fetchCtx, targetClient, cfg, paths)
+				return wrapSonarError(err, "failed to retrieve SonarQube issues of the target branch")
+			})
+		})
 	}
-
-	mergeRequestChanges, err := gitlabClient.ListMergeRequestChanges(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID)
-	if err != nil {
-		if errors.Is(err, gitlab.ErrUnauthorized) {
-			return fmt.Errorf("failed to authenticate in GitLab API: %w", err)
+	group.Go(func() error {
+		return timings.measure("gitlab: merge request", func() error {
+			var err error
+			mergeRequest, err = gitlabClient.GetMergeRequest(fetchCtx, cfg.GitLabProjectID, cfg.GitLabMRIID)
+			return wrapGitLabError(err, "failed to connect to GitLab API")
+		})
+	})
+	group.Go(func() error {
+		err := timings.measure("gitlab: merge request changes", func() error {
+			changes, err := gitlabClient.ListMergeRequestChanges(fetchCtx, cfg.GitLabProjectID, cfg.GitLabMRIID)
+			if err != nil {
+				return wrapGitLabError(err, "failed to retrieve merge request diff from GitLab API")
+			}
+			diffLineIndex = buildDiffLineIndex(changes)
+			return nil
+		})
+		if err != nil || cfg.IssueScope != config.IssueScopeDiff {
+			return err
+		}
+		if cfg.CompareTargetBranch {
+			fetchTarget(targetPaths(diffLineIndex))
 		}
 
-		return fmt.Errorf("failed to retrieve merge request diff from GitLab API: %w", err)
+		return timings.measure("sonar: issues", func() error {
+			var err error
+			issueFetch, err = fetchSonarIssues(fetchCtx, client, cfg, diffLineIndex.paths())
+			return wrapSonarError(err, "failed to retrieve SonarQube issues")
+		})
+	})
+	group.Go(func() error {
+		return timings.measure("sonar: authentication and version", func() error {
+			if err := client.ValidateAuthentication(fetchCtx); err != nil {
+				return wrapSonarError(err, "failed to connect to SonarQube API")
+			}
+
+			// The version only selects the taxonomy; without it the run falls back
+			// to the legacy one.
+			serverVersion, versionErr = client.FetchServerVersion(fetchCtx)
+			return nil
+		})
+	})
+	if cfg.IssueScope != config.IssueScopeDiff {
+		group.Go(func() error {
+			return timings.measure("sonar: issues", func() error {
+				var err error
+				issueFetch, err = fetchSonarIssues(fetchCtx, client, cfg, nil)
+				return wrapSonarError(err, "failed to retrieve SonarQube issues")
+			})
+		})
+		if cfg.CompareTargetBranch {
+			fetchTarget(nil)
+		}
+	}
+	group.Go(func() error {
+		return timings.measure("sonar: quality report", func() error {
+			var err error
+			qualityReport, err = client.FetchQualityReport(fetchCtx, cfg.SonarProjectKey)
+			return wrapSonarError(err, "failed to retrieve SonarQube quality gate and coverage")
+		})
+	})
+	if !cfg.DryRun {
+		group.Go(func() error {
+			return timings.measure("gitlab: discussions", func() error {
+				var err error
+				discussions, err = gitlabClient.ListMergeRequestDiscussions(fetchCtx, cfg.GitLabProjectID, cfg.GitLabMRIID)
+				return wrapGitLabError(err, "failed to list merge request discussions")
+			})
+		})
+		group.Go(func() error {
+			return timings.measure("gitlab: notes", func() error {
+				var err error
+				notes, err = gitlabClient.ListMergeRequestNotes(fetchCtx, cfg.GitLabProjectID, cfg.GitLabMRIID)
+				return wrapGitLabError(err, "failed to list merge request notes")
+			})
+		})
+		if cfg.SyncResolutions || cfg.SonarCommands || cfg.MirrorReplies || cfg.ResolvePolicy != config.ResolvePolicyAlways {
+			group.Go(func() error {
+				return timings.measure("gitlab: current user", func() error {
+					var err error
+					botUser, err = gitlabClient.GetCurrentUser(fetchCtx)
+					return wrapGitLabError(err, "failed to identify the GitLab token user")
+				})
+			})
+		}
+	}
+	if err := group.Wait(); err != nil {
+		return fetchTimeoutError(err, cfg.FetchTimeout)
 	}
-	diffLineIndex := buildDiffLineIndex(mergeRequestChanges)
+	cancelRead()
+
+	// Updates get their own deadline, so that a long read phase does not eat into it.
+	ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	defer func() { cancel() }()
+	// renewUpdateDeadline restarts the update deadline after a bulk write, so that
+	// the updates after it still run when the write used up --write-timeout.
+	renewUpdateDeadline := func() {
+		cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), updateTimeout)
+	}
+
 	if cfg.Logs {
 		fileCount, lineCount := diffLineIndexStats(diffLineIndex)
 		if err := writeOutput(stdout, "Loaded MR diff lines: files=%d lines=%d\n", fileCount, lineCount); err != nil {
@@ -82,23 +233,26 @@ func runWith(args []string, getenv func(string) string, stdout io.Writer) error
 		}
 	}
 
-	if err := client.ValidateAuthentication(ctx); err != nil {
-		if errors.Is(err, sonar.ErrUnauthorized) {
-			return fmt.Errorf("failed to authenticate in SonarQube API: %w", err)
+	if versionErr != nil {
+		if err := writeOutput(stdout, "SonarQube server version unavailable, using the legacy taxonomy: %v\n", versionErr); err != nil {
+			return err
 		}
-
-		return fmt.Errorf("failed to connect to SonarQube API: %w", err)
 	}
-
-	issues, err := client.FetchProjectIssues(ctx, cfg.SonarProjectKey)
-	if err != nil {
-		if errors.Is(err, sonar.ErrUnauthorized) {
-			return fmt.Errorf("failed to authenticate in SonarQube API: %w", err)
+	taxonomy := sonar.TaxonomyForVersion(serverVersion)
+	if sonar.IsSonarCloudURL(cfg.SonarURL) {
+		// SonarCloud reports its own version scheme but always exposes impacts.
+		taxonomy = sonar.TaxonomyCleanCode
+	}
+	if cfg.Logs {
+		if err := writeOutput(stdout, "SonarQube server version: %s (taxonomy=%s)\n", serverVersion, taxonomy); err != nil {
+			return err
+		}
+		if err := logIssueFetch(stdout, issueFetch); err != nil {
+			return err
 		}
-
-		return fmt.Errorf("failed to retrieve SonarQube issues: %w", err)
 	}
 
+	issues := issueFetch.issues
 	if cfg.Logs {
 		if err := logFetchedSonarIssues(stdout, issues); err != nil {
 			return err
@@ -113,200 +267,761 @@ func runWith(args []string, getenv func(string) string, stdout io.Writer) error
 	}
 
 	issues = sonar.FilterIssuesBySeverity(issues, cfg.SeverityThreshold)
-	inlineIssues, projectLevelIssues := splitIssuesByLineBinding(issues)
+	if len(cfg.ImpactSeverityThresholds) > 0 {
+		if taxonomy == sonar.TaxonomyCleanCode {
+			issues = sonar.FilterIssuesByImpact(issues, cfg.ImpactSeverityThresholds)
+		} else if err := writeOutput(
+			stdout,
+			"Impact severity threshold ignored: SonarQube %s does not report software quality impacts\n",
+			serverVersion,
+		); err != nil {
+			return err
+		}
+	}
+	var baseline *baselineComparison
+	if cfg.CompareTargetBranch {
+		var preexisting []sonar.Issue
+		issues, preexisting = splitIssuesByBaseline(issues, targetIssues, diffLineIndex)
+		baseline = &baselineComparison{target: baselineTarget(cfg), preexisting: preexisting}
+		if cfg.Logs {
+			if err := writeOutput(
+				stdout,
+				"Compared with %s (%d issues): %d introduced by the MR, %d pre-existing in touched lines\n",
+				baseline.target,
+				len(targetIssues),
+				len(issues),
+				len(preexisting),
+			); err != nil {
+				return err
+			}
+		}
+	}
+	var (
+		commandCount  int
+		commandResult sonarCommandResult
+		transitions   []resolutionTransition
+		syncedCount   int
+		mirroredCount int
+	)
+	transitioned := map[string]bool{}
+	if (cfg.SonarCommands || cfg.SyncResolutions || cfg.MirrorReplies) && !cfg.DryRun {
+		acceptTransition := acceptTransitionFor(cfg, serverVersion)
+		openKeys := make(map[string]bool, len(issueFetch.issues))
+		for _, issue := range issueFetch.issues {
+			openKeys[issue.Key] = true
+		}
 
-	qualityReport, err := client.FetchQualityReport(ctx, cfg.SonarProjectKey)
-	if err != nil {
-		if errors.Is(err, sonar.ErrUnauthorized) {
-			return fmt.Errorf("failed to authenticate in SonarQube API: %w", err)
+		if cfg.MirrorReplies {
+			mirrors := planReplyMirrors(discussions, openKeys, botUser)
+			err = timings.measure("sonar: mirror replies", func() error {
+				var err error
+				mirroredCount, err = mirrorReplies(ctx, gitlabClient, client, cfg, catalog, mergeRequest.WebURL, mirrors)
+				return err
+			})
+			if err != nil {
+				return err
+			}
 		}
 
-		return fmt.Errorf("failed to retrieve SonarQube quality gate and coverage: %w", err)
+		if cfg.SonarCommands {
+			commands := planSonarCommands(discussions, botUser)
+			commandCount = len(commands)
+			err = timings.measure("sonar: apply commands", func() error {
+				writeCtx, cancelWrite := context.WithTimeout(context.Background(), cfg.WriteTimeout)
+				defer cancelWrite()
+
+				var err error
+				commandResult, err = applySonarCommands(writeCtx, gitlabClient, client, cfg, catalog, commands, openKeys, acceptTransition, userMap)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			renewUpdateDeadline()
+			for key := range commandResult.transitioned {
+				transitioned[key] = true
+				delete(openKeys, key)
+			}
+		}
+
+		if cfg.SyncResolutions {
+			transitions = planResolutionTransitions(cfg, discussions, openKeys, botUser, acceptTransition)
+			var synced map[string]bool
+			err = timings.measure("sonar: sync resolutions", func() error {
+				var err error
+				synced, err = syncResolutions(ctx, gitlabClient, client, cfg, catalog, transitions)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			syncedCount = len(synced)
+			for key := range synced {
+				transitioned[key] = true
+			}
+		}
+
+		isTransitioned := func(issue sonar.Issue) bool { return transitioned[issue.Key] }
+		issues = slices.DeleteFunc(issues, isTransitioned)
+		if baseline != nil {
+			baseline.preexisting = slices.DeleteFunc(baseline.preexisting, isTransitioned)
+		}
 	}
+	inlineIssues, projectLevelIssues := splitIssuesByLineBinding(issues)
 
-	resolvedDiscussionsCount := 0
+	var resolution discussionResolution
 	postedInlineCount := 0
+	skippedInlineCount := 0
+	var assignment authorAssignment
+	createdIssueCount := 0
+	var labels labelChanges
+	var inlineLimits inlineLimitResult
 	publishedCommentsCount := 0
-	summaryAction := "Skipped (dry-run)"
+	summaryLog := catalog.LogSummarySkipped
+	summaryNoteCount := 0
 
 	if cfg.DryRun {
-		if err := writeOutput(stdout, "Dry-run enabled: skipping GitLab discussion resolution and comment publishing\n"); err != nil {
+		if err := writeOutput(stdout, "%s", catalog.LogDryRun); err != nil {
 			return err
 		}
 	} else {
-		resolvedDiscussionsCount, err = resolvePreviousSonarDiscussions(
-			ctx,
-			gitlabClient,
-			cfg.GitLabProjectID,
-			cfg.GitLabMRIID,
-		)
+		// Pre-existing issues are still reported, so their discussions stay open
+		// as they did before the baseline comparison.
+		reportedIssues := issues
+		if baseline != nil {
+			reportedIssues = slices.Concat(issues, baseline.preexisting)
+		}
+		err = timings.measure("gitlab: resolve discussions", func() error {
+			var err error
+			resolution, err = resolvePreviousSonarDiscussions(
+				ctx,
+				gitlabClient,
+				cfg.GitLabProjectID,
+				cfg.GitLabMRIID,
+				discussions,
+				reportedIssues,
+				cfg.ResolvePolicy,
+				botUser,
+				resolvedReplyOptions{
+					templates:   templates,
+					links:       newCommentLinks(cfg, mergeRequest),
+					pipelineID:  cfg.PipelineID,
+					pipelineURL: cfg.PipelineURL,
+				},
+			)
+			return err
+		})
 		if err != nil {
 			return fmt.Errorf("failed to resolve previous SonarQube discussions: %w", err)
 		}
 
+		newInlineIssues := make([]sonar.Issue, 0, len(inlineIssues))
 		for _, issue := range inlineIssues {
-			normalizedPath := normalizeRepoPath(issue.FilePath)
-			pathInfo, hasPathInfo := diffLineIndex.pathMap[normalizedPath]
-
-			if !hasPathInfo {
-				projectLevelIssues = append(projectLevelIssues, issue)
-				if cfg.Logs {
-					if writeErr := writeOutput(
-						stdout,
-						"Skipped inline discussion for issue %q: path not found in diff mapping (path=%q, line=%d); added to summary\n",
-						issue.Key,
-						issue.FilePath,
-						issue.Line,
-					); writeErr != nil {
-						return writeErr
-					}
-				}
+			if !resolution.openIssueKeys[issue.Key] {
+				newInlineIssues = append(newInlineIssues, issue)
 				continue
 			}
-
-			// Get line info to determine old_line and new_line
-			lines, hasLines := diffLineIndex.lines[normalizedPath]
-			if !hasLines {
-				projectLevelIssues = append(projectLevelIssues, issue)
-				if cfg.Logs {
-					if writeErr := writeOutput(
-						stdout,
-						"Skipped inline discussion for issue %q: no line info found (path=%q, line=%d); added to summary\n",
-						issue.Key,
-						issue.FilePath,
-						issue.Line,
-					); writeErr != nil {
-						return writeErr
-					}
+			if cfg.Logs {
+				if err := writeOutput(stdout, "Skipped inline discussion for issue %q: already reported in an open discussion\n", issue.Key); err != nil {
+					return err
 				}
-				continue
 			}
+		}
 
-			info, hasInfo := lines[issue.Line]
-			if !hasInfo {
-				projectLevelIssues = append(projectLevelIssues, issue)
-				if cfg.Logs {
-					if writeErr := writeOutput(
-						stdout,
-						"Skipped inline discussion for issue %q: line not in diff (path=%q, line=%d); added to summary\n",
-						issue.Key,
-						issue.FilePath,
-						issue.Line,
-					); writeErr != nil {
-						return writeErr
-					}
-				}
+		posts, unpostable, err := planInlineDiscussions(stdout, cfg.Logs, newInlineIssues, diffLineIndex, cfg.InlineGrouping)
+		if err != nil {
+			return err
+		}
+		projectLevelIssues = append(projectLevelIssues, unpostable...)
+
+		inlineLimits = limitInlinePosts(posts, diffLineIndex.paths(), resolution.openPaths, cfg.MaxInlineComments, cfg.MaxCommentsPerFile)
+		posts = inlineLimits.kept
+		links := newCommentLinks(cfg, mergeRequest)
+		overflow := overflowIssues(inlineLimits.demoted(), diffLineIndex, mergeRequest.WebURL)
+		for index := range posts {
+			posts[index].body, err = formatInlineDiscussion(templates, links, posts[index].issues)
+			if err != nil {
+				return err
+			}
+		}
+
+		var postResults []error
+		err = timings.measure("gitlab: post inline comments", func() error {
+			writeCtx, cancelWrite := context.WithTimeout(context.Background(), cfg.WriteTimeout)
+			defer cancelWrite()
+
+			var err error
+			postResults, err = postInlineComments(writeCtx, gitlabClient, cfg, mergeRequest.DiffRefs, posts)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		var postedIssues []sonar.Issue
+		for index, postErr := range postResults {
+			post := posts[index]
+			if postErr == nil {
+				postedInlineCount++
+				postedIssues = append(postedIssues, post.issues...)
 				continue
 			}
 
-			if err := gitlabClient.CreateInlineDiscussion(
-				ctx,
-				cfg.GitLabProjectID,
-				cfg.GitLabMRIID,
-				formatInlineIssueComment(issue),
-				pathInfo.oldPath,
-				pathInfo.newPath,
-				info.oldLine,
-				info.newLine,
-				mergeRequest.DiffRefs,
-			); err != nil {
-				if errors.Is(err, gitlab.ErrInvalidInlinePosition) {
-					projectLevelIssues = append(projectLevelIssues, issue)
-					if cfg.Logs {
-						lineTypeStr := "added"
-						if info.lineType == lineTypeContext {
-							lineTypeStr = "context"
-						}
-						if writeErr := writeOutput(
-							stdout,
-							"Skipped inline discussion for issue %q: invalid diff line mapping (old_path=%q, new_path=%q, old_line=%d, new_line=%d, type=%s, base_sha=%s, start_sha=%s, head_sha=%s); GitLab error: %v; added to summary\n",
-							issue.Key,
-							pathInfo.oldPath,
-							pathInfo.newPath,
-							info.oldLine,
-							info.newLine,
-							lineTypeStr,
-							mergeRequest.DiffRefs.BaseSHA[:8],
-							mergeRequest.DiffRefs.StartSHA[:8],
-							mergeRequest.DiffRefs.HeadSHA[:8],
-							err,
-						); writeErr != nil {
-							return writeErr
-						}
-					}
-					continue
+			projectLevelIssues = append(projectLevelIssues, post.issues...)
+			if errors.Is(postErr, context.DeadlineExceeded) {
+				skippedInlineCount++
+				continue
+			}
+			if cfg.Logs {
+				lineTypeStr := "added"
+				if post.line.lineType == lineTypeContext {
+					lineTypeStr = "context"
+				}
+				if writeErr := writeOutput(
+					stdout,
+					"Skipped inline discussion for issues %q: invalid diff line mapping (old_path=%q, new_path=%q, old_line=%d, new_line=%d, type=%s, base_sha=%s, start_sha=%s, head_sha=%s); GitLab error: %v; added to summary\n",
+					strings.Join(post.issueKeys(), ","),
+					post.path.oldPath,
+					post.path.newPath,
+					post.line.oldLine,
+					post.line.newLine,
+					lineTypeStr,
+					shortSHA(mergeRequest.DiffRefs.BaseSHA),
+					shortSHA(mergeRequest.DiffRefs.StartSHA),
+					shortSHA(mergeRequest.DiffRefs.HeadSHA),
+					postErr,
+				); writeErr != nil {
+					return writeErr
 				}
+			}
+		}
 
-				return fmt.Errorf("failed to post inline discussion for SonarQube issue %q: %w", issue.Key, err)
+		publishedCommentsCount = postedInlineCount
+
+		renewUpdateDeadline()
+
+		if cfg.AssignAuthor {
+			err = timings.measure("sonar: assign issues", func() error {
+				var err error
+				assignment, err = assignIssuesToAuthor(ctx, gitlabClient, client, userMap, mergeRequest.Author, postedIssues)
+				return err
+			})
+			if err != nil {
+				return err
 			}
+		}
 
-			postedInlineCount++
+		previous := previousSummarySnapshot(notes)
+		tracked := make(map[string]int)
+		if previous != nil {
+			maps.Copy(tracked, previous.Tracked)
 		}
+		// A failure to create GitLab issues is returned after the summary is
+		// updated, so that the issues created so far are recorded.
+		var trackErr error
+		if cfg.CreateIssues != "" {
+			candidates := slices.Clone(projectLevelIssues)
+			for _, entry := range overflow {
+				candidates = append(candidates, entry.issue)
+			}
+			trackErr = timings.measure("gitlab: create issues", func() error {
+				groups := planTrackedIssues(cfg, candidates, tracked)
+				if len(groups) == 0 {
+					return nil
+				}
+				found, err := findTrackedIssues(ctx, gitlabClient, cfg)
+				if err != nil {
+					return err
+				}
+				for key, iid := range found {
+					if tracked[key] == 0 {
+						tracked[key] = iid
+					}
+				}
 
-		publishedCommentsCount = postedInlineCount
+				groups = planTrackedIssues(cfg, candidates, tracked)
+				created, err := createTrackedIssues(ctx, gitlabClient, cfg, catalog, links, mergeRequest.WebURL, groups, time.Now())
+				maps.Copy(tracked, created)
+				createdIssueCount = len(created)
+				return err
+			})
+		}
 
-		summaryBody := formatMergeRequestSummaryComment(qualityReport, issues, projectLevelIssues)
-		summaryUpdated, err := upsertSummaryNote(
-			ctx,
-			gitlabClient,
-			cfg.GitLabProjectID,
-			cfg.GitLabMRIID,
-			summaryBody,
+		summaryBody, err := formatMergeRequestSummaryComment(
+			templates,
+			links,
+			qualityReport,
+			issues,
+			projectLevelIssues,
+			overflow,
+			taxonomy,
+			previous,
+			baseline,
+			tracked,
 		)
+		if err != nil {
+			return err
+		}
+		summaryNotes := splitSummaryNote(catalog, summaryBody, templates.noteLimit)
+		summaryNoteCount = len(summaryNotes)
+		var summaryResult summaryNoteResult
+		err = timings.measure("gitlab: upsert summary", func() error {
+			var err error
+			summaryResult, err = upsertSummaryNote(
+				ctx,
+				gitlabClient,
+				cfg.GitLabProjectID,
+				cfg.GitLabMRIID,
+				notes,
+				summaryNotes,
+			)
+			return err
+		})
 		if err != nil {
 			return fmt.Errorf("failed to post SonarQube summary note: %w", err)
 		}
-		summaryAction = "Posted"
-		if summaryUpdated {
-			summaryAction = "Updated"
-		} else {
-			publishedCommentsCount++
+		summaryLog = catalog.LogSummaryPosted
+		if summaryResult.updated {
+			summaryLog = catalog.LogSummaryUpdated
+		}
+		publishedCommentsCount += summaryResult.created
+		if cfg.Logs && summaryResult.deleted > 0 {
+			if err := writeOutput(stdout, "Deleted %d stale summary continuation notes\n", summaryResult.deleted); err != nil {
+				return err
+			}
+		}
+
+		if len(cfg.LabelRules) > 0 {
+			labels = planLabelChanges(cfg.LabelRules, mergeRequest.Labels, qualityReport, issues)
+			if !labels.empty() {
+				err = timings.measure("gitlab: update labels", func() error {
+					return gitlabClient.UpdateMergeRequestLabels(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID, labels.add, labels.remove)
+				})
+				if err != nil {
+					return wrapGitLabError(err, "failed to update merge request labels")
+				}
+			}
+		}
+		if trackErr != nil {
+			return trackErr
+		}
+	}
+
+	statusLog := ""
+	if cfg.CommitStatus {
+		status := newCommitStatus(cfg, catalog, newCommentLinks(cfg, mergeRequest), qualityReport, issues)
+		err = timings.measure("gitlab: commit status", func() error {
+			var err error
+			statusLog, err = publishCommitStatus(ctx, gitlabClient, cfg, catalog, mergeRequest.DiffRefs.HeadSHA, status)
+			return err
+		})
+		if err != nil {
+			return err
 		}
 	}
 
-	if err := writeOutput(stdout, "Action log: found %d issues, published %d comments\n", len(issues), publishedCommentsCount); err != nil {
+	statusCheckLog := ""
+	if cfg.StatusCheckName != "" {
+		err = timings.measure("gitlab: external status check", func() error {
+			var err error
+			statusCheckLog, err = reportStatusCheck(ctx, gitlabClient, cfg, catalog, mergeRequest.DiffRefs.HeadSHA, qualityReport)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := writeOutput(stdout, catalog.LogActionSummary, len(issues), publishedCommentsCount); err != nil {
 		return err
 	}
 	if err := writeOutput(
 		stdout,
-		"Resolved %d previous SonarQube discussions in merge request %d\n",
-		resolvedDiscussionsCount,
+		catalog.LogResolved,
+		resolution.resolved,
 		cfg.GitLabMRIID,
 	); err != nil {
 		return err
 	}
+	if cfg.ResolvePolicy == config.ResolvePolicySkipDiscussed && !cfg.DryRun {
+		if err := writeOutput(stdout, catalog.LogKeptOpen, resolution.keptOpen); err != nil {
+			return err
+		}
+	}
+	if cfg.ResolvePolicy == config.ResolvePolicyReopen && !cfg.DryRun {
+		if err := writeOutput(stdout, catalog.LogReopened, resolution.reopened); err != nil {
+			return err
+		}
+	}
+	if cfg.MirrorReplies && !cfg.DryRun {
+		if err := writeOutput(stdout, catalog.LogMirrored, mirroredCount); err != nil {
+			return err
+		}
+	}
+	if len(assignment.keys) > 0 {
+		if err := writeOutput(
+			stdout,
+			catalog.LogAssigned,
+			len(assignment.keys),
+			assignment.login,
+			mergeRequest.Author.Username,
+			strings.Join(assignment.keys, ", "),
+		); err != nil {
+			return err
+		}
+	}
+	if assignment.unmapped {
+		if err := writeOutput(stdout, catalog.LogUnmappedAuthor, mergeRequest.Author.Username); err != nil {
+			return err
+		}
+	}
+	if cfg.CreateIssues != "" && !cfg.DryRun {
+		if err := writeOutput(stdout, catalog.LogCreatedIssues, createdIssueCount); err != nil {
+			return err
+		}
+	}
+	if len(cfg.LabelRules) > 0 && !cfg.DryRun {
+		labelLog := fmt.Sprintf(catalog.LogLabelsUpdated, formatLabelList(labels.add), formatLabelList(labels.remove))
+		if labels.empty() {
+			labelLog = catalog.LogLabelsUnchanged
+		}
+		if err := writeOutput(stdout, "%s", labelLog); err != nil {
+			return err
+		}
+	}
+	for _, line := range []string{statusLog, statusCheckLog} {
+		if line == "" {
+			continue
+		}
+		if err := writeOutput(stdout, "%s", line); err != nil {
+			return err
+		}
+	}
+	if cfg.SonarCommands && !cfg.DryRun {
+		if err := writeOutput(stdout, catalog.LogCommands, commandResult.applied, commandCount); err != nil {
+			return err
+		}
+		if commandResult.skipped > 0 {
+			if err := writeOutput(stdout, catalog.LogCommandsSkipped, commandResult.skipped, cfg.WriteTimeout); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.SyncResolutions && !cfg.DryRun {
+		if err := writeOutput(stdout, catalog.LogTransitioned, syncedCount, len(transitions)); err != nil {
+			return err
+		}
+	}
 	if err := writeOutput(
 		stdout,
-		"Posted %d inline SonarQube discussions to merge request %d\n",
+		catalog.LogPostedInline,
 		postedInlineCount,
 		cfg.GitLabMRIID,
 	); err != nil {
 		return err
 	}
-	if err := writeOutput(
-		stdout,
-		"%s summary SonarQube note in merge request %d\n",
-		summaryAction,
-		cfg.GitLabMRIID,
-	); err != nil {
+	if skippedInlineCount > 0 {
+		if err := writeOutput(stdout, catalog.LogInlineSkipped, skippedInlineCount, cfg.WriteTimeout); err != nil {
+			return err
+		}
+	}
+	if demoted := len(inlineLimits.overFile) + len(inlineLimits.overTotal); demoted > 0 {
+		if err := writeOutput(
+			stdout,
+			catalog.LogDemoted,
+			demoted,
+			len(inlineLimits.overFile),
+			cfg.MaxCommentsPerFile,
+			len(inlineLimits.overTotal),
+			cfg.MaxInlineComments,
+		); err != nil {
+			return err
+		}
+	}
+	if err := writeOutput(stdout, summaryLog, cfg.GitLabMRIID); err != nil {
 		return err
 	}
+	if summaryNoteCount > 1 {
+		if err := writeOutput(stdout, catalog.LogSummarySplit, summaryNoteCount); err != nil {
+			return err
+		}
+	}
 	if err := writeOutput(
 		stdout,
-		"Quality gate: %s, coverage: %.2f%%, new code coverage: %.2f%%\n",
+		catalog.LogQualityReport,
 		qualityReport.QualityGateStatus,
 		qualityReport.OverallCoverage,
 		qualityReport.NewCodeCoverage,
 	); err != nil {
 		return err
 	}
-	if err := writeOutput(stdout, "Resolved GitLab merge request: project_id=%d, mr_iid=%d\n", cfg.GitLabProjectID, cfg.GitLabMRIID); err != nil {
+	if err := writeOutput(stdout, catalog.LogMergeRequestTarget, cfg.GitLabProjectID, cfg.GitLabMRIID); err != nil {
+		return err
+	}
+	if cfg.Logs {
+		if err := timings.write(stdout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// postInlineComments creates one inline comment per planned post and returns the
+// per-post errors in input order. ErrInvalidInlinePosition and the deadline of ctx
+// are reported per post, so posts cut off by --write-timeout are skipped; any
+// other failure aborts the whole batch. With --draft-notes the comments are
+// created as drafts and published as a single review.
+func postInlineComments(
+	ctx context.Context,
+	gitlabClient *gitlab.Client,
+	cfg config.Config,
+	diffRefs gitlab.DiffRefs,
+	posts []inlinePost,
+) ([]error, error) {
+	draftIDs := make([]int, len(posts))
+	results, fatalIndex := runRateLimited(
+		ctx,
+		len(posts),
+		inlinePostWorkers,
+		inlinePostInterval,
+		func(ctx context.Context, index int) error {
+			post := posts[index]
+			if !cfg.DraftNotes {
+				return gitlabClient.CreateInlineDiscussion(
+					ctx,
+					cfg.GitLabProjectID,
+					cfg.GitLabMRIID,
+					post.body,
+					post.path.oldPath,
+					post.path.newPath,
+					post.line.oldLine,
+					post.line.newLine,
+					diffRefs,
+				)
+			}
+
+			draftID, err := gitlabClient.CreateDraftNote(
+				ctx,
+				cfg.GitLabProjectID,
+				cfg.GitLabMRIID,
+				post.body,
+				post.path.oldPath,
+				post.path.newPath,
+				post.line.oldLine,
+				post.line.newLine,
+				diffRefs,
+			)
+			draftIDs[index] = draftID
+			return err
+		},
+		func(err error) bool {
+			return !errors.Is(err, gitlab.ErrInvalidInlinePosition) && !errors.Is(err, context.DeadlineExceeded)
+		},
+	)
+
+	if fatalIndex >= 0 {
+		postErr := fmt.Errorf(
+			"failed to post inline discussion for SonarQube issues %q: %w",
+			strings.Join(posts[fatalIndex].issueKeys(), ","),
+			results[fatalIndex],
+		)
+		if cfg.DraftNotes {
+			return nil, errors.Join(postErr, discardDraftNotes(ctx, gitlabClient, cfg, draftIDs))
+		}
+		return nil, postErr
+	}
+
+	if !cfg.DraftNotes || !slices.ContainsFunc(draftIDs, func(id int) bool { return id > 0 }) {
+		return results, nil
+	}
+
+	// Drafts created before the deadline are still published as one review.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), updateTimeout)
+	defer cancel()
+
+	if err := publishDraftNotes(ctx, gitlabClient, cfg, draftIDs); err != nil {
+		publishErr := fmt.Errorf("failed to publish inline draft notes: %w", err)
+		return nil, errors.Join(publishErr, discardDraftNotes(ctx, gitlabClient, cfg, draftIDs))
+	}
+
+	return results, nil
+}
+
+// publishDraftNotes publishes the drafts created by this run as one review. Bulk
+// publish would also post any other pending draft of the token user, so in that
+// case each draft is published on its own.
+func publishDraftNotes(ctx context.Context, gitlabClient *gitlab.Client, cfg config.Config, draftIDs []int) error {
+	pending, err := gitlabClient.ListDraftNotes(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID)
+	if err != nil {
 		return err
 	}
+	foreign := slices.ContainsFunc(pending, func(draft gitlab.DraftNote) bool {
+		return !slices.Contains(draftIDs, draft.ID)
+	})
+	if !foreign {
+		return gitlabClient.BulkPublishDraftNotes(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID)
+	}
+
+	for _, draftID := range draftIDs {
+		if draftID <= 0 {
+			continue
+		}
+		if err := gitlabClient.PublishDraftNote(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID, draftID); err != nil {
+			return fmt.Errorf("failed to publish draft note %d: %w", draftID, err)
+		}
+	}
 
 	return nil
 }
 
+// discardDraftNotes deletes drafts created by this run. Drafts that were already
+// published before a partial failure are no longer found and are skipped.
+func discardDraftNotes(ctx context.Context, gitlabClient *gitlab.Client, cfg config.Config, draftIDs []int) error {
+	var errs []error
+	for _, draftID := range draftIDs {
+		if draftID <= 0 {
+			continue
+		}
+
+		err := gitlabClient.DeleteDraftNote(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID, draftID)
+		if err != nil && !errors.Is(err, gitlab.ErrNotFound) {
+			errs = append(errs, fmt.Errorf("failed to delete draft note %d: %w", draftID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func wrapGitLabError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gitlab.ErrUnauthorized) {
+		return fmt.Errorf("failed to authenticate in GitLab API: %w", err)
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+func wrapSonarError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sonar.ErrUnauthorized) {
+		return fmt.Errorf("failed to authenticate in SonarQube API: %w", err)
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// inlinePost is one inline discussion: a group of issues anchored at the diff
+// position of the first issue line.
+type inlinePost struct {
+	issues []sonar.Issue
+	path   pathInfo
+	line   lineInfo
+	body   string
+}
+
+func (post inlinePost) issueKeys() []string {
+	keys := make([]string, 0, len(post.issues))
+	for _, issue := range post.issues {
+		keys = append(keys, issue.Key)
+	}
+
+	return keys
+}
+
+// planInlineDiscussions maps inline issues to diff positions and merges them into
+// discussions according to the grouping strategy. Issues that cannot be anchored
+// to the diff are returned separately so they end up in the summary.
+func planInlineDiscussions(
+	stdout io.Writer,
+	logs bool,
+	issues []sonar.Issue,
+	index diffLineIndex,
+	grouping string,
+) ([]inlinePost, []sonar.Issue, error) {
+	posts := make([]inlinePost, 0, len(issues))
+	groupIndexes := make(map[string]int)
+	var unpostable []sonar.Issue
+
+	for _, issue := range issues {
+		normalizedPath := normalizeRepoPath(issue.FilePath)
+		reason := ""
+
+		pathInfo, hasPathInfo := index.pathMap[normalizedPath]
+		lines, hasLines := index.lines[normalizedPath]
+		info, hasInfo := lines[issue.Line]
+		switch {
+		case !hasPathInfo:
+			reason = "path not found in diff mapping"
+		case !hasLines:
+			reason = "no line info found"
+		case !hasInfo:
+			reason = "line not in diff"
+		}
+
+		if reason == "" {
+			groupKey := inlineGroupKey(grouping, normalizedPath, issue)
+			position, grouped := groupIndexes[groupKey]
+			if groupKey == "" || !grouped {
+				if groupKey != "" {
+					groupIndexes[groupKey] = len(posts)
+				}
+				posts = append(posts, inlinePost{issues: []sonar.Issue{issue}, path: pathInfo, line: info})
+				continue
+			}
+
+			post := &posts[position]
+			if issue.Line < post.issues[0].Line {
+				post.line = info
+			}
+			post.issues = append(post.issues, issue)
+			slices.SortStableFunc(post.issues, func(a, b sonar.Issue) int { return a.Line - b.Line })
+			continue
+		}
+
+		unpostable = append(unpostable, issue)
+		if logs {
+			if err := writeOutput(
+				stdout,
+				"Skipped inline discussion for issue %q: %s (path=%q, line=%d); added to summary\n",
+				issue.Key,
+				reason,
+				issue.FilePath,
+				issue.Line,
+			); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return posts, unpostable, nil
+}
+
+// inlineGroupKey returns the key issues are merged on, or "" when every issue gets
+// its own discussion.
+func inlineGroupKey(grouping string, path string, issue sonar.Issue) string {
+	switch grouping {
+	case config.InlineGroupingLine:
+		return path + ":" + strconv.Itoa(issue.Line)
+	case config.InlineGroupingRuleFile:
+		return path + ":" + issue.Rule
+	default:
+		return ""
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+
+	return sha
+}
+
 func writeOutput(stdout io.Writer, format string, args ...any) error {
 	if _, err := fmt.Fprintf(stdout, format, args...); err != nil {
 		return fmt.Errorf("failed to write CLI output: %w", err)
@@ -323,11 +1038,12 @@ func logFetchedSonarIssues(stdout io.Writer, issues []sonar.Issue) error {
 	for index, issue := range issues {
 		if err := writeOutput(
 			stdout,
-			"Sonar issue #%d: key=%q severity=%q type=%q rule=%q file=%q line=%d message=%q\n",
+			"Sonar issue #%d: key=%q severity=%q type=%q impacts=%q rule=%q file=%q line=%d message=%q\n",
 			index+1,
 			compactLogValue(issue.Key),
 			compactLogValue(issue.Severity),
 			compactLogValue(issue.Type),
+			formatImpacts(issue.Impacts),
 			compactLogValue(issue.Rule),
 			compactLogValue(issue.FilePath),
 			issue.Line,
@@ -340,6 +1056,85 @@ func logFetchedSonarIssues(stdout io.Writer, issues []sonar.Issue) error {
 	return nil
 }
 
+type issueFetchResult struct {
+	issues      []sonar.Issue
+	diffScoped  bool
+	scopedFiles int
+	elapsed     time.Duration
+	// The fields below estimate the full-project fetch and are only set for
+	// diff-scoped fetches with --logs.
+	projectTotal       int
+	fullFetchRequests  int
+	estimatedFullFetch time.Duration
+}
+
+// fetchSonarIssues loads issues for the whole project or, with the diff issue scope,
+// only for the files changed in the merge request.
+func fetchSonarIssues(
+	ctx context.Context,
+	client *sonar.Client,
+	cfg config.Config,
+	diffPaths []string,
+) (issueFetchResult, error) {
+	if cfg.IssueScope != config.IssueScopeDiff {
+		issues, err := client.FetchProjectIssues(ctx, cfg.SonarProjectKey)
+		return issueFetchResult{issues: issues}, err
+	}
+
+	paths := diffPaths
+	started := time.Now()
+	issues, err := client.FetchFileIssues(ctx, cfg.SonarProjectKey, paths)
+	if err != nil {
+		return issueFetchResult{}, err
+	}
+	result := issueFetchResult{
+		issues:      issues,
+		diffScoped:  true,
+		scopedFiles: len(paths),
+		elapsed:     time.Since(started),
+	}
+	if !cfg.Logs {
+		return result, nil
+	}
+
+	// Estimate the full-project fetch from the latency of a single search request.
+	countStarted := time.Now()
+	projectTotal, err := client.CountProjectIssues(ctx, cfg.SonarProjectKey)
+	if err != nil {
+		return issueFetchResult{}, err
+	}
+	requestLatency := time.Since(countStarted)
+	result.projectTotal = projectTotal
+	result.fullFetchRequests = sonar.ProjectIssuesPageCount(projectTotal)
+	result.estimatedFullFetch = requestLatency * time.Duration(result.fullFetchRequests)
+
+	return result, nil
+}
+
+func logIssueFetch(stdout io.Writer, result issueFetchResult) error {
+	if !result.diffScoped {
+		return nil
+	}
+
+	if err := writeOutput(
+		stdout,
+		"Fetched SonarQube issues for %d MR files in %s\n",
+		result.scopedFiles,
+		result.elapsed.Round(time.Millisecond),
+	); err != nil {
+		return err
+	}
+
+	return writeOutput(
+		stdout,
+		"Full-project fetch estimate: issues=%d requests=%d duration=%s; estimated time saved ~%s\n",
+		result.projectTotal,
+		result.fullFetchRequests,
+		result.estimatedFullFetch.Round(time.Millisecond),
+		max(result.estimatedFullFetch-result.elapsed, 0).Round(time.Millisecond),
+	)
+}
+
 func compactLogValue(value string) string {
 	return strings.Join(strings.Fields(strings.TrimSpace(value)), " ")
 }
@@ -353,6 +1148,8 @@ type lineInfo struct {
 	lineType lineType
 	oldLine  int // For context and deleted lines
 	newLine  int // For context and added lines
+	// oldPosition is the old-file line counter at this line, used in GitLab line codes.
+	oldPosition int
 }
 
 type lineType int
@@ -400,6 +1197,17 @@ func buildDiffLineIndex(changes []gitlab.MergeRequestChange) diffLineIndex {
 	return index
 }
 
+// paths returns the normalized new paths of the diff in sorted order.
+func (index diffLineIndex) paths() []string {
+	paths := make([]string, 0, len(index.lines))
+	for path := range index.lines {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
 func diffLineIndexStats(index diffLineIndex) (int, int) {
 	fileCount := len(index.lines)
 	lineCount := 0
@@ -496,8 +1304,9 @@ func extractDiffLines(diff string) map[int]lineInfo {
 				continue
 			}
 			result[currentNewLine] = lineInfo{
-				lineType: lineTypeAdded,
-				newLine:  currentNewLine,
+				lineType:    lineTypeAdded,
+				newLine:     currentNewLine,
+				oldPosition: currentOldLine,
 			}
 			currentNewLine++
 		case strings.HasPrefix(line, "-"):
@@ -509,9 +1318,10 @@ func extractDiffLines(diff string) map[int]lineInfo {
 		case strings.HasPrefix(line, " "):
 			// Context line - exists in both old and new
 			result[currentNewLine] = lineInfo{
-				lineType: lineTypeContext,
-				oldLine:  currentOldLine,
-				newLine:  currentNewLine,
+				lineType:    lineTypeContext,
+				oldLine:     currentOldLine,
+				newLine:     currentNewLine,
+				oldPosition: currentOldLine,
 			}
 			currentOldLine++
 			currentNewLine++
@@ -542,33 +1352,147 @@ func parseDiffHunkHeader(line string) (oldStart, newStart int, ok bool) {
 	return oldValue, newValue, true
 }
 
+// resolvedReplyOptions configures the reply posted to discussions whose issues
+// are gone.
+type resolvedReplyOptions struct {
+	templates   commentTemplates
+	links       commentLinks
+	pipelineID  string
+	pipelineURL string
+}
+
+// discussionResolution reports how resolvePreviousSonarDiscussions changed tool
+// discussions.
+type discussionResolution struct {
+	resolved int
+	// keptOpen counts fixed discussions left open because of human replies.
+	keptOpen int
+	reopened int
+	// openIssueKeys lists the keys of discussions that stay or become open.
+	openIssueKeys map[string]bool
+	// openPaths has the file of every tool discussion left open, so that inline
+	// limits also count threads posted by earlier runs.
+	openPaths []string
+}
+
+// resolvePreviousSonarDiscussions replies to and resolves tool discussions whose
+// issues are all gone, following the resolve policy for discussions people took
+// part in. A discussion stays open while any issue listed in its hidden key marker
+// is still reported, and those keys are returned so the issues are not posted
+// again. Discussions created before key markers existed are always resolved.
 func resolvePreviousSonarDiscussions(
 	ctx context.Context,
 	gitlabClient *gitlab.Client,
 	projectID int,
 	mrIID int,
-) (int, error) {
-	discussions, err := gitlabClient.ListMergeRequestDiscussions(ctx, projectID, mrIID)
-	if err != nil {
-		return 0, err
+	discussions []gitlab.Discussion,
+	issues []sonar.Issue,
+	policy string,
+	bot gitlab.User,
+	reply resolvedReplyOptions,
+) (discussionResolution, error) {
+	currentKeys := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		currentKeys[issue.Key] = true
 	}
 
-	resolvedCount := 0
+	result := discussionResolution{openIssueKeys: make(map[string]bool)}
 	for _, discussion := range discussions {
-		if discussion.Resolved || !discussion.Resolvable {
+		if !discussion.Resolvable || !discussionContainsMarker(discussion) {
+			continue
+		}
+
+		keys := discussionIssueKeys(discussion)
+		reported := slices.ContainsFunc(keys, func(key string) bool { return currentKeys[key] })
+		if discussion.Resolved {
+			resolver := discussionResolver(discussion)
+			if policy != config.ResolvePolicyReopen || !reported || resolver.ID == 0 || resolver.ID == bot.ID {
+				continue
+			}
+			if err := gitlabClient.ReopenMergeRequestDiscussion(ctx, projectID, mrIID, discussion.ID); err != nil {
+				return result, err
+			}
+			result.reopened++
+		}
+		if reported {
+			if hasResolvedReply(discussion) {
+				body := reportedAgainMarker + "\n" + fmt.Sprintf(reply.templates.catalog.ReportedAgain, shortSHA(reply.links.headSHA))
+				if err := gitlabClient.CreateDiscussionNote(ctx, projectID, mrIID, discussion.ID, body); err != nil {
+					return result, err
+				}
+			}
+			for _, key := range keys {
+				result.openIssueKeys[key] = true
+			}
+			result.openPaths = append(result.openPaths, discussionPath(discussion))
 			continue
 		}
-		if !discussionContainsMarker(discussion) {
+
+		keepOpen := policy == config.ResolvePolicySkipDiscussed && discussionHasHumanReplies(discussion, bot)
+		if !hasResolvedReply(discussion) {
+			body, err := formatResolvedReply(reply, keys, keepOpen)
+			if err != nil {
+				return result, err
+			}
+			if err := gitlabClient.CreateDiscussionNote(ctx, projectID, mrIID, discussion.ID, body); err != nil {
+				return result, err
+			}
+		}
+		if keepOpen {
+			result.keptOpen++
+			result.openPaths = append(result.openPaths, discussionPath(discussion))
 			continue
 		}
 
 		if err := gitlabClient.ResolveMergeRequestDiscussion(ctx, projectID, mrIID, discussion.ID); err != nil {
-			return resolvedCount, err
+			return result, err
+		}
+		result.resolved++
+	}
+
+	return result, nil
+}
+
+// hasResolvedReply reports whether the discussion got a resolved reply since its
+// issues were last reported again.
+func hasResolvedReply(discussion gitlab.Discussion) bool {
+	for index := len(discussion.Notes) - 1; index >= 0; index-- {
+		switch body := discussion.Notes[index].Body; {
+		case strings.Contains(body, reportedAgainMarker):
+			return false
+		case strings.Contains(body, resolvedMarker):
+			return true
 		}
-		resolvedCount++
 	}
 
-	return resolvedCount, nil
+	return false
+}
+
+// discussionHasHumanReplies reports whether a discussion has notes that neither
+// the bot nor GitLab itself wrote.
+func discussionHasHumanReplies(discussion gitlab.Discussion, bot gitlab.User) bool {
+	return slices.ContainsFunc(discussion.Notes, func(note gitlab.DiscussionNote) bool {
+		return !note.System && note.Author.ID != bot.ID && !commentHasMarker(note.Body)
+	})
+}
+
+func formatResolvedReply(options resolvedReplyOptions, keys []string, keptOpen bool) (string, error) {
+	body, err := executeTemplate(options.templates.resolved, resolvedTemplateData{
+		T:           options.templates.catalog,
+		Marker:      resolvedMarker,
+		IssueKeys:   keys,
+		CommitSHA:   options.links.headSHA,
+		ShortSHA:    shortSHA(options.links.headSHA),
+		CommitURL:   options.links.commit(),
+		PipelineID:  options.pipelineID,
+		PipelineURL: options.pipelineURL,
+		KeptOpen:    keptOpen,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return ensureMarkers(strings.TrimSpace(body), resolvedMarker), nil
 }
 
 func discussionContainsMarker(discussion gitlab.Discussion) bool {
@@ -581,31 +1505,112 @@ func discussionContainsMarker(discussion gitlab.Discussion) bool {
 	return false
 }
 
+// discussionPath returns the file of the first tool note of a discussion.
+func discussionPath(discussion gitlab.Discussion) string {
+	for _, note := range discussion.Notes {
+		if commentHasMarker(note.Body) {
+			return normalizeRepoPath(note.NewPath)
+		}
+	}
+
+	return ""
+}
+
+// discussionIssueKeys returns the SonarQube issue keys listed in the first tool note
+// of a discussion.
+func discussionIssueKeys(discussion gitlab.Discussion) []string {
+	for _, note := range discussion.Notes {
+		if !commentHasMarker(note.Body) {
+			continue
+		}
+
+		match := issueKeysMarkerRegex.FindStringSubmatch(note.Body)
+		if match == nil || match[1] == "" {
+			return nil
+		}
+		return strings.Split(match[1], ",")
+	}
+
+	return nil
+}
+
+// summaryNoteResult reports how upsertSummaryNote changed the summary notes.
+type summaryNoteResult struct {
+	// updated is set when the main summary note already existed.
+	updated bool
+	created int
+	deleted int
+}
+
+// upsertSummaryNote writes the main summary note followed by its continuation
+// notes, reusing the notes of the previous run and deleting continuation notes it
+// no longer needs.
 func upsertSummaryNote(
 	ctx context.Context,
 	gitlabClient *gitlab.Client,
 	projectID int,
 	mrIID int,
-	body string,
-) (bool, error) {
-	notes, err := gitlabClient.ListMergeRequestNotes(ctx, projectID, mrIID)
-	if err != nil {
-		return false, err
+	notes []gitlab.MergeRequestNote,
+	bodies []string,
+) (summaryNoteResult, error) {
+	var result summaryNoteResult
+	continuations := findSummaryContinuationNotes(notes)
+	for index, body := range bodies {
+		var (
+			note  gitlab.MergeRequestNote
+			found bool
+		)
+		if index == 0 {
+			note, found = findLatestSummaryNote(notes)
+		} else {
+			note, found = continuations[index+1]
+		}
+
+		if !found {
+			if err := gitlabClient.CreateMergeRequestNote(ctx, projectID, mrIID, body); err != nil {
+				return result, err
+			}
+			result.created++
+			continue
+		}
+		if err := gitlabClient.UpdateMergeRequestNote(ctx, projectID, mrIID, note.ID, body); err != nil {
+			return result, err
+		}
+		result.updated = result.updated || index == 0
 	}
 
-	summaryNote, found := findLatestSummaryNote(notes)
-	if !found {
-		if err := gitlabClient.CreateMergeRequestNote(ctx, projectID, mrIID, body); err != nil {
-			return false, err
+	parts := make([]int, 0, len(continuations))
+	for part := range continuations {
+		parts = append(parts, part)
+	}
+	sort.Ints(parts)
+	for _, part := range parts {
+		if part <= len(bodies) {
+			continue
 		}
-		return false, nil
+		if err := gitlabClient.DeleteMergeRequestNote(ctx, projectID, mrIID, continuations[part].ID); err != nil {
+			return result, err
+		}
+		result.deleted++
 	}
 
-	if err := gitlabClient.UpdateMergeRequestNote(ctx, projectID, mrIID, summaryNote.ID, body); err != nil {
-		return false, err
+	return result, nil
+}
+
+// findSummaryContinuationNotes returns the latest continuation note of each part.
+func findSummaryContinuationNotes(notes []gitlab.MergeRequestNote) map[int]gitlab.MergeRequestNote {
+	continuations := make(map[int]gitlab.MergeRequestNote)
+	for _, note := range notes {
+		part, ok := summaryPart(note.Body)
+		if !ok {
+			continue
+		}
+		if latest, exists := continuations[part]; !exists || note.ID > latest.ID {
+			continuations[part] = note
+		}
 	}
 
-	return true, nil
+	return continuations
 }
 
 func findLatestSummaryNote(notes []gitlab.MergeRequestNote) (gitlab.MergeRequestNote, bool) {
@@ -627,8 +1632,22 @@ func findLatestSummaryNote(notes []gitlab.MergeRequestNote) (gitlab.MergeRequest
 	return latestOne, found
 }
 
+// isSummaryNote recognizes summary notes posted in any locale or by a custom
+// template that carries the summary marker instead of a heading.
 func isSummaryNote(body string) bool {
-	return commentHasMarker(body) && strings.Contains(body, summaryHeading)
+	if !commentHasMarker(body) {
+		return false
+	}
+	if _, continuation := summaryPart(body); continuation {
+		return false
+	}
+	if strings.Contains(body, summaryMarker) {
+		return true
+	}
+
+	return slices.ContainsFunc(i18n.SummaryHeadings(), func(heading string) bool {
+		return strings.Contains(body, heading)
+	})
 }
 
 func commentHasMarker(body string) bool {
@@ -651,58 +1670,224 @@ func splitIssuesByLineBinding(issues []sonar.Issue) ([]sonar.Issue, []sonar.Issu
 	return inlineIssues, projectLevelIssues
 }
 
-func formatInlineIssueComment(issue sonar.Issue) string {
-	return fmt.Sprintf(
-		"%s\n**SonarQube issue**\n- Severity: `%s`\n- Type: `%s`\n- Message: %s\n- Rule key: `%s`",
-		commentMarker,
-		strings.TrimSpace(issue.Severity),
-		strings.TrimSpace(issue.Type),
-		strings.TrimSpace(issue.Message),
-		strings.TrimSpace(issue.Rule),
-	)
+// formatInlineDiscussion renders the body of one inline discussion. The hidden
+// markers are injected when the template leaves them out.
+func formatInlineDiscussion(templates commentTemplates, links commentLinks, issues []sonar.Issue) (string, error) {
+	keys := make([]string, 0, len(issues))
+	data := inlineTemplateData{T: templates.catalog, Marker: commentMarker}
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+		data.Issues = append(data.Issues, newIssueData(links, issue))
+		data.HasImpacts = data.HasImpacts || len(issue.Impacts) > 0
+	}
+	data.IssueKeysMarker = issueKeysMarkerPrefix + strings.Join(keys, ",") + " -->"
+	if len(data.Issues) > 0 {
+		data.Issue = data.Issues[0]
+	}
+
+	body, err := executeTemplate(templates.inline, data)
+	if err != nil {
+		return "", err
+	}
+
+	return ensureMarkers(body, commentMarker, data.IssueKeysMarker), nil
+}
+
+func formatCodeImpacts(impacts []sonar.Impact) string {
+	parts := make([]string, 0, len(impacts))
+	for _, impact := range impacts {
+		parts = append(parts, fmt.Sprintf("`%s:%s`", impact.SoftwareQuality, impact.Severity))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func escapeTableCell(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+func formatImpacts(impacts []sonar.Impact) string {
+	parts := make([]string, 0, len(impacts))
+	for _, impact := range impacts {
+		parts = append(parts, impact.SoftwareQuality+":"+impact.Severity)
+	}
+
+	return strings.Join(parts, ",")
 }
 
 func formatMergeRequestSummaryComment(
+	templates commentTemplates,
+	links commentLinks,
 	qualityReport sonar.QualityReport,
 	issues []sonar.Issue,
 	projectLevelIssues []sonar.Issue,
-) string {
-	issuesBySeverity, unknownSeverityCount := countIssuesBySeverity(issues)
+	overflow []overflowIssue,
+	taxonomy sonar.Taxonomy,
+	previous *summarySnapshot,
+	baseline *baselineComparison,
+	tracked map[string]int,
+) (string, error) {
+	data := summaryTemplateData{
+		T:                 templates.catalog,
+		Marker:            commentMarker,
+		QualityGateStatus: qualityReport.QualityGateStatus,
+		QualityGate:       formatQualityGateStatus(templates.catalog, qualityReport.QualityGateStatus),
+		DashboardURL:      links.sonar.Dashboard(),
+		OverallCoverage:   qualityReport.OverallCoverage,
+		NewCodeCoverage:   qualityReport.NewCodeCoverage,
+		TotalIssues:       len(issues),
+		Baseline:          newBaselineData(links, baseline),
+		Taxonomy:          string(taxonomy),
+		TrackedIssues:     newTrackedIssueData(links, tracked, issues),
+	}
 
-	var builder strings.Builder
-	builder.WriteString(commentMarker)
-	builder.WriteString("\n")
-	builder.WriteString(summaryHeading)
-	builder.WriteString("\n")
-	builder.WriteString(fmt.Sprintf("- Quality gate: %s\n", formatQualityGateStatus(qualityReport.QualityGateStatus)))
-	builder.WriteString(fmt.Sprintf("- Overall coverage: %.2f%%\n", qualityReport.OverallCoverage))
-	builder.WriteString(fmt.Sprintf("- New code coverage: %.2f%%\n", qualityReport.NewCodeCoverage))
-	builder.WriteString(fmt.Sprintf("- Total issues: %d\n", len(issues)))
-	builder.WriteString("\n**Issues by severity**\n")
+	issuesBySeverity, unknownSeverityCount := countIssuesBySeverity(issues)
 	for _, severity := range summarySeverityOrder {
-		builder.WriteString(fmt.Sprintf("- %s: %d\n", severity, issuesBySeverity[severity]))
-	}
-	if unknownSeverityCount > 0 {
-		builder.WriteString(fmt.Sprintf("- UNKNOWN: %d\n", unknownSeverityCount))
-	}
-
-	if len(projectLevelIssues) > 0 {
-		builder.WriteString("\n**SonarQube issues without line binding**\n")
-		for i, issue := range projectLevelIssues {
-			builder.WriteString(
-				fmt.Sprintf(
-					"%d. [%s][%s] %s (rule `%s`)\n",
-					i+1,
-					strings.TrimSpace(issue.Severity),
-					strings.TrimSpace(issue.Type),
-					strings.TrimSpace(issue.Message),
-					strings.TrimSpace(issue.Rule),
-				),
-			)
+		data.SeverityCounts = append(data.SeverityCounts, severityCount{Severity: severity, Count: issuesBySeverity[severity]})
+	}
+	data.UnknownSeverityCount = unknownSeverityCount
+
+	issuesByQuality, unclassifiedCount := countIssuesBySoftwareQuality(issues)
+	for _, quality := range sonar.SoftwareQualities() {
+		qualityCount := softwareQualityCount{Quality: quality}
+		for _, severity := range summaryImpactSeverityOrder {
+			if count := issuesByQuality[quality][severity]; count > 0 {
+				qualityCount.Total += count
+				qualityCount.Severities = append(qualityCount.Severities, severityCount{Severity: severity, Count: count})
+			}
+		}
+		data.SoftwareQualityCounts = append(data.SoftwareQualityCounts, qualityCount)
+	}
+	data.UnclassifiedCount = unclassifiedCount
+
+	summaryOnly := make(map[string]bool, len(projectLevelIssues)+len(overflow))
+	for _, issue := range projectLevelIssues {
+		data.ProjectLevelIssues = append(data.ProjectLevelIssues, newIssueData(links, issue))
+		summaryOnly[issue.Key] = true
+	}
+	for _, entry := range overflow {
+		summaryOnly[entry.issue.Key] = true
+	}
+	data.FileSeverities = fileBreakdownSeverities(taxonomy)
+	data.Files = buildFileBreakdown(links, issues, summaryOnly, taxonomy)
+	data.FilesCollapsed = templates.fileRows > 0 && len(data.Files) > templates.fileRows
+	data.TopRules = topViolatedRules(links, issues, summaryTopRules)
+
+	current := newSummarySnapshot(qualityReport, issues)
+	if len(tracked) > 0 {
+		current.Tracked = tracked
+	}
+	if previous != nil {
+		delta := newSummaryDelta(links, *previous, current, issues)
+		delta.PreviousQualityGate = formatQualityGateStatus(templates.catalog, delta.PreviousQualityGateStatus)
+		data.Delta = &delta
+	}
+	snapshot, err := encodeSummarySnapshot(current)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode summary snapshot: %w", err)
+	}
+
+	overflowData := newOverflowTemplateData(templates.catalog, links, overflow)
+	body, err := executeSummaryTemplate(templates, data, overflowData, snapshot)
+	if err != nil || noteLength(body) <= templates.noteLimit {
+		return body, err
+	}
+
+	// Too long for one note: collapse the file table and shorten issue messages
+	// before the caller splits the rest into continuation notes.
+	data.FilesCollapsed = len(data.Files) > 0
+	for i := range data.ProjectLevelIssues {
+		data.ProjectLevelIssues[i].Message = truncateRunes(data.ProjectLevelIssues[i].Message, compactMessageLength)
+	}
+	for _, file := range overflowData.Files {
+		for i := range file.Issues {
+			file.Issues[i].Message = truncateRunes(file.Issues[i].Message, compactMessageLength)
+		}
+	}
+
+	return executeSummaryTemplate(templates, data, overflowData, snapshot)
+}
+
+// executeSummaryTemplate renders the summary and prepends the snapshot marker and
+// any marker the template left out.
+func executeSummaryTemplate(
+	templates commentTemplates,
+	data summaryTemplateData,
+	overflow overflowTemplateData,
+	snapshot string,
+) (string, error) {
+	data.Overflow = ""
+	if overflow.Count > 0 {
+		section, err := executeTemplate(templates.overflow, overflow)
+		if err != nil {
+			return "", err
+		}
+		data.Overflow = strings.TrimRight(section, "\n")
+	}
+
+	body, err := executeTemplate(templates.summary, data)
+	if err != nil {
+		return "", err
+	}
+	body = strings.TrimRight(body, "\n")
+	if !isSummaryNote(body) {
+		body = ensureMarkers(body, commentMarker, summaryMarker)
+	}
+
+	return snapshot + "\n" + body, nil
+}
+
+// newOverflowTemplateData groups issues demoted by inline comment limits by file,
+// in order of first appearance, and sorts each file by line.
+func newOverflowTemplateData(catalog i18n.Catalog, links commentLinks, overflow []overflowIssue) overflowTemplateData {
+	data := overflowTemplateData{T: catalog, Count: len(overflow)}
+	fileIndexes := make(map[string]int)
+	for _, entry := range overflow {
+		position, exists := fileIndexes[entry.issue.FilePath]
+		if !exists {
+			position = len(data.Files)
+			fileIndexes[entry.issue.FilePath] = position
+			data.Files = append(data.Files, overflowFile{Path: entry.issue.FilePath})
+		}
+
+		issue := newIssueData(links, entry.issue)
+		issue.Link = entry.link
+		data.Files[position].Issues = append(data.Files[position].Issues, issue)
+	}
+	for _, file := range data.Files {
+		slices.SortStableFunc(file.Issues, func(a, b issueData) int { return a.Line - b.Line })
+	}
+
+	return data
+}
+
+// countIssuesBySoftwareQuality counts issues per software quality and impact severity.
+// An issue with impacts on several qualities is counted once for each of them.
+func countIssuesBySoftwareQuality(issues []sonar.Issue) (map[string]map[string]int, int) {
+	counts := make(map[string]map[string]int, len(sonar.SoftwareQualities()))
+	for _, quality := range sonar.SoftwareQualities() {
+		counts[quality] = make(map[string]int, len(summaryImpactSeverityOrder))
+	}
+
+	unclassifiedCount := 0
+	for _, issue := range issues {
+		classified := false
+		for _, impact := range issue.Impacts {
+			severityCounts, ok := counts[sonar.NormalizeSeverity(impact.SoftwareQuality)]
+			if !ok || !sonar.IsValidImpactSeverity(impact.Severity) {
+				continue
+			}
+
+			severityCounts[sonar.NormalizeSeverity(impact.Severity)]++
+			classified = true
+		}
+		if !classified {
+			unclassifiedCount++
 		}
 	}
 
-	return strings.TrimRight(builder.String(), "\n")
+	return counts, unclassifiedCount
 }
 
 func countIssuesBySeverity(issues []sonar.Issue) (map[string]int, int) {
@@ -725,13 +1910,13 @@ func countIssuesBySeverity(issues []sonar.Issue) (map[string]int, int) {
 	return counts, unknownSeverityCount
 }
 
-func formatQualityGateStatus(status string) string {
+func formatQualityGateStatus(catalog i18n.Catalog, status string) string {
 	switch strings.ToLower(strings.TrimSpace(status)) {
 	case "passed":
-		return "✅ **passed**"
+		return "✅ **" + catalog.QualityGatePassed + "**"
 	case "failed":
-		return "❌ **failed**"
+		return "❌ **" + catalog.QualityGateFailed + "**"
 	default:
-		return "⚠️ **warning**"
+		return "⚠️ **" + catalog.QualityGateWarning + "**"
 	}
 }
@@ -3,13 +3,20 @@ package main
 import (
 	"bytes"
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"sonar-gitlab-commenter/internal/config"
 	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
 	"sonar-gitlab-commenter/internal/sonar"
 )
 
@@ -31,7 +38,8 @@ func TestFormatMergeRequestSummaryComment(t *testing.T) {
 		},
 	}
 
-	comment := formatMergeRequestSummaryComment(
+	comment := renderSummaryComment(
+		t,
 		sonar.QualityReport{
 			QualityGateStatus: "passed",
 			OverallCoverage:   82.4,
@@ -39,6 +47,8 @@ func TestFormatMergeRequestSummaryComment(t *testing.T) {
 		},
 		issues,
 		projectLevelIssues,
+		nil,
+		sonar.TaxonomyLegacy,
 	)
 
 	assertCommentContains(t, comment, commentMarker)
@@ -59,10 +69,13 @@ func TestFormatMergeRequestSummaryComment(t *testing.T) {
 func TestFormatMergeRequestSummaryCommentWithoutProjectLevelIssues(t *testing.T) {
 	t.Parallel()
 
-	comment := formatMergeRequestSummaryComment(
+	comment := renderSummaryComment(
+		t,
 		sonar.QualityReport{QualityGateStatus: "failed"},
 		[]sonar.Issue{{Severity: "MINOR"}},
 		nil,
+		nil,
+		sonar.TaxonomyLegacy,
 	)
 
 	assertCommentContains(t, comment, "Quality gate: ❌ **failed**")
@@ -72,14 +85,209 @@ func TestFormatMergeRequestSummaryCommentWithoutProjectLevelIssues(t *testing.T)
 	}
 }
 
+func TestFormatMergeRequestSummaryCommentCleanCodeTaxonomy(t *testing.T) {
+	t.Parallel()
+
+	comment := renderSummaryComment(
+		t,
+		sonar.QualityReport{QualityGateStatus: "passed"},
+		[]sonar.Issue{
+			{Key: "A", FilePath: "a.go", Impacts: []sonar.Impact{{SoftwareQuality: "SECURITY", Severity: "HIGH"}}},
+			{Key: "B", Impacts: []sonar.Impact{
+				{SoftwareQuality: "SECURITY", Severity: "LOW"},
+				{SoftwareQuality: "MAINTAINABILITY", Severity: "MEDIUM"},
+			}},
+			{Key: "C", Severity: "MAJOR"},
+		},
+		nil,
+		nil,
+		sonar.TaxonomyCleanCode,
+	)
+
+	assertCommentContains(t, comment, "**Issues by software quality**")
+	assertCommentContains(t, comment, "- SECURITY: 2 (HIGH: 1, LOW: 1)")
+	assertCommentContains(t, comment, "- RELIABILITY: 0")
+	assertCommentContains(t, comment, "- MAINTAINABILITY: 1 (MEDIUM: 1)")
+	assertCommentContains(t, comment, "- UNCLASSIFIED: 1")
+	assertCommentContains(t, comment, "| File | BLOCKER | HIGH | MEDIUM | LOW | INFO | Inline | Summary only |")
+	assertCommentContains(t, comment, "| `a.go` | 0 | 1 | 0 | 0 | 0 | 1 | 0 |")
+	if strings.Contains(comment, "Issues by severity") {
+		t.Fatalf("did not expect legacy severity breakdown, got %q", comment)
+	}
+}
+
+func TestFormatMergeRequestSummaryCommentOverflowSection(t *testing.T) {
+	t.Parallel()
+
+	comment := renderSummaryComment(
+		t,
+		sonar.QualityReport{QualityGateStatus: "passed"},
+		[]sonar.Issue{{Severity: "MAJOR"}, {Severity: "MINOR"}, {Severity: "MINOR"}},
+		nil,
+		[]overflowIssue{
+			{issue: sonar.Issue{FilePath: "b.go", Line: 7, Severity: "MAJOR", Type: "BUG", Message: "late", Rule: "go:S1"}, link: "https://gitlab/mr/diffs#abc_7_7"},
+			{issue: sonar.Issue{FilePath: "a.go", Line: 9, Severity: "MINOR", Type: "CODE_SMELL", Message: "second", Rule: "go:S2"}},
+			{issue: sonar.Issue{FilePath: "b.go", Line: 3, Severity: "MINOR", Type: "CODE_SMELL", Message: "early", Rule: "go:S2"}},
+		},
+		sonar.TaxonomyLegacy,
+	)
+
+	assertCommentContains(t, comment, "<details>\n<summary>3 more issues not posted inline (inline comment limit reached)</summary>")
+	assertCommentContains(t, comment, "`b.go`\n- L3 [MINOR][CODE_SMELL] early (rule `go:S2`)\n- [L7](https://gitlab/mr/diffs#abc_7_7) [MAJOR][BUG] late (rule `go:S1`)")
+	assertCommentContains(t, comment, "`a.go`\n- L9 [MINOR][CODE_SMELL] second (rule `go:S2`)")
+	if !strings.HasSuffix(comment, "</details>") {
+		t.Fatalf("expected overflow section to be closed, got %q", comment)
+	}
+}
+
+func TestFormatMergeRequestSummaryCommentFileBreakdown(t *testing.T) {
+	t.Parallel()
+
+	issues := []sonar.Issue{
+		{Key: "1", FilePath: "a.go", Severity: "MINOR", Rule: "go:S2"},
+		{Key: "2", FilePath: "b.go", Severity: "CRITICAL", Rule: "go:S1"},
+		{Key: "3", FilePath: "b.go", Severity: "MINOR", Rule: "go:S2"},
+	}
+	comment := renderSummaryComment(
+		t,
+		sonar.QualityReport{QualityGateStatus: "passed"},
+		issues,
+		nil,
+		[]overflowIssue{{issue: issues[2]}},
+		sonar.TaxonomyLegacy,
+	)
+
+	assertCommentContains(t, comment, "**Issues by file**\n\n| File | BLOCKER | CRITICAL | MAJOR | MINOR | INFO | Inline | Summary only |")
+	assertCommentContains(t, comment, "| `b.go` | 0 | 1 | 0 | 1 | 0 | 1 | 1 |\n| `a.go` | 0 | 0 | 0 | 1 | 0 | 1 | 0 |")
+	assertCommentContains(t, comment, "**Top violated rules**\n- `go:S2`: 2\n- `go:S1`: 1")
+	if strings.Contains(comment, "<summary>2 files with issues</summary>") {
+		t.Fatalf("expected short file table to stay expanded, got %q", comment)
+	}
+
+	templates := defaultCommentTemplates()
+	templates.fileRows = 1
+	collapsed, err := formatMergeRequestSummaryComment(templates, commentLinks{}, sonar.QualityReport{}, issues, nil, nil, sonar.TaxonomyLegacy, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("formatMergeRequestSummaryComment returned error: %v", err)
+	}
+	assertCommentContains(t, collapsed, "**Issues by file**\n<details>\n<summary>2 files with issues</summary>\n\n| File |")
+	assertCommentContains(t, collapsed, "| 1 | 0 |\n\n</details>")
+}
+
+func TestFormatMergeRequestSummaryCommentDeltaSection(t *testing.T) {
+	t.Parallel()
+
+	issues := []sonar.Issue{
+		{Key: "KEPT", Severity: "MAJOR", Type: "BUG", Message: "still here", Rule: "go:S1"},
+		{Key: "NEW", Severity: "CRITICAL", Type: "BUG", Message: "brand new", Rule: "go:S2"},
+	}
+	previous := &summarySnapshot{
+		QualityGate:     "passed",
+		OverallCoverage: 80,
+		NewCodeCoverage: 70,
+		Issues:          map[string]string{"KEPT": "MAJOR", "GONE": "MINOR"},
+	}
+
+	comment, err := formatMergeRequestSummaryComment(
+		defaultCommentTemplates(),
+		commentLinks{},
+		sonar.QualityReport{QualityGateStatus: "failed", OverallCoverage: 81.25, NewCodeCoverage: 70},
+		issues,
+		nil,
+		nil,
+		sonar.TaxonomyLegacy,
+		previous,
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	assertCommentContains(t, comment, "- Total issues: 2\n\n**Since last analysis**\n- Quality gate: ✅ **passed** → ❌ **failed**")
+	assertCommentContains(t, comment, "- Overall coverage: +1.25%\n- New code coverage: +0.00%")
+	assertCommentContains(t, comment, "- New issues: 1\n  - [CRITICAL][BUG] brand new (rule `go:S2`)")
+	assertCommentContains(t, comment, "- Fixed issues: 1\n  - `GONE` (MINOR)")
+	assertCommentContains(t, comment, "- Unchanged issues: 1\n\n**Issues by severity**")
+
+	snapshot, ok := decodeSummarySnapshot(comment)
+	if !ok || len(snapshot.Issues) != 2 || snapshot.QualityGate != "failed" {
+		t.Fatalf("expected current snapshot in the summary, got %+v (ok=%v)", snapshot, ok)
+	}
+
+	first := renderSummaryComment(t, sonar.QualityReport{}, issues, nil, nil, sonar.TaxonomyLegacy)
+	if strings.Contains(first, "Since last analysis") {
+		t.Fatalf("did not expect delta section without a previous snapshot, got %q", first)
+	}
+}
+
+func TestFormatInlineIssueCommentIncludesImpacts(t *testing.T) {
+	t.Parallel()
+
+	comment := renderInlineDiscussion(t, sonar.Issue{
+		Severity:                   "MAJOR",
+		Type:                       "BUG",
+		Message:                    "Null dereference",
+		Rule:                       "go:S2259",
+		Impacts:                    []sonar.Impact{{SoftwareQuality: "RELIABILITY", Severity: "HIGH"}},
+		CleanCodeAttribute:         "LOGICAL",
+		CleanCodeAttributeCategory: "INTENTIONAL",
+	})
+
+	assertCommentContains(t, comment, "- Impacts: `RELIABILITY:HIGH`")
+	assertCommentContains(t, comment, "- Clean code attribute: `LOGICAL` (INTENTIONAL)")
+}
+
+func TestFormatCommentsWithLinks(t *testing.T) {
+	t.Parallel()
+
+	links := commentLinks{
+		sonar:      sonar.UILinks{BaseURL: "https://sonar.example.com", ProjectKey: "app", PullRequest: "42"},
+		projectURL: "https://gitlab.example.com/group/app",
+		headSHA:    "abc123",
+	}
+	issue := sonar.Issue{Key: "AX-1", Rule: "go:S100", Severity: "MAJOR", Type: "BUG", Message: "boom", FilePath: "main.go", Line: 7}
+
+	inline, err := formatInlineDiscussion(defaultCommentTemplates(), links, []sonar.Issue{issue})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertCommentContains(t, inline, "- Rule key: [`go:S100`](https://sonar.example.com/coding_rules?open=go%3AS100&rule_key=go%3AS100)")
+	assertCommentContains(t, inline, "- [Open in SonarQube](https://sonar.example.com/project/issues?id=app&open=AX-1&pullRequest=42)")
+
+	grouped, err := formatInlineDiscussion(defaultCommentTemplates(), links, []sonar.Issue{issue, issue})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertCommentContains(t, grouped, "| 7 | `MAJOR` | `BUG` | [`go:S100`](https://sonar.example.com/coding_rules?open=go%3AS100&rule_key=go%3AS100) | [boom](https://sonar.example.com/project/issues?id=app&open=AX-1&pullRequest=42) |")
+
+	summary, err := formatMergeRequestSummaryComment(
+		defaultCommentTemplates(),
+		links,
+		sonar.QualityReport{QualityGateStatus: "passed"},
+		[]sonar.Issue{issue},
+		[]sonar.Issue{issue},
+		nil,
+		sonar.TaxonomyLegacy,
+		nil,
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertCommentContains(t, summary, "**SonarQube summary**\n- [SonarQube dashboard](https://sonar.example.com/dashboard?id=app&pullRequest=42)\n")
+	assertCommentContains(t, summary, "1. [MAJOR][BUG] boom (rule `go:S100`) — [main.go:7](https://gitlab.example.com/group/app/-/blob/abc123/main.go#L7)")
+}
+
 func TestFindLatestSummaryNote(t *testing.T) {
 	t.Parallel()
 
 	notes := []gitlab.MergeRequestNote{
 		{ID: 11, Body: "regular note"},
 		{ID: 20, Body: commentMarker + "\n**SonarQube issue**"},
-		{ID: 30, Body: commentMarker + "\n" + summaryHeading},
-		{ID: 31, Body: commentMarker + "\n" + summaryHeading + "\nupdated"},
+		{ID: 30, Body: commentMarker + "\n" + "**SonarQube summary**"},
+		{ID: 31, Body: commentMarker + "\n" + "**SonarQube summary**" + "\nupdated"},
 	}
 
 	note, found := findLatestSummaryNote(notes)
@@ -91,6 +299,31 @@ func TestFindLatestSummaryNote(t *testing.T) {
 	}
 }
 
+func TestFindLatestSummaryNoteAcrossLocales(t *testing.T) {
+	t.Parallel()
+
+	russian, _ := i18n.Lookup("ru")
+	templates, err := loadCommentTemplates(russian, "", "", "", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	body, err := formatMergeRequestSummaryComment(templates, commentLinks{}, sonar.QualityReport{QualityGateStatus: "failed"}, nil, nil, nil, sonar.TaxonomyLegacy, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertCommentContains(t, body, "**Сводка SonarQube**")
+	assertCommentContains(t, body, "- Quality gate: ❌ **не пройден**")
+
+	note, found := findLatestSummaryNote([]gitlab.MergeRequestNote{
+		{ID: 5, Body: commentMarker + "\n**SonarQube summary**"},
+		{ID: 9, Body: body},
+		{ID: 12, Body: commentMarker + "\n**Проблема SonarQube**"},
+	})
+	if !found || note.ID != 9 {
+		t.Fatalf("expected Russian summary note to be found, got %+v (found=%v)", note, found)
+	}
+}
+
 func TestFindLatestSummaryNoteWhenMissing(t *testing.T) {
 	t.Parallel()
 
@@ -231,15 +464,20 @@ func TestFilterIssuesByMRDiff(t *testing.T) {
 func TestResolvePreviousSonarDiscussionsResolvesOnlyToolThreads(t *testing.T) {
 	t.Parallel()
 
+	discussions := []gitlab.Discussion{
+		{ID: "tool-open", Resolvable: true, Notes: []gitlab.DiscussionNote{{Body: commentMarker + "\nold"}}},
+		{ID: "tool-resolved", Resolved: true, Resolvable: true, Notes: []gitlab.DiscussionNote{{Body: commentMarker + "\nresolved"}}},
+		{ID: "other-open", Resolvable: true, Notes: []gitlab.DiscussionNote{{Body: "external"}}},
+	}
+
 	resolvedCalls := 0
+	var replies []string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch {
-		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
-			_, _ = w.Write([]byte(`[
-				{"id":"tool-open","resolved":false,"resolvable":true,"notes":[{"body":"` + commentMarker + `\nold"}]},
-				{"id":"tool-resolved","resolved":true,"resolvable":true,"notes":[{"body":"` + commentMarker + `\nresolved"}]},
-				{"id":"other-open","resolved":false,"resolvable":true,"notes":[{"body":"external"}]}
-			]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions/tool-open/notes":
+			_ = r.ParseForm()
+			replies = append(replies, r.PostForm.Get("body"))
+			w.WriteHeader(http.StatusCreated)
 		case r.Method == http.MethodPut && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions/tool-open":
 			if err := r.ParseForm(); err != nil {
 				t.Fatalf("failed to parse form: %v", err)
@@ -256,16 +494,316 @@ func TestResolvePreviousSonarDiscussionsResolvesOnlyToolThreads(t *testing.T) {
 	defer server.Close()
 
 	client := gitlab.NewClient(server.URL, "secret-token", server.Client())
-	resolvedCount, err := resolvePreviousSonarDiscussions(context.Background(), client, 100, 42)
+	reply := resolvedReplyOptions{
+		templates:   defaultCommentTemplates(),
+		links:       commentLinks{projectURL: "https://gitlab.example.com/group/app", headSHA: "0123456789abcdef"},
+		pipelineID:  "77",
+		pipelineURL: "https://gitlab.example.com/group/app/-/pipelines/77",
+	}
+	resolution, err := resolvePreviousSonarDiscussions(
+		context.Background(),
+		client,
+		100,
+		42,
+		discussions,
+		nil,
+		config.ResolvePolicyAlways,
+		gitlab.User{},
+		reply,
+	)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if resolvedCount != 1 {
-		t.Fatalf("expected 1 resolved discussion, got %d", resolvedCount)
+	if resolution.resolved != 1 {
+		t.Fatalf("expected 1 resolved discussion, got %d", resolution.resolved)
 	}
 	if resolvedCalls != 1 {
 		t.Fatalf("expected 1 resolve request, got %d", resolvedCalls)
 	}
+	expectedReplies := []string{resolvedMarker + "\nNo longer reported by SonarQube as of commit " +
+		"[`01234567`](https://gitlab.example.com/group/app/-/commit/0123456789abcdef) " +
+		"([pipeline #77](https://gitlab.example.com/group/app/-/pipelines/77))."}
+	if !reflect.DeepEqual(replies, expectedReplies) {
+		t.Fatalf("expected replies %q, got %q", expectedReplies, replies)
+	}
+}
+
+func TestResolvePreviousSonarDiscussionsSkipsDiscussedThreads(t *testing.T) {
+	t.Parallel()
+
+	bot := gitlab.User{ID: 1, Username: "bot"}
+	alice := gitlab.User{ID: 2, Username: "alice"}
+	toolNote := gitlab.DiscussionNote{Body: commentMarker + "\n" + issueKeysMarkerPrefix + "A -->", Author: bot}
+	discussions := []gitlab.Discussion{
+		{ID: "discussed", Resolvable: true, Notes: []gitlab.DiscussionNote{toolNote, {Body: "Fixed by moving the check", Author: alice}}},
+		{ID: "replied", Resolvable: true, Notes: []gitlab.DiscussionNote{toolNote, {Body: "thanks", Author: alice}, {Body: resolvedMarker + "\ngone", Author: bot}}},
+		{ID: "system", Resolvable: true, Notes: []gitlab.DiscussionNote{toolNote, {Body: "changed this line in version 2 of the diff", Author: alice, System: true}}},
+		{ID: "bot-only", Resolvable: true, Notes: []gitlab.DiscussionNote{toolNote, {Body: transitionMarker + "\ndone", Author: bot}}},
+	}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		request := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/discussions/")
+		if body := r.PostForm.Get("body"); body != "" {
+			request += " " + body
+		}
+		requests = append(requests, request)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient(server.URL, "secret-token", server.Client())
+	reply := resolvedReplyOptions{templates: defaultCommentTemplates(), links: commentLinks{headSHA: "0123456789abcdef"}}
+	resolution, err := resolvePreviousSonarDiscussions(
+		context.Background(),
+		client,
+		100,
+		42,
+		discussions,
+		nil,
+		config.ResolvePolicySkipDiscussed,
+		bot,
+		reply,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	fixed := resolvedMarker + "\nNo longer reported by SonarQube as of commit `01234567`."
+	expected := []string{
+		"POST discussed/notes " + fixed + " Left open because the discussion has replies.",
+		"POST system/notes " + fixed,
+		"PUT system",
+		"POST bot-only/notes " + fixed,
+		"PUT bot-only",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("expected requests %q, got %q", expected, requests)
+	}
+	if resolution.resolved != 2 || resolution.keptOpen != 2 || resolution.reopened != 0 {
+		t.Fatalf("expected 2 resolved and 2 kept open discussions, got %+v", resolution)
+	}
+}
+
+func TestResolvePreviousSonarDiscussionsReopensThreadsResolvedByPeople(t *testing.T) {
+	t.Parallel()
+
+	bot := gitlab.User{ID: 1, Username: "bot"}
+	alice := gitlab.User{ID: 2, Username: "alice"}
+	resolvedBy := func(id string, resolver gitlab.User, keys string) gitlab.Discussion {
+		return gitlab.Discussion{ID: id, Resolved: true, Resolvable: true, Notes: []gitlab.DiscussionNote{
+			{Body: commentMarker + "\n" + issueKeysMarkerPrefix + keys + " -->", Author: bot, ResolvedBy: resolver},
+		}}
+	}
+	discussions := []gitlab.Discussion{
+		resolvedBy("by-alice", alice, "A"),
+		resolvedBy("by-bot", bot, "B"),
+		resolvedBy("fixed", alice, "GONE"),
+	}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		requests = append(requests, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/discussions/")+" "+r.PostForm.Encode())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient(server.URL, "secret-token", server.Client())
+	resolution, err := resolvePreviousSonarDiscussions(
+		context.Background(),
+		client,
+		100,
+		42,
+		discussions,
+		[]sonar.Issue{{Key: "A"}, {Key: "B"}},
+		config.ResolvePolicyReopen,
+		bot,
+		resolvedReplyOptions{templates: defaultCommentTemplates()},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(requests, []string{"PUT by-alice resolved=false"}) {
+		t.Fatalf("expected only by-alice to be reopened, got %q", requests)
+	}
+	if resolution.reopened != 1 || resolution.resolved != 0 || !reflect.DeepEqual(resolution.openIssueKeys, map[string]bool{"A": true}) {
+		t.Fatalf("unexpected resolution: %+v", resolution)
+	}
+}
+
+func TestResolvePreviousSonarDiscussionsRepliesAgainAfterIssuesReturn(t *testing.T) {
+	t.Parallel()
+
+	bot := gitlab.User{ID: 1, Username: "bot"}
+	alice := gitlab.User{ID: 2, Username: "alice"}
+	fixed := resolvedMarker + "\nNo longer reported by SonarQube as of commit `01234567`."
+	discussion := gitlab.Discussion{ID: "d1", Resolved: true, Resolvable: true, Notes: []gitlab.DiscussionNote{
+		{Body: commentMarker + "\n" + issueKeysMarkerPrefix + "A -->", Author: bot, ResolvedBy: alice},
+		{Body: fixed, Author: bot, ResolvedBy: alice},
+	}}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		request := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/discussions/")
+		if body := r.PostForm.Get("body"); body != "" {
+			request += " " + body
+		}
+		requests = append(requests, request)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient(server.URL, "secret-token", server.Client())
+	reply := resolvedReplyOptions{templates: defaultCommentTemplates(), links: commentLinks{headSHA: "0123456789abcdef"}}
+	resolve := func(issues []sonar.Issue) []string {
+		requests = nil
+		if _, err := resolvePreviousSonarDiscussions(
+			context.Background(), client, 100, 42, []gitlab.Discussion{discussion}, issues, config.ResolvePolicyReopen, bot, reply,
+		); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return requests
+	}
+
+	// The issue came back and a reviewer resolved the thread again.
+	reportedAgain := reportedAgainMarker + "\nReported by SonarQube again as of commit `01234567`."
+	expected := []string{"PUT d1", "POST d1/notes " + reportedAgain}
+	if got := resolve([]sonar.Issue{{Key: "A"}}); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected requests %q, got %q", expected, got)
+	}
+
+	// The issue is fixed again, so the thread gets a new resolved reply.
+	discussion.Resolved = false
+	discussion.Notes = append(discussion.Notes, gitlab.DiscussionNote{Body: reportedAgain, Author: bot})
+	expected = []string{"POST d1/notes " + fixed, "PUT d1"}
+	if got := resolve(nil); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected requests %q, got %q", expected, got)
+	}
+
+	// A rerun before the thread is resolved does not repeat the reply.
+	discussion.Notes = append(discussion.Notes, gitlab.DiscussionNote{Body: fixed, Author: bot})
+	expected = []string{"PUT d1"}
+	if got := resolve(nil); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected requests %q, got %q", expected, got)
+	}
+}
+
+func TestResolvePreviousSonarDiscussionsKeepsGroupsWithRemainingIssues(t *testing.T) {
+	t.Parallel()
+
+	discussions := []gitlab.Discussion{
+		{ID: "partly-fixed", Resolvable: true, Notes: []gitlab.DiscussionNote{{Body: renderInlineDiscussion(t, sonar.Issue{Key: "A", Line: 1}, sonar.Issue{Key: "B", Line: 2})}}},
+		{ID: "fully-fixed", Resolvable: true, Notes: []gitlab.DiscussionNote{{Body: renderInlineDiscussion(t, sonar.Issue{Key: "C", Line: 3}, sonar.Issue{Key: "D", Line: 3})}}},
+	}
+
+	var resolvedIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/discussions/fully-fixed/notes") {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if r.Method != http.MethodPut {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		resolvedIDs = append(resolvedIDs, strings.TrimPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/discussions/"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient(server.URL, "secret-token", server.Client())
+	resolution, err := resolvePreviousSonarDiscussions(
+		context.Background(),
+		client,
+		100,
+		42,
+		discussions,
+		[]sonar.Issue{{Key: "B"}, {Key: "E"}},
+		config.ResolvePolicyAlways,
+		gitlab.User{},
+		resolvedReplyOptions{templates: defaultCommentTemplates()},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resolution.resolved != 1 || !reflect.DeepEqual(resolvedIDs, []string{"fully-fixed"}) {
+		t.Fatalf("unexpected resolved discussions: count=%d ids=%v", resolution.resolved, resolvedIDs)
+	}
+	if !reflect.DeepEqual(resolution.openIssueKeys, map[string]bool{"A": true, "B": true}) {
+		t.Fatalf("unexpected open issue keys: %v", resolution.openIssueKeys)
+	}
+}
+
+func TestPlanInlineDiscussionsGrouping(t *testing.T) {
+	t.Parallel()
+
+	index := buildDiffLineIndex([]gitlab.MergeRequestChange{
+		{OldPath: "a.go", NewPath: "a.go", Diff: "@@ -0,0 +1,3 @@\n+one\n+two\n+three"},
+		{OldPath: "b.go", NewPath: "b.go", Diff: "@@ -0,0 +1,1 @@\n+one"},
+	})
+	issues := []sonar.Issue{
+		{Key: "K1", Rule: "go:S1", FilePath: "a.go", Line: 3},
+		{Key: "K2", Rule: "go:S2", FilePath: "a.go", Line: 3},
+		{Key: "K3", Rule: "go:S1", FilePath: "a.go", Line: 1},
+		{Key: "K4", Rule: "go:S1", FilePath: "b.go", Line: 1},
+	}
+
+	tests := []struct {
+		grouping string
+		expected [][]string
+		anchors  []int
+	}{
+		{grouping: config.InlineGroupingNone, expected: [][]string{{"K1"}, {"K2"}, {"K3"}, {"K4"}}, anchors: []int{3, 3, 1, 1}},
+		{grouping: config.InlineGroupingLine, expected: [][]string{{"K1", "K2"}, {"K3"}, {"K4"}}, anchors: []int{3, 1, 1}},
+		{grouping: config.InlineGroupingRuleFile, expected: [][]string{{"K3", "K1"}, {"K2"}, {"K4"}}, anchors: []int{1, 3, 1}},
+	}
+
+	for _, test := range tests {
+		posts, unpostable, err := planInlineDiscussions(io.Discard, false, issues, index, test.grouping)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", test.grouping, err)
+		}
+		if len(unpostable) != 0 {
+			t.Fatalf("%s: unexpected unpostable issues: %+v", test.grouping, unpostable)
+		}
+
+		var keys [][]string
+		var anchors []int
+		for _, post := range posts {
+			keys = append(keys, post.issueKeys())
+			anchors = append(anchors, post.line.newLine)
+		}
+		if !reflect.DeepEqual(keys, test.expected) {
+			t.Fatalf("%s: unexpected groups %v", test.grouping, keys)
+		}
+		if !reflect.DeepEqual(anchors, test.anchors) {
+			t.Fatalf("%s: unexpected anchor lines %v", test.grouping, anchors)
+		}
+	}
+}
+
+func TestFormatInlineDiscussionGroupTable(t *testing.T) {
+	t.Parallel()
+
+	comment := renderInlineDiscussion(
+		t,
+		sonar.Issue{Key: "K1", Severity: "MAJOR", Type: "BUG", Rule: "go:S1", Message: "first | piped", Line: 3},
+		sonar.Issue{Key: "K2", Severity: "MINOR", Type: "CODE_SMELL", Rule: "go:S2", Message: "second\nline", Line: 3},
+	)
+
+	assertCommentContains(t, comment, commentMarker)
+	assertCommentContains(t, comment, "<!-- sonar-issues: K1,K2 -->")
+	assertCommentContains(t, comment, "**SonarQube issues (2)**")
+	assertCommentContains(t, comment, "| Line | Severity | Type | Rule | Message |")
+	assertCommentContains(t, comment, "| 3 | `MAJOR` | `BUG` | `go:S1` | first \\| piped |")
+	assertCommentContains(t, comment, "| 3 | `MINOR` | `CODE_SMELL` | `go:S2` | second line |")
+
+	if keys := discussionIssueKeys(gitlab.Discussion{Notes: []gitlab.DiscussionNote{{Body: comment}}}); !reflect.DeepEqual(keys, []string{"K1", "K2"}) {
+		t.Fatalf("unexpected issue keys parsed from discussion: %v", keys)
+	}
 }
 
 func TestUpsertSummaryNoteCreatesOnFirstRun(t *testing.T) {
@@ -274,14 +812,12 @@ func TestUpsertSummaryNoteCreatesOnFirstRun(t *testing.T) {
 	createCalls := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch {
-		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
-			_, _ = w.Write([]byte(`[]`))
 		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
 			if err := r.ParseForm(); err != nil {
 				t.Fatalf("failed to parse form: %v", err)
 			}
 			body := r.PostForm.Get("body")
-			if !strings.Contains(body, commentMarker) || !strings.Contains(body, summaryHeading) {
+			if !strings.Contains(body, commentMarker) || !strings.Contains(body, "**SonarQube summary**") {
 				t.Fatalf("unexpected created body: %q", body)
 			}
 			createCalls++
@@ -293,11 +829,11 @@ func TestUpsertSummaryNoteCreatesOnFirstRun(t *testing.T) {
 	defer server.Close()
 
 	client := gitlab.NewClient(server.URL, "secret-token", server.Client())
-	updated, err := upsertSummaryNote(context.Background(), client, 100, 42, commentMarker+"\n"+summaryHeading+"\nnew")
+	result, err := upsertSummaryNote(context.Background(), client, 100, 42, nil, []string{commentMarker + "\n" + "**SonarQube summary**" + "\nnew"})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if updated {
+	if result.updated || result.created != 1 {
 		t.Fatal("expected create path, got update")
 	}
 	if createCalls != 1 {
@@ -308,14 +844,14 @@ func TestUpsertSummaryNoteCreatesOnFirstRun(t *testing.T) {
 func TestUpsertSummaryNoteUpdatesExistingSummary(t *testing.T) {
 	t.Parallel()
 
+	notes := []gitlab.MergeRequestNote{
+		{ID: 10, Body: "plain note"},
+		{ID: 11, Body: commentMarker + "\n" + "**SonarQube summary**" + "\nold"},
+	}
+
 	updateCalls := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch {
-		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
-			_, _ = w.Write([]byte(`[
-				{"id":10,"body":"plain note"},
-				{"id":11,"body":"` + commentMarker + `\n` + summaryHeading + `\nold"}
-			]`))
 		case r.Method == http.MethodPut && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes/11":
 			if err := r.ParseForm(); err != nil {
 				t.Fatalf("failed to parse form: %v", err)
@@ -332,11 +868,11 @@ func TestUpsertSummaryNoteUpdatesExistingSummary(t *testing.T) {
 	defer server.Close()
 
 	client := gitlab.NewClient(server.URL, "secret-token", server.Client())
-	updated, err := upsertSummaryNote(context.Background(), client, 100, 42, commentMarker+"\n"+summaryHeading+"\nfresh summary")
+	result, err := upsertSummaryNote(context.Background(), client, 100, 42, notes, []string{commentMarker + "\n" + "**SonarQube summary**" + "\nfresh summary"})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if !updated {
+	if !result.updated {
 		t.Fatal("expected update path")
 	}
 	if updateCalls != 1 {
@@ -344,39 +880,122 @@ func TestUpsertSummaryNoteUpdatesExistingSummary(t *testing.T) {
 	}
 }
 
-func TestRunWithHelpReturnsSuccessAndWritesDocumentation(t *testing.T) {
+func TestUpsertSummaryNoteSyncsContinuationNotes(t *testing.T) {
 	t.Parallel()
 
-	var output bytes.Buffer
-	err := runWith(
-		[]string{"--help"},
-		func(string) string { return "" },
-		&output,
-	)
+	notes := []gitlab.MergeRequestNote{
+		{ID: 11, Body: commentMarker + "\n**SonarQube summary**\nold"},
+		{ID: 12, Body: commentMarker + "\n<!-- sonar-gitlab-commenter:summary-part 2 -->\n**SonarQube summary (continued, part 2)**\nold"},
+		{ID: 13, Body: commentMarker + "\n<!-- sonar-gitlab-commenter:summary-part 3 -->\n**SonarQube summary (continued, part 3)**\nold"},
+		{ID: 14, Body: commentMarker + "\n<!-- sonar-gitlab-commenter:summary-part 4 -->\n**SonarQube summary (continued, part 4)**\nold"},
+	}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := gitlab.NewClient(server.URL, "secret-token", server.Client())
+	result, err := upsertSummaryNote(context.Background(), client, 100, 42, notes, []string{"main", "part 2"})
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
-	helpText := output.String()
-	for _, expected := range []string{"--sonar-url", "--dry-run", "SONAR_HOST_URL", "CI_PROJECT_ID"} {
-		if !strings.Contains(helpText, expected) {
-			t.Fatalf("help output %q does not contain %q", helpText, expected)
-		}
+	expected := []string{
+		"PUT /api/v4/projects/100/merge_requests/42/notes/11",
+		"PUT /api/v4/projects/100/merge_requests/42/notes/12",
+		"DELETE /api/v4/projects/100/merge_requests/42/notes/13",
+		"DELETE /api/v4/projects/100/merge_requests/42/notes/14",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("unexpected requests: %v", requests)
+	}
+	if !result.updated || result.created != 0 || result.deleted != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	requests = nil
+	result, err = upsertSummaryNote(context.Background(), client, 100, 42, notes[:1], []string{"main", "part 2", "part 3"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(requests) != 3 || requests[1] != "POST /api/v4/projects/100/merge_requests/42/notes" || result.created != 2 {
+		t.Fatalf("expected continuation notes to be created, got %v (%+v)", requests, result)
 	}
 }
 
-func TestRunWithDryRunSkipsGitLabWriteOperations(t *testing.T) {
+func TestFindLatestSummaryNoteIgnoresContinuationNotes(t *testing.T) {
 	t.Parallel()
 
-	var writeCalls int32
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch {
-		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
-			w.Header().Set("Content-Type", "application/json")
-			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
-		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
-			w.Header().Set("Content-Type", "application/json")
-			_, _ = w.Write([]byte(`{
+	note, found := findLatestSummaryNote([]gitlab.MergeRequestNote{
+		{ID: 1, Body: commentMarker + "\n**SonarQube summary**"},
+		{ID: 2, Body: commentMarker + "\n<!-- sonar-gitlab-commenter:summary-part 2 -->\n**SonarQube summary (continued, part 2)**"},
+	})
+	if !found || note.ID != 1 {
+		t.Fatalf("expected main summary note, got %+v (found=%v)", note, found)
+	}
+}
+
+func TestFormatMergeRequestSummaryCommentCompactsLongSummary(t *testing.T) {
+	t.Parallel()
+
+	longMessage := strings.Repeat("x", 500)
+	projectLevel := []sonar.Issue{{Key: "P", Severity: "MAJOR", Type: "BUG", Message: longMessage, Rule: "go:S1", FilePath: "a.go"}}
+
+	templates := defaultCommentTemplates()
+	full, err := formatMergeRequestSummaryComment(templates, commentLinks{}, sonar.QualityReport{}, projectLevel, projectLevel, nil, sonar.TaxonomyLegacy, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertCommentContains(t, full, longMessage)
+
+	templates.noteLimit = noteLength(full) - 1
+	compact, err := formatMergeRequestSummaryComment(templates, commentLinks{}, sonar.QualityReport{}, projectLevel, projectLevel, nil, sonar.TaxonomyLegacy, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if strings.Contains(compact, longMessage) {
+		t.Fatalf("expected long message to be truncated, got %q", compact)
+	}
+	assertCommentContains(t, compact, strings.Repeat("x", compactMessageLength-1)+"…")
+	assertCommentContains(t, compact, "<summary>1 files with issues</summary>")
+}
+
+func TestRunWithHelpReturnsSuccessAndWritesDocumentation(t *testing.T) {
+	t.Parallel()
+
+	var output bytes.Buffer
+	err := runWith(
+		[]string{"--help"},
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	helpText := output.String()
+	for _, expected := range []string{"--sonar-url", "--dry-run", "SONAR_HOST_URL", "CI_PROJECT_ID"} {
+		if !strings.Contains(helpText, expected) {
+			t.Fatalf("help output %q does not contain %q", helpText, expected)
+		}
+	}
+}
+
+func TestRunWithDryRunSkipsGitLabWriteOperations(t *testing.T) {
+	t.Parallel()
+
+	var writeCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{
 				"changes":[
 					{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,1 @@\n+added line"}
 				]
@@ -384,6 +1003,8 @@ func TestRunWithDryRunSkipsGitLabWriteOperations(t *testing.T) {
 		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
 		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{
@@ -460,6 +1081,8 @@ func TestRunWithLogsFlagPrintsFetchedSonarIssues(t *testing.T) {
 		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
 		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{
@@ -506,6 +1129,9 @@ func TestRunWithLogsFlagPrintsFetchedSonarIssues(t *testing.T) {
 		`Sonar issue #1: key="ISSUE-LOG-1"`,
 		`severity="MAJOR"`,
 		`type="CODE_SMELL"`,
+		"Timing breakdown:",
+		"sonar: issues:",
+		"gitlab: merge request changes:",
 	} {
 		if !strings.Contains(logOutput, expected) {
 			t.Fatalf("output %q does not contain %q", logOutput, expected)
@@ -513,10 +1139,9 @@ func TestRunWithLogsFlagPrintsFetchedSonarIssues(t *testing.T) {
 	}
 }
 
-func TestRunWithInlineInvalidPositionFallsBackToSummary(t *testing.T) {
+func TestRunWithImpactSeverityThresholdOnCleanCodeServer(t *testing.T) {
 	t.Parallel()
 
-	summaryNotesCreated := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
@@ -526,19 +1151,24 @@ func TestRunWithInlineInvalidPositionFallsBackToSummary(t *testing.T) {
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{
 				"changes":[
-					{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,1 @@\n+added line"}
+					{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}
 				]
 			}`))
 		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`10.4.1.88267`))
 		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{
 				"issues":[
-					{"key":"ISSUE-LINE-CODE","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"inline issue","component":"project:main.go","line":12}
+					{"key":"ISSUE-HIGH","rule":"go:S100","type":"BUG","severity":"MAJOR","message":"high impact","component":"project:main.go","line":12,
+					 "impacts":[{"softwareQuality":"RELIABILITY","severity":"HIGH"}]},
+					{"key":"ISSUE-LOW","rule":"go:S200","type":"CODE_SMELL","severity":"MAJOR","message":"low impact","component":"project:main.go","line":13,
+					 "impacts":[{"softwareQuality":"MAINTAINABILITY","severity":"LOW"}]}
 				],
-				"paging":{"pageIndex":1,"pageSize":500,"total":1}
+				"paging":{"pageIndex":1,"pageSize":500,"total":2}
 			}`))
 		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
 			w.Header().Set("Content-Type", "application/json")
@@ -546,33 +1176,212 @@ func TestRunWithInlineInvalidPositionFallsBackToSummary(t *testing.T) {
 		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
-		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
-			w.Header().Set("Content-Type", "application/json")
-			_, _ = w.Write([]byte(`[]`))
-		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
-			http.Error(
-				w,
-				`{"message":"400 Bad request - Note {:line_code=>[\"can't be blank\", \"must be a valid line code\"]}"}`,
-				http.StatusBadRequest,
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		[]string{
+			"--sonar-url=" + server.URL,
+			"--sonar-token=token",
+			"--sonar-project-key=project",
+			"--gitlab-url=" + server.URL,
+			"--gitlab-token=token",
+			"--project-id=100",
+			"--mr-iid=42",
+			"--dry-run",
+			"--impact-severity-threshold=HIGH",
+		},
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	logOutput := output.String()
+	if !strings.Contains(logOutput, "Action log: found 1 issues, published 0 comments") {
+		t.Fatalf("output %q does not contain impact-filtered issue count", logOutput)
+	}
+}
+
+func TestRunWithUnavailableServerVersionFallsBackToLegacyTaxonomy(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,1 @@\n+added line"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			http.Error(w, "forbidden", http.StatusForbidden)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			_, _ = w.Write([]byte(`{"issues":[{"key":"ISSUE-1","rule":"go:S100","type":"BUG","severity":"MAJOR","message":"first","component":"project:main.go","line":12,` +
+				`"impacts":[{"softwareQuality":"RELIABILITY","severity":"LOW"}]}],"paging":{"pageIndex":1,"pageSize":500,"total":1}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		[]string{
+			"--sonar-url=" + server.URL,
+			"--sonar-token=token",
+			"--sonar-project-key=project",
+			"--gitlab-url=" + server.URL,
+			"--gitlab-token=token",
+			"--project-id=100",
+			"--mr-iid=42",
+			"--dry-run",
+			"--impact-severity-threshold=HIGH",
+		},
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	logOutput := output.String()
+	for _, expected := range []string{
+		"SonarQube server version unavailable, using the legacy taxonomy:",
+		"Impact severity threshold ignored",
+		"Action log: found 1 issues, published 0 comments",
+	} {
+		if !strings.Contains(logOutput, expected) {
+			t.Fatalf("output %q does not contain %q", logOutput, expected)
+		}
+	}
+}
+
+func TestRunWithFetchTimeoutCoversNarrowedIssueSearch(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		fetchTimeout string
+		err          string
+	}{
+		{name: "completes within the fetch timeout", fetchTimeout: "5s"},
+		{name: "stops at the fetch timeout", fetchTimeout: "50ms", err: "read phase exceeded --fetch-timeout=50ms"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+					_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+					_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,1 @@\n+added line"}]}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+					_, _ = w.Write([]byte(`{"valid":true}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+					_, _ = w.Write([]byte(`9.9.4.87374`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+					// The project query exceeds the search cap, so the issues are
+					// read per type, each in a slow request.
+					issueType := r.URL.Query().Get("types")
+					if issueType == "" {
+						_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":500,"total":15000}}`))
+						return
+					}
+					select {
+					case <-time.After(30 * time.Millisecond):
+					case <-r.Context().Done():
+						return
+					}
+					_, _ = w.Write([]byte(`{"issues":[{"key":"ISSUE-` + issueType + `","rule":"go:S100","type":"` + issueType +
+						`","severity":"MAJOR","message":"issue","component":"project:main.go","line":12}],"paging":{"pageIndex":1,"pageSize":500,"total":1}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+					_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+					_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+					http.Error(w, "unexpected", http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			var output bytes.Buffer
+			err := runWith(
+				[]string{
+					"--sonar-url=" + server.URL,
+					"--sonar-token=token",
+					"--sonar-project-key=project",
+					"--gitlab-url=" + server.URL,
+					"--gitlab-token=token",
+					"--project-id=100",
+					"--mr-iid=42",
+					"--dry-run",
+					"--fetch-timeout=" + tc.fetchTimeout,
+				},
+				func(string) string { return "" },
+				&output,
 			)
-		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
-			w.Header().Set("Content-Type", "application/json")
-			_, _ = w.Write([]byte(`[]`))
-		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
-			if err := r.ParseForm(); err != nil {
-				t.Fatalf("failed to parse form: %v", err)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing %q, got %v", tc.err, err)
+				}
+				return
 			}
-			body := r.PostForm.Get("body")
-			if !strings.Contains(body, "without line binding") {
-				t.Fatalf("expected summary to contain project-level section, got %q", body)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
 			}
-			if !strings.Contains(body, "inline issue") {
-				t.Fatalf("expected summary to include skipped issue message, got %q", body)
+			if !strings.Contains(output.String(), "Action log: found 3 issues") {
+				t.Fatalf("expected every narrowed issue in the output, got %q", output.String())
 			}
-			summaryNotesCreated++
-			w.WriteHeader(http.StatusCreated)
+		})
+	}
+}
+
+func TestRunWithResolvedSonarIssuesNotReported(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			// Like SonarQube, the search returns resolved issues unless the
+			// query asks for unresolved ones only.
+			issues := `{"key":"ISSUE-OPEN","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"open","component":"project:main.go","line":12,"status":"OPEN"}`
+			total := 1
+			if r.URL.Query().Get("resolved") != "false" {
+				issues += `,{"key":"ISSUE-FP","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"false positive","component":"project:main.go","line":13,` +
+					`"status":"RESOLVED","resolution":"FALSE-POSITIVE"}`
+				total = 2
+			}
+			_, _ = w.Write([]byte(`{"issues":[` + issues + `],"paging":{"pageIndex":1,"pageSize":500,"total":` + strconv.Itoa(total) + `}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
 		default:
-			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
 		}
 	}))
 	defer server.Close()
@@ -587,6 +1396,7 @@ func TestRunWithInlineInvalidPositionFallsBackToSummary(t *testing.T) {
 			"--gitlab-token=token",
 			"--project-id=100",
 			"--mr-iid=42",
+			"--dry-run",
 		},
 		func(string) string { return "" },
 		&output,
@@ -594,14 +1404,83 @@ func TestRunWithInlineInvalidPositionFallsBackToSummary(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
-	if summaryNotesCreated != 1 {
-		t.Fatalf("expected one summary note create, got %d", summaryNotesCreated)
+	assertCommentContains(t, output.String(), "Action log: found 1 issues, published 0 comments")
+}
+
+func TestRunWithDiffIssueScopeQueriesChangedFiles(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{
+				"changes":[
+					{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,1 @@\n+added line"}
+				]
+			}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("componentKeys") == "project" {
+				_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":1,"total":1200}}`))
+				return
+			}
+			if got := r.URL.Query().Get("componentKeys"); got != "project:main.go" {
+				t.Fatalf("unexpected componentKeys query: %q", got)
+			}
+			_, _ = w.Write([]byte(`{
+				"issues":[
+					{"key":"ISSUE-1","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"inline issue","component":"project:main.go","line":12}
+				],
+				"paging":{"pageIndex":1,"pageSize":500,"total":1}
+			}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		[]string{
+			"--sonar-url=" + server.URL,
+			"--sonar-token=token",
+			"--sonar-project-key=project",
+			"--gitlab-url=" + server.URL,
+			"--gitlab-token=token",
+			"--project-id=100",
+			"--mr-iid=42",
+			"--dry-run",
+			"--logs",
+			"--issue-scope=diff",
+		},
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
 	}
 
 	logOutput := output.String()
 	for _, expected := range []string{
-		"Action log: found 1 issues, published 1 comments",
-		"Posted 0 inline SonarQube discussions to merge request 42",
+		"Fetched SonarQube issues for 1 MR files in",
+		"Full-project fetch estimate: issues=1200 requests=3",
+		"estimated time saved ~",
+		"Action log: found 1 issues, published 0 comments",
 	} {
 		if !strings.Contains(logOutput, expected) {
 			t.Fatalf("output %q does not contain %q", logOutput, expected)
@@ -609,6 +1488,657 @@ func TestRunWithInlineInvalidPositionFallsBackToSummary(t *testing.T) {
 	}
 }
 
+func TestRunWithInlineInvalidPositionFallsBackToSummary(t *testing.T) {
+	t.Parallel()
+
+	summaryNotesCreated := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{
+				"changes":[
+					{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,1 @@\n+added line"}
+				]
+			}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{
+				"issues":[
+					{"key":"ISSUE-LINE-CODE","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"inline issue","component":"project:main.go","line":12}
+				],
+				"paging":{"pageIndex":1,"pageSize":500,"total":1}
+			}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			http.Error(
+				w,
+				`{"message":"400 Bad request - Note {:line_code=>[\"can't be blank\", \"must be a valid line code\"]}"}`,
+				http.StatusBadRequest,
+			)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			if err := r.ParseForm(); err != nil {
+				t.Fatalf("failed to parse form: %v", err)
+			}
+			body := r.PostForm.Get("body")
+			if !strings.Contains(body, "without line binding") {
+				t.Fatalf("expected summary to contain project-level section, got %q", body)
+			}
+			if !strings.Contains(body, "inline issue") {
+				t.Fatalf("expected summary to include skipped issue message, got %q", body)
+			}
+			summaryNotesCreated++
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		[]string{
+			"--sonar-url=" + server.URL,
+			"--sonar-token=token",
+			"--sonar-project-key=project",
+			"--gitlab-url=" + server.URL,
+			"--gitlab-token=token",
+			"--project-id=100",
+			"--mr-iid=42",
+		},
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if summaryNotesCreated != 1 {
+		t.Fatalf("expected one summary note create, got %d", summaryNotesCreated)
+	}
+
+	logOutput := output.String()
+	for _, expected := range []string{
+		"Action log: found 1 issues, published 1 comments",
+		"Posted 0 inline SonarQube discussions to merge request 42",
+	} {
+		if !strings.Contains(logOutput, expected) {
+			t.Fatalf("output %q does not contain %q", logOutput, expected)
+		}
+	}
+}
+
+func TestRunWithWriteTimeoutSkipsRemainingInlinePosts(t *testing.T) {
+	t.Parallel()
+
+	var (
+		discussionPosts int32
+		summaryBody     string
+	)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,4 @@\n+one\n+two\n+three\n+four"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			_, _ = w.Write([]byte(`{"issues":[` +
+				`{"key":"ISSUE-1","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"issue one","component":"project:main.go","line":12},` +
+				`{"key":"ISSUE-2","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"issue two","component":"project:main.go","line":13},` +
+				`{"key":"ISSUE-3","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"issue three","component":"project:main.go","line":14},` +
+				`{"key":"ISSUE-4","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"issue four","component":"project:main.go","line":15}` +
+				`],"paging":{"pageIndex":1,"pageSize":500,"total":4}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			// Only the first post gets an answer; the next one hangs past the
+			// write timeout.
+			if atomic.AddInt32(&discussionPosts, 1) > 1 {
+				<-release
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("failed to parse form: %v", err)
+			}
+			summaryBody = r.PostForm.Get("body")
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var output bytes.Buffer
+	err := runWith(
+		[]string{
+			"--sonar-url=" + server.URL,
+			"--sonar-token=token",
+			"--sonar-project-key=project",
+			"--gitlab-url=" + server.URL,
+			"--gitlab-token=token",
+			"--project-id=100",
+			"--mr-iid=42",
+			"--write-timeout=150ms",
+		},
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	logOutput := output.String()
+	for _, expected := range []string{
+		"Posted 1 inline SonarQube discussions to merge request 42",
+		"Skipped 3 inline SonarQube discussions after --write-timeout=150ms; their issues are listed in the summary",
+	} {
+		if !strings.Contains(logOutput, expected) {
+			t.Fatalf("output %q does not contain %q", logOutput, expected)
+		}
+	}
+	for _, message := range []string{"issue two", "issue three", "issue four"} {
+		if !strings.Contains(summaryBody, message) {
+			t.Fatalf("expected summary to list skipped issue %q, got %q", message, summaryBody)
+		}
+	}
+}
+
+func TestRunWithDraftNotesPublishesOnce(t *testing.T) {
+	t.Parallel()
+
+	var draftCreates, publishCalls, deleteCalls int32
+	server := newDraftNotesTestServer(t, &draftCreates, &publishCalls, &deleteCalls, http.StatusNoContent)
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(draftNotesTestArgs(server.URL), func(string) string { return "" }, &output)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if draftCreates != 2 || publishCalls != 1 || deleteCalls != 0 {
+		t.Fatalf("unexpected calls: drafts=%d publish=%d delete=%d", draftCreates, publishCalls, deleteCalls)
+	}
+	if !strings.Contains(output.String(), "Posted 2 inline SonarQube discussions to merge request 42") {
+		t.Fatalf("unexpected output %q", output.String())
+	}
+}
+
+func TestRunWithDraftNotesCleansUpWhenPublishFails(t *testing.T) {
+	t.Parallel()
+
+	var draftCreates, publishCalls, deleteCalls int32
+	server := newDraftNotesTestServer(t, &draftCreates, &publishCalls, &deleteCalls, http.StatusInternalServerError)
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(draftNotesTestArgs(server.URL), func(string) string { return "" }, &output)
+	if err == nil || !strings.Contains(err.Error(), "failed to publish inline draft notes") {
+		t.Fatalf("expected publish error, got %v", err)
+	}
+
+	if draftCreates != 2 || publishCalls != 1 || deleteCalls != 2 {
+		t.Fatalf("unexpected calls: drafts=%d publish=%d delete=%d", draftCreates, publishCalls, deleteCalls)
+	}
+}
+
+func TestRunWithDraftNotesPublishesOnlyOwnDraftsNextToPendingOnes(t *testing.T) {
+	t.Parallel()
+
+	var draftCreates, publishCalls, deleteCalls int32
+	server := newDraftNotesTestServerWithPending(t, `[{"id":99,"note":"unfinished review"}]`, &draftCreates, &publishCalls, &deleteCalls, http.StatusNoContent)
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(draftNotesTestArgs(server.URL), func(string) string { return "" }, &output)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Each of the two drafts is published on its own; the pending draft 99 is
+	// left alone.
+	if draftCreates != 2 || publishCalls != 2 || deleteCalls != 0 {
+		t.Fatalf("unexpected calls: drafts=%d publish=%d delete=%d", draftCreates, publishCalls, deleteCalls)
+	}
+}
+
+func TestRunWithMaxInlineCommentsDemotesToSummary(t *testing.T) {
+	t.Parallel()
+
+	var draftCreates, publishCalls, deleteCalls int32
+	server := newDraftNotesTestServer(t, &draftCreates, &publishCalls, &deleteCalls, http.StatusNoContent)
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		append(draftNotesTestArgs(server.URL), "--max-inline-comments=1"),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if draftCreates != 1 {
+		t.Fatalf("expected one inline draft, got %d", draftCreates)
+	}
+	for _, expected := range []string{
+		"Posted 1 inline SonarQube discussions to merge request 42",
+		"Demoted 1 inline SonarQube discussions to the summary: 0 over --max-comments-per-file=0, 1 over --max-inline-comments=1",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Fatalf("output %q does not contain %q", output.String(), expected)
+		}
+	}
+}
+
+func TestRunWithCompareTargetBranchSkipsPreexistingIssues(t *testing.T) {
+	t.Parallel()
+
+	var draftCreates, publishCalls, deleteCalls int32
+	server := newDraftNotesTestServer(t, &draftCreates, &publishCalls, &deleteCalls, http.StatusNoContent)
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		append(draftNotesTestArgs(server.URL), "--compare-target-branch", "--target-branch=main", "--logs"),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if draftCreates != 0 {
+		t.Fatalf("expected no inline drafts for pre-existing issues, got %d", draftCreates)
+	}
+	assertCommentContains(t, output.String(), "Compared with main (2 issues): 0 introduced by the MR, 2 pre-existing in touched lines")
+}
+
+func TestRunWithCompareTargetBranchKeepsDiscussionsOfPreexistingIssuesOpen(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		writes []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[` +
+				`{"id":"d1","resolvable":true,"notes":[{"id":1,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-1 -->"}]},` +
+				`{"id":"d2","resolvable":true,"notes":[{"id":2,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-9 -->"}]}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPost || r.Method == http.MethodPut:
+			writes = append(writes, r.Method+" "+r.URL.Path)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			_, _ = w.Write([]byte(`{"issues":[{"key":"ISSUE-1","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"first","component":"project:main.go","line":12}],` +
+				`"paging":{"pageIndex":1,"pageSize":500,"total":1}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		append(draftNotesTestArgs(server.URL), "--compare-target-branch", "--target-branch=main"),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// ISSUE-1 is pre-existing on main and still reported, so only the discussion
+	// of the fixed ISSUE-9 gets a reply and is resolved.
+	expected := []string{
+		"POST /api/v4/projects/100/merge_requests/42/discussions/d2/notes",
+		"PUT /api/v4/projects/100/merge_requests/42/discussions/d2",
+	}
+	if !reflect.DeepEqual(writes, expected) {
+		t.Fatalf("expected writes %q, got %q", expected, writes)
+	}
+}
+
+func TestFormatMergeRequestSummaryCommentBaselineSection(t *testing.T) {
+	t.Parallel()
+
+	introduced := []sonar.Issue{{Key: "NEW", Severity: "MAJOR", FilePath: "main.go", Line: 3}}
+	baseline := &baselineComparison{
+		target: "main",
+		preexisting: []sonar.Issue{
+			{Key: "OLD-2", Severity: "MINOR", Type: "CODE_SMELL", Message: "later", Rule: "go:S2", FilePath: "main.go", Line: 9},
+			{Key: "OLD-1", Severity: "MAJOR", Type: "BUG", Message: "earlier", Rule: "go:S1", FilePath: "main.go", Line: 4},
+		},
+	}
+
+	comment, err := formatMergeRequestSummaryComment(defaultCommentTemplates(), commentLinks{}, sonar.QualityReport{}, introduced, nil, nil, sonar.TaxonomyLegacy, nil, baseline, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	assertCommentContains(t, comment, "- New in this MR: 1\n- Pre-existing in touched lines (already in `main`): 2")
+	assertCommentContains(t, comment, "<summary>2 pre-existing issues in touched lines</summary>\n\n- main.go:4 [MAJOR][BUG] earlier (rule `go:S1`)\n- main.go:9 [MINOR][CODE_SMELL] later (rule `go:S2`)\n\n</details>")
+}
+
+func TestRunWithInvalidTemplateFailsBeforeAPICalls(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	templatePath := writeTemplateFile(t, t.TempDir(), "summary.tmpl", "{{ .Unknown }}")
+	var output bytes.Buffer
+	err := runWith(
+		append(draftNotesTestArgs(server.URL), "--summary-template="+templatePath),
+		func(string) string { return "" },
+		&output,
+	)
+	if err == nil || !strings.Contains(err.Error(), "failed to render summary comment template") {
+		t.Fatalf("expected template validation error, got %v", err)
+	}
+}
+
+func TestRunWithRussianLocaleLocalizesActionLog(t *testing.T) {
+	t.Parallel()
+
+	var draftCreates, publishCalls, deleteCalls int32
+	server := newDraftNotesTestServer(t, &draftCreates, &publishCalls, &deleteCalls, http.StatusNoContent)
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		append(draftNotesTestArgs(server.URL), "--locale=ru"),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, expected := range []string{
+		"Журнал действий: найдено проблем 2, опубликовано комментариев 3",
+		"Опубликовано inline-дискуссий SonarQube: 2 в merge request 42",
+		"Summary-комментарий SonarQube опубликован в merge request 42",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Fatalf("output %q does not contain %q", output.String(), expected)
+		}
+	}
+}
+
+func TestRunWithReopenPolicyReopensThreadsResolvedByPeople(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		reopens []string
+		drafts  []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/user":
+			_, _ = w.Write([]byte(`{"id":1,"username":"bot"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[{"id":"d1","resolved":true,"resolvable":true,"notes":[` +
+				`{"id":1,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-1 -->","author":{"id":1,"username":"bot"},"resolved_by":{"id":2,"username":"alice"}}]}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions/d1":
+			reopens = append(reopens, r.PostForm.Get("resolved"))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			drafts = append(drafts, r.PostForm.Get("note"))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes/bulk_publish":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			_, _ = w.Write([]byte(`{
+				"issues":[
+					{"key":"ISSUE-1","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"first","component":"project:main.go","line":12},
+					{"key":"ISSUE-2","rule":"go:S200","type":"BUG","severity":"MAJOR","message":"second","component":"project:main.go","line":13}
+				],
+				"paging":{"pageIndex":1,"pageSize":500,"total":2}
+			}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		append(draftNotesTestArgs(server.URL), "--resolve-policy=reopen"),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(reopens, []string{"false"}) {
+		t.Fatalf("expected d1 to be reopened once, got %q", reopens)
+	}
+	if len(drafts) != 1 || !strings.Contains(drafts[0], "ISSUE-2") {
+		t.Fatalf("expected only ISSUE-2 to be posted, got %q", drafts)
+	}
+	assertCommentContains(t, output.String(), "Reopened 1 SonarQube discussions resolved by reviewers while their issues are still reported")
+}
+
+func draftNotesTestArgs(serverURL string) []string {
+	return []string{
+		"--sonar-url=" + serverURL,
+		"--sonar-token=token",
+		"--sonar-project-key=project",
+		"--gitlab-url=" + serverURL,
+		"--gitlab-token=token",
+		"--project-id=100",
+		"--mr-iid=42",
+		"--draft-notes",
+	}
+}
+
+func newDraftNotesTestServer(
+	t *testing.T,
+	draftCreates *int32,
+	publishCalls *int32,
+	deleteCalls *int32,
+	publishStatus int,
+) *httptest.Server {
+	t.Helper()
+
+	return newDraftNotesTestServerWithPending(t, `[]`, draftCreates, publishCalls, deleteCalls, publishStatus)
+}
+
+// newDraftNotesTestServerWithPending also lists the JSON drafts in pending, on
+// top of the ones created by the run, as pending drafts of the token user.
+func newDraftNotesTestServerWithPending(
+	t *testing.T,
+	pending string,
+	draftCreates *int32,
+	publishCalls *int32,
+	deleteCalls *int32,
+	publishStatus int,
+) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{
+				"changes":[
+					{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}
+				]
+			}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{
+				"issues":[
+					{"key":"ISSUE-1","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"first","component":"project:main.go","line":12},
+					{"key":"ISSUE-2","rule":"go:S200","type":"BUG","severity":"MAJOR","message":"second","component":"project:main.go","line":13}
+				],
+				"paging":{"pageIndex":1,"pageSize":500,"total":2}
+			}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			_, _ = w.Write([]byte(pending))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			id := atomic.AddInt32(draftCreates, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":` + strconv.Itoa(int(id)) + `}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes/bulk_publish":
+			atomic.AddInt32(publishCalls, 1)
+			w.WriteHeader(publishStatus)
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/publish"):
+			atomic.AddInt32(publishCalls, 1)
+			w.WriteHeader(publishStatus)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/draft_notes/"):
+			atomic.AddInt32(deleteCalls, 1)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+}
+
+func renderInlineDiscussion(t *testing.T, issues ...sonar.Issue) string {
+	t.Helper()
+
+	comment, err := formatInlineDiscussion(defaultCommentTemplates(), commentLinks{}, issues)
+	if err != nil {
+		t.Fatalf("failed to render inline discussion: %v", err)
+	}
+
+	return comment
+}
+
+func renderSummaryComment(
+	t *testing.T,
+	qualityReport sonar.QualityReport,
+	issues []sonar.Issue,
+	projectLevelIssues []sonar.Issue,
+	overflow []overflowIssue,
+	taxonomy sonar.Taxonomy,
+) string {
+	t.Helper()
+
+	comment, err := formatMergeRequestSummaryComment(
+		defaultCommentTemplates(),
+		commentLinks{},
+		qualityReport,
+		issues,
+		projectLevelIssues,
+		overflow,
+		taxonomy,
+		nil,
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("failed to render summary comment: %v", err)
+	}
+
+	return comment
+}
+
 func assertCommentContains(t *testing.T, comment, expected string) {
 	t.Helper()
 
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// mirroredMarkerPrefix starts the hidden marker that lists, in the first tool note
+// of a discussion, the IDs of replies already copied to SonarQube.
+const mirroredMarkerPrefix = "<!-- sonar-mirrored: "
+
+var mirroredMarkerRegex = regexp.MustCompile(`<!-- sonar-mirrored: ([0-9,]*) -->`)
+
+// replyMirror is the set of new human replies of one tool discussion.
+type replyMirror struct {
+	toolNote gitlab.DiscussionNote
+	keys     []string
+	replies  []gitlab.DiscussionNote
+	mirrored []int
+}
+
+// planReplyMirrors finds human replies in tool discussions that are not yet
+// listed in the mirrored marker. /sonar commands are skipped: their reasons
+// already reach SonarQube. Only keys in openKeys receive comments.
+func planReplyMirrors(discussions []gitlab.Discussion, openKeys map[string]bool, bot gitlab.User) []replyMirror {
+	var mirrors []replyMirror
+	for _, discussion := range discussions {
+		toolIndex := slices.IndexFunc(discussion.Notes, func(note gitlab.DiscussionNote) bool {
+			return commentHasMarker(note.Body)
+		})
+		if toolIndex < 0 || discussion.Notes[toolIndex].ID <= 0 {
+			continue
+		}
+
+		mirror := replyMirror{
+			toolNote: discussion.Notes[toolIndex],
+			mirrored: mirroredNoteIDs(discussion.Notes[toolIndex].Body),
+		}
+		for _, key := range discussionIssueKeys(discussion) {
+			if openKeys[key] {
+				mirror.keys = append(mirror.keys, key)
+			}
+		}
+		if len(mirror.keys) == 0 {
+			continue
+		}
+
+		for _, note := range discussion.Notes {
+			if note.ID <= 0 || note.Author.ID == bot.ID || commentHasMarker(note.Body) || slices.Contains(mirror.mirrored, note.ID) {
+				continue
+			}
+			if _, command := parseSonarCommand(note.Body); command || strings.TrimSpace(note.Body) == "" {
+				continue
+			}
+			mirror.replies = append(mirror.replies, note)
+		}
+		if len(mirror.replies) > 0 {
+			mirrors = append(mirrors, mirror)
+		}
+	}
+
+	return mirrors
+}
+
+// mirrorReplies adds every planned reply as a comment to the issues of its
+// discussion and then lists the reply IDs in the tool note marker. Replies
+// mirrored before a failure are still recorded, so reruns do not duplicate them.
+func mirrorReplies(
+	ctx context.Context,
+	gitlabClient *gitlab.Client,
+	sonarClient *sonar.Client,
+	cfg config.Config,
+	catalog i18n.Catalog,
+	mergeRequestURL string,
+	mirrors []replyMirror,
+) (int, error) {
+	count := 0
+	for _, mirror := range mirrors {
+		mirrored := mirror.mirrored
+		var mirrorErr error
+		for _, reply := range mirror.replies {
+			text := formatMirroredReply(catalog, cfg.GitLabMRIID, mergeRequestURL, reply)
+			for _, key := range mirror.keys {
+				if mirrorErr = sonarClient.AddComment(ctx, key, text); mirrorErr != nil {
+					break
+				}
+			}
+			if mirrorErr != nil {
+				mirrorErr = fmt.Errorf("failed to copy GitLab note %d to SonarQube: %w", reply.ID, mirrorErr)
+				break
+			}
+			mirrored = append(mirrored, reply.ID)
+			count++
+		}
+
+		if len(mirrored) > len(mirror.mirrored) {
+			body := withMirroredMarker(mirror.toolNote.Body, mirrored)
+			if err := gitlabClient.UpdateMergeRequestNote(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID, mirror.toolNote.ID, body); err != nil {
+				return count, wrapGitLabError(err, "failed to record mirrored replies")
+			}
+		}
+		if mirrorErr != nil {
+			return count, mirrorErr
+		}
+	}
+
+	return count, nil
+}
+
+func formatMirroredReply(catalog i18n.Catalog, mrIID int, mergeRequestURL string, reply gitlab.DiscussionNote) string {
+	source := fmt.Sprintf(catalog.MirroredReplySource, mrIID)
+	if mergeRequestURL != "" {
+		source = fmt.Sprintf("[%s](%s#note_%d)", source, mergeRequestURL, reply.ID)
+	}
+
+	return strings.TrimSpace(reply.Body) + "\n\n— @" + reply.Author.Username + ", " + source
+}
+
+func mirroredNoteIDs(body string) []int {
+	match := mirroredMarkerRegex.FindStringSubmatch(body)
+	if match == nil {
+		return nil
+	}
+
+	var ids []int
+	for _, value := range strings.Split(match[1], ",") {
+		if id, err := strconv.Atoi(value); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// withMirroredMarker replaces the mirrored marker of a tool note or appends one.
+func withMirroredMarker(body string, ids []int) string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.Itoa(id))
+	}
+	marker := mirroredMarkerPrefix + strings.Join(values, ",") + " -->"
+
+	if mirroredMarkerRegex.MatchString(body) {
+		return mirroredMarkerRegex.ReplaceAllLiteralString(body, marker)
+	}
+
+	return strings.TrimRight(body, "\n") + "\n" + marker
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/gitlab"
+)
+
+func TestPlanReplyMirrorsSkipsMirroredBotAndCommandNotes(t *testing.T) {
+	t.Parallel()
+
+	bot := gitlab.User{ID: 1, Username: "bot"}
+	alice := gitlab.User{ID: 2, Username: "alice"}
+	toolNote := gitlab.DiscussionNote{
+		ID:     10,
+		Body:   commentMarker + "\n" + issueKeysMarkerPrefix + "A,GONE -->\n" + mirroredMarkerPrefix + "11 -->",
+		Author: bot,
+	}
+	discussions := []gitlab.Discussion{
+		{ID: "d1", Notes: []gitlab.DiscussionNote{
+			toolNote,
+			{ID: 11, Body: "already copied", Author: alice},
+			{ID: 12, Body: "/sonar accept", Author: alice},
+			{ID: 13, Body: "bot reply", Author: bot},
+			{ID: 14, Body: "This is a test fixture", Author: alice},
+		}},
+		{ID: "closed", Notes: []gitlab.DiscussionNote{
+			{ID: 20, Body: commentMarker + "\n" + issueKeysMarkerPrefix + "GONE -->", Author: bot},
+			{ID: 21, Body: "reply", Author: alice},
+		}},
+		{ID: "human", Notes: []gitlab.DiscussionNote{{ID: 30, Body: "reply", Author: alice}}},
+	}
+
+	mirrors := planReplyMirrors(discussions, map[string]bool{"A": true}, bot)
+
+	expected := []replyMirror{{
+		toolNote: toolNote,
+		keys:     []string{"A"},
+		replies:  []gitlab.DiscussionNote{{ID: 14, Body: "This is a test fixture", Author: alice}},
+		mirrored: []int{11},
+	}}
+	if !reflect.DeepEqual(mirrors, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, mirrors)
+	}
+}
+
+func TestWithMirroredMarker(t *testing.T) {
+	t.Parallel()
+
+	body := withMirroredMarker("tool note\n", []int{11})
+	if body != "tool note\n<!-- sonar-mirrored: 11 -->" {
+		t.Fatalf("expected appended marker, got %q", body)
+	}
+
+	body = withMirroredMarker(body, []int{11, 14})
+	if body != "tool note\n<!-- sonar-mirrored: 11,14 -->" {
+		t.Fatalf("expected replaced marker, got %q", body)
+	}
+	if ids := mirroredNoteIDs(body); !reflect.DeepEqual(ids, []int{11, 14}) {
+		t.Fatalf("expected [11 14], got %v", ids)
+	}
+}
+
+func TestRunWithMirrorRepliesCopiesRepliesOnce(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		comments []string
+		updates  []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/user":
+			_, _ = w.Write([]byte(`{"id":1,"username":"bot"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"web_url":"https://gitlab.example.com/group/app/-/merge_requests/42","diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[{"id":"d1","resolvable":true,"notes":[` +
+				`{"id":7,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-1 -->","author":{"id":1,"username":"bot"}},` +
+				`{"id":8,"body":"Mocked in tests, see #12","author":{"id":2,"username":"alice"}}]}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes/7":
+			updates = append(updates, r.PostForm.Get("body"))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/issues/add_comment":
+			comments = append(comments, r.PostForm.Get("issue")+": "+r.PostForm.Get("text"))
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/draft_notes"):
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`10.6.0.92116`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			_, _ = w.Write([]byte(`{"issues":[{"key":"ISSUE-1","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"first","component":"project:main.go","line":12}],"paging":{"pageIndex":1,"pageSize":500,"total":1}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		append(draftNotesTestArgs(server.URL), "--mirror-replies"),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectedComments := []string{
+		"ISSUE-1: Mocked in tests, see #12\n\n— @alice, [GitLab merge request !42](https://gitlab.example.com/group/app/-/merge_requests/42#note_8)",
+	}
+	if !reflect.DeepEqual(comments, expectedComments) {
+		t.Fatalf("expected comments %q, got %q", expectedComments, comments)
+	}
+	expectedUpdates := []string{"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-1 -->\n<!-- sonar-mirrored: 8 -->"}
+	if !reflect.DeepEqual(updates, expectedUpdates) {
+		t.Fatalf("expected tool note updates %q, got %q", expectedUpdates, updates)
+	}
+	assertCommentContains(t, output.String(), "Copied 1 GitLab replies to SonarQube issue comments")
+}
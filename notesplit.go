@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"sonar-gitlab-commenter/internal/i18n"
+)
+
+// gitlabNoteLimit is the maximum note length GitLab accepts, in characters.
+const gitlabNoteLimit = 1_000_000
+
+// compactMessageLength is the number of characters issue messages are cut to when
+// the summary does not fit into one note.
+const compactMessageLength = 200
+
+const summaryPartMarkerFormat = "<!-- sonar-gitlab-commenter:summary-part %d -->"
+
+var summaryPartMarkerRegex = regexp.MustCompile(`<!-- sonar-gitlab-commenter:summary-part (\d+) -->`)
+
+func noteLength(body string) int {
+	return utf8.RuneCountInString(body)
+}
+
+// truncateRunes cuts value to at most limit characters, ending with an ellipsis.
+func truncateRunes(value string, limit int) string {
+	if noteLength(value) <= limit {
+		return value
+	}
+	if limit <= 0 {
+		return ""
+	}
+
+	runes := []rune(value)
+	return string(runes[:limit-1]) + "…"
+}
+
+// summaryPart returns the continuation number of a summary continuation note.
+func summaryPart(body string) (int, bool) {
+	if !commentHasMarker(body) {
+		return 0, false
+	}
+	match := summaryPartMarkerRegex.FindStringSubmatch(body)
+	if match == nil {
+		return 0, false
+	}
+	part, err := strconv.Atoi(match[1])
+	if err != nil || part < 2 {
+		return 0, false
+	}
+
+	return part, true
+}
+
+// splitSummaryNote splits a summary longer than limit characters into the main
+// note and numbered continuation notes at line boundaries. Open <details> blocks
+// are closed and reopened and table headers repeated in the next note; a line
+// that does not fit into an empty note is truncated.
+func splitSummaryNote(catalog i18n.Catalog, body string, limit int) []string {
+	if noteLength(body) <= limit {
+		return []string{body}
+	}
+
+	splitter := noteSplitter{catalog: catalog, limit: limit}
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		splitter.add(line, i+1 < len(lines) && isTableSeparator(lines[i+1]))
+	}
+
+	return splitter.finish()
+}
+
+type noteSplitter struct {
+	catalog i18n.Catalog
+	limit   int
+	parts   []string
+	lines   []string
+	length  int
+	// content counts the lines of the current note after its continuation header.
+	content int
+	// details holds the opening lines of the enclosing <details> blocks and
+	// tableHeader the header and separator rows of the enclosing table.
+	details     [][]string
+	tableHeader []string
+}
+
+func (s *noteSplitter) add(line string, startsTable bool) {
+	if s.content > 0 && s.length+1+noteLength(line)+s.closingLength() > s.limit {
+		s.flush()
+		s.start(strings.HasPrefix(line, "|") && !startsTable)
+	}
+	if available := s.limit - s.length - s.closingLength() - 1; noteLength(line) > available {
+		line = truncateRunes(line, available)
+	}
+	s.append(line)
+	s.content++
+
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "<details>":
+		s.details = append(s.details, []string{line})
+	case strings.HasPrefix(trimmed, "<summary>") && len(s.details) > 0 && len(s.details[len(s.details)-1]) == 1:
+		s.details[len(s.details)-1] = append(s.details[len(s.details)-1], line)
+	case trimmed == "</details>" && len(s.details) > 0:
+		s.details = s.details[:len(s.details)-1]
+	}
+
+	switch {
+	case startsTable:
+		s.tableHeader = []string{line}
+	case len(s.tableHeader) == 1 && isTableSeparator(line):
+		s.tableHeader = append(s.tableHeader, line)
+	case !strings.HasPrefix(line, "|"):
+		s.tableHeader = nil
+	}
+}
+
+func (s *noteSplitter) append(line string) {
+	if len(s.lines) > 0 {
+		s.length++
+	}
+	s.lines = append(s.lines, line)
+	s.length += noteLength(line)
+}
+
+func (s *noteSplitter) closingLength() int {
+	return len(s.details) * noteLength("\n\n</details>")
+}
+
+func (s *noteSplitter) flush() {
+	for range s.details {
+		s.append("")
+		s.append("</details>")
+	}
+	s.parts = append(s.parts, strings.Join(s.lines, "\n"))
+	s.lines = nil
+	s.length = 0
+	s.content = 0
+}
+
+// start opens a continuation note and restores the enclosing blocks.
+func (s *noteSplitter) start(repeatTableHeader bool) {
+	s.append(commentMarker)
+	s.append(fmt.Sprintf(summaryPartMarkerFormat, len(s.parts)+1))
+	s.append("**" + fmt.Sprintf(s.catalog.SummaryContinued, len(s.parts)+1) + "**")
+	s.append("")
+	for _, opening := range s.details {
+		for _, line := range opening {
+			s.append(line)
+		}
+		s.append("")
+	}
+	if repeatTableHeader {
+		for _, line := range s.tableHeader {
+			s.append(line)
+		}
+	}
+}
+
+func (s *noteSplitter) finish() []string {
+	if len(s.lines) > 0 {
+		s.flush()
+	}
+
+	return s.parts
+}
+
+func isTableSeparator(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "|") && strings.Trim(trimmed, "|-: ") == ""
+}
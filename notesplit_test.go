@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/i18n"
+)
+
+func TestSplitSummaryNoteKeepsShortSummary(t *testing.T) {
+	t.Parallel()
+
+	parts := splitSummaryNote(i18n.English(), "short", 10)
+	if len(parts) != 1 || parts[0] != "short" {
+		t.Fatalf("unexpected parts: %q", parts)
+	}
+}
+
+func TestSplitSummaryNoteReopensDetailsAndTables(t *testing.T) {
+	t.Parallel()
+
+	lines := []string{commentMarker, "**SonarQube summary**", "", "| File | Count |", "| --- | ---: |"}
+	for index := range 20 {
+		lines = append(lines, fmt.Sprintf("| `file%02d.go` | %d |", index, index))
+	}
+	lines = append(lines, "", "<details>", "<summary>20 more issues</summary>", "")
+	for index := range 20 {
+		lines = append(lines, fmt.Sprintf("- L%d [MAJOR][BUG] message number %d", index, index))
+	}
+	lines = append(lines, "", "</details>")
+	body := strings.Join(lines, "\n")
+
+	const limit = 300
+	parts := splitSummaryNote(i18n.English(), body, limit)
+	if len(parts) < 3 {
+		t.Fatalf("expected several parts, got %d", len(parts))
+	}
+
+	for index, part := range parts {
+		if length := noteLength(part); length > limit {
+			t.Fatalf("part %d is %d characters long, limit is %d: %q", index, length, limit, part)
+		}
+		if strings.Count(part, "<details>") != strings.Count(part, "</details>") {
+			t.Fatalf("part %d has unbalanced details blocks: %q", index, part)
+		}
+		if index == 0 {
+			continue
+		}
+
+		assertCommentContains(t, part, fmt.Sprintf("%s\n<!-- sonar-gitlab-commenter:summary-part %d -->\n**SonarQube summary (continued, part %d)**", commentMarker, index+1, index+1))
+		if part, ok := summaryPart(part); !ok || part != index+1 {
+			t.Fatalf("expected continuation part %d, got %d (ok=%v)", index+1, part, ok)
+		}
+		if isSummaryNote(part) {
+			t.Fatalf("continuation note %d detected as main summary", index)
+		}
+		if strings.Contains(part, "`file") && !strings.Contains(part, "| File | Count |\n| --- | ---: |\n| `file") {
+			t.Fatalf("expected table header to be repeated in part %d: %q", index, part)
+		}
+		if strings.Contains(part, "- L") && !strings.Contains(part, "<details>\n<summary>20 more issues</summary>") {
+			t.Fatalf("expected details block to be reopened in part %d: %q", index, part)
+		}
+	}
+
+	for index := range 20 {
+		row := fmt.Sprintf("| `file%02d.go` | %d |", index, index)
+		item := fmt.Sprintf("- L%d [MAJOR][BUG] message number %d\n", index, index)
+		joined := strings.Join(parts, "\n")
+		if !strings.Contains(joined, row) || !strings.Contains(joined, item) {
+			t.Fatalf("expected line %d to be kept across parts", index)
+		}
+	}
+}
+
+func TestSplitSummaryNoteTruncatesOversizedLine(t *testing.T) {
+	t.Parallel()
+
+	body := commentMarker + "\n" + strings.Repeat("ы", 500)
+	parts := splitSummaryNote(i18n.English(), body, 200)
+	for index, part := range parts {
+		if length := noteLength(part); length > 200 {
+			t.Fatalf("part %d is %d characters long", index, length)
+		}
+	}
+	if !strings.HasSuffix(parts[len(parts)-1], "…") {
+		t.Fatalf("expected truncated line, got %q", parts)
+	}
+}
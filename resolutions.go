@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// transitionMarker tags the reply recording a transition, so that a discussion is
+// transitioned only once.
+const transitionMarker = "<!-- sonar-gitlab-commenter:transition -->"
+
+// resolutionTransition is a SonarQube transition requested by a reviewer who
+// resolved a tool discussion.
+type resolutionTransition struct {
+	discussionID string
+	keys         []string
+	transition   string
+	resolver     string
+}
+
+// planResolutionTransitions finds tool discussions that someone other than the bot
+// resolved with a keyword reply. Only keys SonarQube still reports open are
+// transitioned; discussions without a keyword or already recorded are skipped.
+func planResolutionTransitions(
+	cfg config.Config,
+	discussions []gitlab.Discussion,
+	openKeys map[string]bool,
+	bot gitlab.User,
+	acceptTransition string,
+) []resolutionTransition {
+	var transitions []resolutionTransition
+	for _, discussion := range discussions {
+		if !discussion.Resolved || !discussionContainsMarker(discussion) {
+			continue
+		}
+		if slices.ContainsFunc(discussion.Notes, func(note gitlab.DiscussionNote) bool {
+			return strings.Contains(note.Body, transitionMarker)
+		}) {
+			continue
+		}
+
+		resolver := discussionResolver(discussion)
+		if resolver.ID == 0 || resolver.ID == bot.ID {
+			continue
+		}
+
+		transition := replyTransition(cfg, discussion, bot, acceptTransition)
+		if transition == "" {
+			continue
+		}
+
+		var keys []string
+		for _, key := range discussionIssueKeys(discussion) {
+			if openKeys[key] {
+				keys = append(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		transitions = append(transitions, resolutionTransition{
+			discussionID: discussion.ID,
+			keys:         keys,
+			transition:   transition,
+			resolver:     resolver.Username,
+		})
+	}
+
+	return transitions
+}
+
+// acceptTransitionFor returns the accept transition of the configured server;
+// SonarCloud always uses "accept".
+func acceptTransitionFor(cfg config.Config, serverVersion string) string {
+	if sonar.IsSonarCloudURL(cfg.SonarURL) {
+		return sonar.TransitionAccept
+	}
+
+	return sonar.AcceptTransition(serverVersion)
+}
+
+func discussionResolver(discussion gitlab.Discussion) gitlab.User {
+	for _, note := range discussion.Notes {
+		if note.ResolvedBy.ID != 0 {
+			return note.ResolvedBy
+		}
+	}
+
+	return gitlab.User{}
+}
+
+// replyTransition picks the transition named by the latest human reply of the
+// discussion that starts with a keyword.
+func replyTransition(cfg config.Config, discussion gitlab.Discussion, bot gitlab.User, acceptTransition string) string {
+	falsePositive := replyWords(cfg.FalsePositiveKeyword)
+	accept := replyWords(cfg.AcceptKeyword)
+	for index := len(discussion.Notes) - 1; index >= 0; index-- {
+		note := discussion.Notes[index]
+		if note.Author.ID == bot.ID || commentHasMarker(note.Body) {
+			continue
+		}
+
+		words := replyWords(note.Body)
+		switch {
+		case hasWordPrefix(words, falsePositive):
+			return sonar.TransitionFalsePositive
+		case hasWordPrefix(words, accept):
+			return acceptTransition
+		}
+	}
+
+	return ""
+}
+
+// replyWords splits text into words without surrounding punctuation, folding case
+// and typographic apostrophes, which GitLab clients may insert into "won't fix".
+func replyWords(text string) []string {
+	fields := strings.Fields(strings.ToLower(strings.ReplaceAll(text, "’", "'")))
+	words := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if word := strings.TrimFunc(field, unicode.IsPunct); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+func hasWordPrefix(words, prefix []string) bool {
+	return len(prefix) > 0 && len(words) >= len(prefix) && slices.Equal(words[:len(prefix)], prefix)
+}
+
+// syncResolutions applies the planned transitions and records each in its
+// discussion. It returns the transitioned issue keys.
+func syncResolutions(
+	ctx context.Context,
+	gitlabClient *gitlab.Client,
+	sonarClient *sonar.Client,
+	cfg config.Config,
+	catalog i18n.Catalog,
+	transitions []resolutionTransition,
+) (map[string]bool, error) {
+	transitioned := make(map[string]bool)
+	for _, planned := range transitions {
+		for _, key := range planned.keys {
+			if err := sonarClient.DoTransition(ctx, key, planned.transition); err != nil {
+				return transitioned, fmt.Errorf("failed to transition SonarQube issue %s: %w", key, err)
+			}
+			transitioned[key] = true
+		}
+
+		body := transitionMarker + "\n" + fmt.Sprintf(
+			catalog.TransitionRecorded,
+			formatIssueKeyList(planned.keys),
+			transitionName(catalog, planned.transition),
+			planned.resolver,
+		)
+		if err := gitlabClient.CreateDiscussionNote(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID, planned.discussionID, body); err != nil {
+			return transitioned, wrapGitLabError(err, "failed to record SonarQube transition in the discussion")
+		}
+	}
+
+	return transitioned, nil
+}
+
+func transitionName(catalog i18n.Catalog, transition string) string {
+	if transition == sonar.TransitionFalsePositive {
+		return catalog.TransitionFalsePositive
+	}
+
+	return catalog.TransitionAccepted
+}
+
+func formatIssueKeyList(keys []string) string {
+	quoted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		quoted = append(quoted, "`"+key+"`")
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+var resolutionTestConfig = config.Config{
+	AcceptKeyword:        config.DefaultAcceptKeyword,
+	FalsePositiveKeyword: config.DefaultFalsePositiveKeyword,
+}
+
+func resolvedToolDiscussion(id string, resolver gitlab.User, keys string, replies ...gitlab.DiscussionNote) gitlab.Discussion {
+	notes := []gitlab.DiscussionNote{{
+		Body:       commentMarker + "\n" + issueKeysMarkerPrefix + keys + " -->",
+		Author:     gitlab.User{ID: 1, Username: "bot"},
+		ResolvedBy: resolver,
+	}}
+
+	return gitlab.Discussion{ID: id, Resolved: true, Resolvable: true, Notes: append(notes, replies...)}
+}
+
+func TestPlanResolutionTransitions(t *testing.T) {
+	t.Parallel()
+
+	bot := gitlab.User{ID: 1, Username: "bot"}
+	alice := gitlab.User{ID: 2, Username: "alice"}
+	reply := func(body string) gitlab.DiscussionNote {
+		return gitlab.DiscussionNote{Body: body, Author: alice}
+	}
+	discussions := []gitlab.Discussion{
+		resolvedToolDiscussion("accepted", alice, "A,GONE", reply("Won’t fix: legacy API")),
+		resolvedToolDiscussion("false-positive", alice, "B", reply("won't fix?"), reply("FALSE POSITIVE: guarded above")),
+		resolvedToolDiscussion("no-keyword", alice, "C", reply("done")),
+		resolvedToolDiscussion("negated", alice, "C", reply("this is not a false positive")),
+		resolvedToolDiscussion("mid-reply", alice, "C", reply("I won't fix it, please do")),
+		resolvedToolDiscussion("longer-word", alice, "C", reply("won't fixate on this")),
+		resolvedToolDiscussion("by-bot", bot, "D", reply("won't fix")),
+		resolvedToolDiscussion("recorded", alice, "E", reply("won't fix"), gitlab.DiscussionNote{Body: transitionMarker, Author: bot}),
+		resolvedToolDiscussion("fixed", alice, "GONE", reply("won't fix")),
+		{ID: "open", Resolvable: true, Notes: []gitlab.DiscussionNote{{Body: commentMarker + "\n" + issueKeysMarkerPrefix + "F -->"}, reply("won't fix")}},
+	}
+	openKeys := map[string]bool{"A": true, "B": true, "C": true, "D": true, "E": true, "F": true}
+
+	transitions := planResolutionTransitions(resolutionTestConfig, discussions, openKeys, bot, sonar.TransitionAccept)
+
+	expected := []resolutionTransition{
+		{discussionID: "accepted", keys: []string{"A"}, transition: sonar.TransitionAccept, resolver: "alice"},
+		{discussionID: "false-positive", keys: []string{"B"}, transition: sonar.TransitionFalsePositive, resolver: "alice"},
+	}
+	if !reflect.DeepEqual(transitions, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, transitions)
+	}
+}
+
+func TestRunWithSyncResolutionsTransitionsResolvedDiscussions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu          sync.Mutex
+		transitions []string
+		replies     []string
+		drafts      []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/user":
+			_, _ = w.Write([]byte(`{"id":1,"username":"bot"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[{"id":"d1","resolved":true,"resolvable":true,"notes":[` +
+				`{"id":1,"body":"<!-- sonar-gitlab-commenter -->\n<!-- sonar-issues: ISSUE-1 -->","author":{"id":1,"username":"bot"},"resolved_by":{"id":2,"username":"alice"}},` +
+				`{"id":2,"body":"Won't fix, generated code","author":{"id":2,"username":"alice"}}]}]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions/d1/notes":
+			_ = r.ParseForm()
+			mu.Lock()
+			replies = append(replies, r.PostForm.Get("body"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			_ = r.ParseForm()
+			mu.Lock()
+			drafts = append(drafts, r.PostForm.Get("note"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes/bulk_publish":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`9.9.4.87374`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			_, _ = w.Write([]byte(`{
+				"issues":[
+					{"key":"ISSUE-1","rule":"go:S100","type":"CODE_SMELL","severity":"MAJOR","message":"first","component":"project:main.go","line":12},
+					{"key":"ISSUE-2","rule":"go:S200","type":"BUG","severity":"MAJOR","message":"second","component":"project:main.go","line":13}
+				],
+				"paging":{"pageIndex":1,"pageSize":500,"total":2}
+			}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/issues/do_transition":
+			_ = r.ParseForm()
+			mu.Lock()
+			transitions = append(transitions, r.PostForm.Get("issue")+"="+r.PostForm.Get("transition"))
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err := runWith(
+		append(draftNotesTestArgs(server.URL), "--sync-resolutions"),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(transitions, []string{"ISSUE-1=wontfix"}) {
+		t.Fatalf("expected ISSUE-1 to be transitioned to wontfix, got %v", transitions)
+	}
+	if len(replies) != 1 || !strings.Contains(replies[0], transitionMarker) ||
+		!strings.Contains(replies[0], "Marked `ISSUE-1` as **accepted** in SonarQube because @alice resolved this discussion.") {
+		t.Fatalf("expected transition reply, got %q", replies)
+	}
+	if len(drafts) != 1 || !strings.Contains(drafts[0], "ISSUE-2") {
+		t.Fatalf("expected only ISSUE-2 to be posted, got %q", drafts)
+	}
+	assertCommentContains(t, output.String(), "Transitioned 1 SonarQube issues of 1 discussions resolved in GitLab")
+}
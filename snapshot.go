@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+const snapshotMarkerPrefix = "<!-- sonar-gitlab-commenter:snapshot "
+
+var snapshotMarkerRegex = regexp.MustCompile(`<!-- sonar-gitlab-commenter:snapshot ([A-Za-z0-9+/=]+) -->`)
+
+// summarySnapshot is the state of one analysis, stored in the summary note so that
+// the next run can report what changed.
+type summarySnapshot struct {
+	QualityGate     string  `json:"gate"`
+	OverallCoverage float64 `json:"coverage"`
+	NewCodeCoverage float64 `json:"newCoverage"`
+	// Issues maps issue keys to severities.
+	Issues map[string]string `json:"issues"`
+	// Tracked maps issue keys or rules to the IIDs of GitLab issues created for
+	// them; it carries over between runs.
+	Tracked map[string]int `json:"tracked,omitempty"`
+}
+
+// summaryDelta is the template view of the changes since the previous snapshot.
+type summaryDelta struct {
+	QualityGateChanged        bool
+	PreviousQualityGateStatus string
+	// PreviousQualityGate is the formatted previous status, like QualityGate.
+	PreviousQualityGate string
+	OverallCoverage     float64
+	NewCodeCoverage     float64
+	NewIssues           []issueData
+	FixedIssues         []issueData
+	UnchangedCount      int
+}
+
+func newSummarySnapshot(qualityReport sonar.QualityReport, issues []sonar.Issue) summarySnapshot {
+	snapshot := summarySnapshot{
+		QualityGate:     qualityReport.QualityGateStatus,
+		OverallCoverage: qualityReport.OverallCoverage,
+		NewCodeCoverage: qualityReport.NewCodeCoverage,
+		Issues:          make(map[string]string, len(issues)),
+	}
+	for _, issue := range issues {
+		if key := strings.TrimSpace(issue.Key); key != "" {
+			snapshot.Issues[key] = sonar.NormalizeSeverity(issue.Severity)
+		}
+	}
+
+	return snapshot
+}
+
+// encodeSummarySnapshot renders the snapshot as a hidden marker holding gzipped,
+// base64-encoded JSON.
+func encodeSummarySnapshot(snapshot summarySnapshot) (string, error) {
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", err
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(payload); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	return snapshotMarkerPrefix + base64.StdEncoding.EncodeToString(compressed.Bytes()) + " -->", nil
+}
+
+// decodeSummarySnapshot reads the snapshot marker of a summary note. Notes without
+// a readable snapshot report false.
+func decodeSummarySnapshot(body string) (summarySnapshot, bool) {
+	match := snapshotMarkerRegex.FindStringSubmatch(body)
+	if match == nil {
+		return summarySnapshot{}, false
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(match[1])
+	if err != nil {
+		return summarySnapshot{}, false
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return summarySnapshot{}, false
+	}
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return summarySnapshot{}, false
+	}
+
+	var snapshot summarySnapshot
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		return summarySnapshot{}, false
+	}
+
+	return snapshot, true
+}
+
+// previousSummarySnapshot returns the snapshot stored in the latest summary note.
+func previousSummarySnapshot(notes []gitlab.MergeRequestNote) *summarySnapshot {
+	note, found := findLatestSummaryNote(notes)
+	if !found {
+		return nil
+	}
+	snapshot, ok := decodeSummarySnapshot(note.Body)
+	if !ok {
+		return nil
+	}
+
+	return &snapshot
+}
+
+// newSummaryDelta compares the current issues and quality report with the
+// previous snapshot. Issues are listed by severity, fixed ones by key within it.
+func newSummaryDelta(
+	links commentLinks,
+	previous summarySnapshot,
+	current summarySnapshot,
+	issues []sonar.Issue,
+) summaryDelta {
+	delta := summaryDelta{
+		QualityGateChanged:        !strings.EqualFold(previous.QualityGate, current.QualityGate),
+		PreviousQualityGateStatus: previous.QualityGate,
+		OverallCoverage:           current.OverallCoverage - previous.OverallCoverage,
+		NewCodeCoverage:           current.NewCodeCoverage - previous.NewCodeCoverage,
+	}
+
+	for _, issue := range issues {
+		key := strings.TrimSpace(issue.Key)
+		if key == "" {
+			continue
+		}
+		if _, known := previous.Issues[key]; known {
+			delta.UnchangedCount++
+			continue
+		}
+		delta.NewIssues = append(delta.NewIssues, newIssueData(links, issue))
+	}
+	for key, severity := range previous.Issues {
+		if _, open := current.Issues[key]; !open {
+			delta.FixedIssues = append(delta.FixedIssues, issueData{Key: key, Severity: severity, SonarURL: links.sonar.Issue(key)})
+		}
+	}
+
+	slices.SortStableFunc(delta.NewIssues, func(a, b issueData) int {
+		return cmp.Compare(severityRank(a.Severity), severityRank(b.Severity))
+	})
+	slices.SortFunc(delta.FixedIssues, func(a, b issueData) int {
+		return cmp.Or(cmp.Compare(severityRank(a.Severity), severityRank(b.Severity)), strings.Compare(a.Key, b.Key))
+	})
+
+	return delta
+}
+
+// severityRank orders severities as summarySeverityOrder, unknown ones last.
+func severityRank(severity string) int {
+	if rank := slices.Index(summarySeverityOrder, sonar.NormalizeSeverity(severity)); rank >= 0 {
+		return rank
+	}
+
+	return len(summarySeverityOrder)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestSummarySnapshotRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	snapshot := newSummarySnapshot(
+		sonar.QualityReport{QualityGateStatus: "failed", OverallCoverage: 81.5, NewCodeCoverage: 60},
+		[]sonar.Issue{{Key: "A", Severity: "major"}, {Key: "B", Severity: "BLOCKER"}, {Severity: "INFO"}},
+	)
+	marker, err := encodeSummarySnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(marker, "<!-- sonar-gitlab-commenter:snapshot ") || strings.Contains(marker[4:len(marker)-3], "--") {
+		t.Fatalf("unexpected snapshot marker: %q", marker)
+	}
+
+	decoded, ok := decodeSummarySnapshot(commentMarker + "\n" + marker + "\n**SonarQube summary**")
+	if !ok {
+		t.Fatal("expected snapshot to be decoded")
+	}
+	expected := summarySnapshot{
+		QualityGate:     "failed",
+		OverallCoverage: 81.5,
+		NewCodeCoverage: 60,
+		Issues:          map[string]string{"A": "MAJOR", "B": "BLOCKER"},
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("unexpected snapshot: %+v", decoded)
+	}
+}
+
+func TestDecodeSummarySnapshotRejectsInvalidMarker(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{
+		"**SonarQube summary**",
+		"<!-- sonar-gitlab-commenter:snapshot bm90IGd6aXA= -->",
+		"<!-- sonar-gitlab-commenter:snapshot %%% -->",
+	} {
+		if _, ok := decodeSummarySnapshot(body); ok {
+			t.Fatalf("expected %q to have no snapshot", body)
+		}
+	}
+}
+
+func TestPreviousSummarySnapshotReadsLatestSummary(t *testing.T) {
+	t.Parallel()
+
+	older, err := encodeSummarySnapshot(summarySnapshot{QualityGate: "failed"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	newer, err := encodeSummarySnapshot(summarySnapshot{QualityGate: "passed"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	snapshot := previousSummarySnapshot([]gitlab.MergeRequestNote{
+		{ID: 1, Body: older + "\n" + commentMarker + "\n**SonarQube summary**"},
+		{ID: 2, Body: newer + "\n" + commentMarker + "\n**SonarQube summary**"},
+		{ID: 3, Body: newer},
+	})
+	if snapshot == nil || snapshot.QualityGate != "passed" {
+		t.Fatalf("expected snapshot of the latest summary, got %+v", snapshot)
+	}
+
+	if snapshot := previousSummarySnapshot([]gitlab.MergeRequestNote{{ID: 1, Body: commentMarker + "\n**SonarQube summary**"}}); snapshot != nil {
+		t.Fatalf("expected no snapshot for legacy summary, got %+v", snapshot)
+	}
+}
+
+func TestNewSummaryDelta(t *testing.T) {
+	t.Parallel()
+
+	links := commentLinks{sonar: sonar.UILinks{BaseURL: "https://sonar.example.com", ProjectKey: "app"}}
+	previous := summarySnapshot{
+		QualityGate:     "passed",
+		OverallCoverage: 80,
+		NewCodeCoverage: 70,
+		Issues:          map[string]string{"KEPT": "MAJOR", "GONE-B": "MINOR", "GONE-A": "MINOR", "GONE-C": "BLOCKER"},
+	}
+	issues := []sonar.Issue{
+		{Key: "KEPT", Severity: "MAJOR"},
+		{Key: "NEW-MINOR", Severity: "MINOR"},
+		{Key: "NEW-CRITICAL", Severity: "CRITICAL"},
+	}
+	current := newSummarySnapshot(sonar.QualityReport{QualityGateStatus: "failed", OverallCoverage: 78.5, NewCodeCoverage: 71}, issues)
+
+	delta := newSummaryDelta(links, previous, current, issues)
+
+	if !delta.QualityGateChanged || delta.PreviousQualityGateStatus != "passed" {
+		t.Fatalf("expected gate change from passed, got %+v", delta)
+	}
+	if delta.OverallCoverage != -1.5 || delta.NewCodeCoverage != 1 {
+		t.Fatalf("unexpected coverage deltas: %v, %v", delta.OverallCoverage, delta.NewCodeCoverage)
+	}
+	if delta.UnchangedCount != 1 {
+		t.Fatalf("expected one unchanged issue, got %d", delta.UnchangedCount)
+	}
+
+	var newKeys, fixedKeys []string
+	for _, issue := range delta.NewIssues {
+		newKeys = append(newKeys, issue.Key)
+	}
+	for _, issue := range delta.FixedIssues {
+		fixedKeys = append(fixedKeys, issue.Key)
+	}
+	if !reflect.DeepEqual(newKeys, []string{"NEW-CRITICAL", "NEW-MINOR"}) {
+		t.Fatalf("unexpected new issues: %v", newKeys)
+	}
+	if !reflect.DeepEqual(fixedKeys, []string{"GONE-C", "GONE-A", "GONE-B"}) {
+		t.Fatalf("unexpected fixed issues: %v", fixedKeys)
+	}
+	if delta.FixedIssues[0].SonarURL != "https://sonar.example.com/project/issues?id=app&open=GONE-C" {
+		t.Fatalf("unexpected fixed issue link: %q", delta.FixedIssues[0].SonarURL)
+	}
+}
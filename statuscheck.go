@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// Statuses accepted by GitLab external status check responses.
+const (
+	statusCheckPassed = "passed"
+	statusCheckFailed = "failed"
+)
+
+// statusCheckStatus reports only a passed quality gate as passed.
+func statusCheckStatus(report sonar.QualityReport) string {
+	if strings.EqualFold(strings.TrimSpace(report.QualityGateStatus), "passed") {
+		return statusCheckPassed
+	}
+
+	return statusCheckFailed
+}
+
+// reportStatusCheck sends the quality gate result to the external status check
+// named in the config and returns the action log line. The check is looked up
+// even in dry-run mode, so that a wrong name is reported early; a check that
+// already has the status for the head commit is left alone.
+func reportStatusCheck(
+	ctx context.Context,
+	gitlabClient *gitlab.Client,
+	cfg config.Config,
+	catalog i18n.Catalog,
+	headSHA string,
+	report sonar.QualityReport,
+) (string, error) {
+	checks, err := gitlabClient.ListMergeRequestStatusChecks(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID)
+	if err != nil {
+		return "", wrapGitLabError(err, "failed to list external status checks")
+	}
+
+	index := slices.IndexFunc(checks, func(check gitlab.StatusCheck) bool {
+		return strings.EqualFold(strings.TrimSpace(check.Name), cfg.StatusCheckName)
+	})
+	if index < 0 {
+		names := make([]string, 0, len(checks))
+		for _, check := range checks {
+			names = append(names, check.Name)
+		}
+		return "", fmt.Errorf(
+			"external status check %q does not apply to merge request %d (available: %q)",
+			cfg.StatusCheckName,
+			cfg.GitLabMRIID,
+			names,
+		)
+	}
+
+	check := checks[index]
+	status := statusCheckStatus(report)
+	if cfg.DryRun {
+		return fmt.Sprintf(catalog.LogStatusCheckPlanned, status, check.Name), nil
+	}
+	if strings.EqualFold(check.Status, status) {
+		return fmt.Sprintf(catalog.LogStatusCheckUnchanged, status, check.Name), nil
+	}
+
+	if err := gitlabClient.SetStatusCheckResponse(ctx, cfg.GitLabProjectID, cfg.GitLabMRIID, check.ID, headSHA, status); err != nil {
+		return "", wrapGitLabError(err, fmt.Sprintf("failed to report to external status check %q", check.Name))
+	}
+
+	return fmt.Sprintf(catalog.LogStatusCheck, status, check.Name), nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestStatusCheckStatus(t *testing.T) {
+	t.Parallel()
+
+	for gate, expected := range map[string]string{"passed": "passed", "failed": "failed", "FAILED": "failed", "PASSED": "passed", "warning": "failed", "": "failed"} {
+		if status := statusCheckStatus(sonar.QualityReport{QualityGateStatus: gate}); status != expected {
+			t.Fatalf("gate %q: expected %q, got %q", gate, expected, status)
+		}
+	}
+}
+
+func TestRunWithStatusCheck(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		args      []string
+		gate      string
+		current   string
+		responses []string
+		log       string
+		err       string
+	}{
+		{
+			name:      "reported",
+			args:      []string{"--status-check=sonarqube"},
+			gate:      "ERROR",
+			current:   "pending",
+			responses: []string{"external_status_check_id=2&sha=head&status=failed"},
+			log:       `Reported failed to external status check "SonarQube"`,
+		},
+		{
+			name:    "unchanged",
+			args:    []string{"--status-check=SonarQube"},
+			gate:    "OK",
+			current: "passed",
+			log:     `Skipped reporting passed: external status check "SonarQube" already has it`,
+		},
+		{
+			name:    "dry run",
+			args:    []string{"--status-check=SonarQube", "--dry-run"},
+			gate:    "OK",
+			current: "pending",
+			log:     `Planned passed for external status check "SonarQube"`,
+		},
+		{
+			name:    "unknown check",
+			args:    []string{"--status-check=Security"},
+			gate:    "OK",
+			current: "pending",
+			err:     `external status check "Security" does not apply to merge request 42 (available: ["License" "SonarQube"])`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu        sync.Mutex
+				responses []string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = r.ParseForm()
+				mu.Lock()
+				defer mu.Unlock()
+
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+					_, _ = w.Write([]byte(`{"iid":42,"diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/status_checks":
+					_, _ = w.Write([]byte(`[{"id":1,"name":"License","status":"passed"},{"id":2,"name":"SonarQube","status":"` + tc.current + `"}]`))
+				case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/status_check_responses":
+					responses = append(responses, r.PostForm.Encode())
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"id":1}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+					_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,2 @@\n+added line\n+another line"}]}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+					_, _ = w.Write([]byte(`[]`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+					_, _ = w.Write([]byte(`[]`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+					_, _ = w.Write([]byte(`[]`))
+				case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/draft_notes"):
+					w.WriteHeader(http.StatusCreated)
+					_, _ = w.Write([]byte(`{"id":1}`))
+				case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+					w.WriteHeader(http.StatusCreated)
+				case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+					_, _ = w.Write([]byte(`{"valid":true}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+					_, _ = w.Write([]byte(`10.6.0.92116`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+					_, _ = w.Write([]byte(`{"issues":[],"paging":{"pageIndex":1,"pageSize":500,"total":0}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+					_, _ = w.Write([]byte(`{"projectStatus":{"status":"` + tc.gate + `"}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+					_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+					http.Error(w, "unexpected", http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			var output bytes.Buffer
+			err := runWith(
+				append(draftNotesTestArgs(server.URL), tc.args...),
+				func(string) string { return "" },
+				&output,
+			)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !reflect.DeepEqual(responses, tc.responses) {
+				t.Fatalf("expected status check responses %q, got %q", tc.responses, responses)
+			}
+			assertCommentContains(t, output.String(), tc.log)
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// summaryMarker identifies summary notes rendered by templates that omit the heading.
+const summaryMarker = "<!-- sonar-gitlab-commenter:summary -->"
+
+// resolvedMarker tags the reply to a discussion whose issues are gone, so that it
+// is posted once.
+const resolvedMarker = "<!-- sonar-gitlab-commenter:resolved -->"
+
+// reportedAgainMarker tags the reply to a discussion whose issues came back, so
+// that the next fix gets a new resolved reply.
+const reportedAgainMarker = "<!-- sonar-gitlab-commenter:reported-again -->"
+
+const defaultInlineTemplate = `{{- if eq (len .Issues) 1 -}}
+{{- with .Issue -}}
+{{ $.Marker }}
+**{{ $.T.IssueTitle }}**
+- {{ $.T.Severity }}: ` + "`{{ .Severity }}`" + `
+- {{ $.T.Type }}: ` + "`{{ .Type }}`" + `
+- {{ $.T.Message }}: {{ .Message }}
+- {{ $.T.RuleKey }}: {{ if .RuleURL }}[` + "`{{ .Rule }}`" + `]({{ .RuleURL }}){{ else }}` + "`{{ .Rule }}`" + `{{ end }}
+{{- if .Impacts }}
+- {{ $.T.Impacts }}: {{ impacts .Impacts }}
+{{- end }}
+{{- if .CleanCodeAttribute }}
+- {{ $.T.CleanCodeAttribute }}: ` + "`{{ .CleanCodeAttribute }}`" + `{{ if .CleanCodeAttributeCategory }} ({{ .CleanCodeAttributeCategory }}){{ end }}
+{{- end }}
+{{- if .SonarURL }}
+- [{{ $.T.OpenInSonar }}]({{ .SonarURL }})
+{{- end }}
+{{ $.IssueKeysMarker }}
+{{- end -}}
+{{- else -}}
+{{ .Marker }}
+{{ .IssueKeysMarker }}
+**{{ .T.IssuesTitle }} ({{ len .Issues }})**
+
+{{ if .HasImpacts -}}
+| {{ .T.Line }} | {{ .T.Severity }} | {{ .T.Impacts }} | {{ .T.Type }} | {{ .T.Rule }} | {{ .T.Message }} |
+| --- | --- | --- | --- | --- | --- |
+{{- else -}}
+| {{ .T.Line }} | {{ .T.Severity }} | {{ .T.Type }} | {{ .T.Rule }} | {{ .T.Message }} |
+| --- | --- | --- | --- | --- |
+{{- end }}
+{{- range .Issues }}
+| {{ .Line }} | ` + "`{{ .Severity }}`" + ` |{{ if $.HasImpacts }} {{ impacts .Impacts }} |{{ end }} ` + "`{{ .Type }}`" + ` | {{ if .RuleURL }}[` + "`{{ .Rule }}`" + `]({{ .RuleURL }}){{ else }}` + "`{{ .Rule }}`" + `{{ end }} | {{ if .SonarURL }}[{{ cell .Message }}]({{ .SonarURL }}){{ else }}{{ cell .Message }}{{ end }} |
+{{- end }}
+{{- end -}}
+`
+
+const defaultSummaryTemplate = `{{ .Marker }}
+**{{ .T.SummaryHeading }}**
+{{- if .DashboardURL }}
+- [{{ .T.Dashboard }}]({{ .DashboardURL }})
+{{- end }}
+- {{ .T.QualityGate }}: {{ .QualityGate }}
+- {{ .T.OverallCoverage }}: {{ printf "%.2f" .OverallCoverage }}%
+- {{ .T.NewCodeCoverage }}: {{ printf "%.2f" .NewCodeCoverage }}%
+- {{ .T.TotalIssues }}: {{ .TotalIssues }}
+{{- with .Baseline }}
+- {{ $.T.NewInMergeRequest }}: {{ $.TotalIssues }}
+- {{ printf $.T.PreexistingInTouchedLines .Target }}: {{ len .PreexistingIssues }}
+{{- end }}
+{{- with .Delta }}
+
+**{{ $.T.SinceLastAnalysis }}**
+- {{ $.T.QualityGate }}: {{ if .QualityGateChanged }}{{ .PreviousQualityGate }} → {{ $.QualityGate }}{{ else }}{{ $.T.Unchanged }}{{ end }}
+- {{ $.T.OverallCoverage }}: {{ signed .OverallCoverage }}%
+- {{ $.T.NewCodeCoverage }}: {{ signed .NewCodeCoverage }}%
+- {{ $.T.NewIssues }}: {{ len .NewIssues }}
+{{- range .NewIssues }}
+  - [{{ .Severity }}][{{ .Type }}] {{ if .SonarURL }}[{{ .Message }}]({{ .SonarURL }}){{ else }}{{ .Message }}{{ end }} ({{ $.T.RuleReference }} ` + "`{{ .Rule }}`" + `)
+{{- end }}
+- {{ $.T.FixedIssues }}: {{ len .FixedIssues }}
+{{- range .FixedIssues }}
+  - {{ if .SonarURL }}[` + "`{{ .Key }}`" + `]({{ .SonarURL }}){{ else }}` + "`{{ .Key }}`" + `{{ end }} ({{ .Severity }})
+{{- end }}
+- {{ $.T.UnchangedIssues }}: {{ .UnchangedCount }}
+{{- end }}
+{{ if eq .Taxonomy "clean-code" }}
+**{{ .T.IssuesBySoftwareQuality }}**
+{{- range .SoftwareQualityCounts }}
+- {{ .Quality }}: {{ .Total }}{{ if .Severities }} ({{ range $i, $count := .Severities }}{{ if $i }}, {{ end }}{{ $count.Severity }}: {{ $count.Count }}{{ end }}){{ end }}
+{{- end }}
+{{- if .UnclassifiedCount }}
+- UNCLASSIFIED: {{ .UnclassifiedCount }}
+{{- end }}
+{{- else }}
+**{{ .T.IssuesBySeverity }}**
+{{- range .SeverityCounts }}
+- {{ .Severity }}: {{ .Count }}
+{{- end }}
+{{- if .UnknownSeverityCount }}
+- UNKNOWN: {{ .UnknownSeverityCount }}
+{{- end }}
+{{- end }}
+{{- if .Files }}
+
+**{{ .T.IssuesByFile }}**
+{{- if .FilesCollapsed }}
+<details>
+<summary>{{ printf .T.FilesWithIssues (len .Files) }}</summary>
+{{- end }}
+
+| {{ .T.File }} |{{ range .FileSeverities }} {{ . }} |{{ end }} {{ .T.InlineColumn }} | {{ .T.SummaryOnly }} |
+| --- |{{ range .FileSeverities }} ---: |{{ end }} ---: | ---: |
+{{- range .Files }}
+| {{ if .BlobURL }}[` + "`{{ .Path }}`" + `]({{ .BlobURL }}){{ else }}` + "`{{ .Path }}`" + `{{ end }} |{{ range .Counts }} {{ .Count }} |{{ end }} {{ .Inline }} | {{ .SummaryOnly }} |
+{{- end }}
+{{- if .FilesCollapsed }}
+
+</details>
+{{- end }}
+{{- end }}
+{{- if .TopRules }}
+
+**{{ .T.TopRules }}**
+{{- range .TopRules }}
+- {{ if .RuleURL }}[` + "`{{ .Rule }}`" + `]({{ .RuleURL }}){{ else }}` + "`{{ .Rule }}`" + `{{ end }}: {{ .Count }}
+{{- end }}
+{{- end }}
+{{- if .ProjectLevelIssues }}
+
+**{{ .T.IssuesWithoutLineBinding }}**
+{{- range $i, $issue := .ProjectLevelIssues }}
+{{ add $i 1 }}. [{{ .Severity }}][{{ .Type }}] {{ .Message }} ({{ $.T.RuleReference }} ` + "`{{ .Rule }}`" + `)
+{{- if .BlobURL }} — [{{ .FilePath }}{{ if .Line }}:{{ .Line }}{{ end }}]({{ .BlobURL }}){{ end }}
+{{- end }}
+{{- end }}
+{{- if .TrackedIssues }}
+
+**{{ .T.TrackedIssues }}**
+{{- range .TrackedIssues }}
+- {{ if .URL }}[#{{ .IID }}]({{ .URL }}){{ else }}#{{ .IID }}{{ end }}: ` + "`{{ .Key }}`" + `
+{{- end }}
+{{- end }}
+{{- with .Baseline }}{{ if .PreexistingIssues }}
+
+<details>
+<summary>{{ printf $.T.PreexistingSummary (len .PreexistingIssues) }}</summary>
+{{ range .PreexistingIssues }}
+- {{ if .BlobURL }}[{{ .FilePath }}:{{ .Line }}]({{ .BlobURL }}){{ else }}{{ .FilePath }}:{{ .Line }}{{ end }} [{{ .Severity }}][{{ .Type }}] {{ .Message }} ({{ $.T.RuleReference }} ` + "`{{ .Rule }}`" + `)
+{{- end }}
+
+</details>
+{{- end }}{{ end }}
+{{- if .Overflow }}
+
+{{ .Overflow }}
+{{- end }}
+`
+
+const defaultOverflowTemplate = `<details>
+<summary>{{ printf .T.OverflowSummary .Count }}</summary>
+{{- range .Files }}
+
+` + "`{{ .Path }}`" + `
+{{- range .Issues }}
+- {{ if .Link }}[L{{ .Line }}]({{ .Link }}){{ else }}L{{ .Line }}{{ end }} [{{ .Severity }}][{{ .Type }}] {{ .Message }} ({{ $.T.RuleReference }} ` + "`{{ .Rule }}`" + `)
+{{- end }}
+{{- end }}
+
+</details>
+`
+
+const defaultResolvedTemplate = `{{ .Marker }}
+{{ .T.NoLongerReported }} {{ if .CommitURL }}[` + "`{{ .ShortSHA }}`" + `]({{ .CommitURL }}){{ else }}` + "`{{ .ShortSHA }}`" + `{{ end }}
+{{- if .PipelineID }} ({{ if .PipelineURL }}[{{ .T.Pipeline }} #{{ .PipelineID }}]({{ .PipelineURL }}){{ else }}{{ .T.Pipeline }} #{{ .PipelineID }}{{ end }}){{ end }}.
+{{- if .KeptOpen }} {{ .T.KeptOpenWithReplies }}{{ end }}
+`
+
+// commentTemplates holds the parsed inline, summary, overflow and resolved reply
+// templates and the message catalog they are rendered with.
+type commentTemplates struct {
+	catalog i18n.Catalog
+	// fileRows is the number of per-file summary rows shown before collapsing; 0
+	// never collapses.
+	fileRows int
+	// noteLimit is the maximum summary length before it is compacted and split.
+	noteLimit int
+	inline    *template.Template
+	summary   *template.Template
+	overflow  *template.Template
+	resolved  *template.Template
+}
+
+// issueData is the template view of one SonarQube issue. Fields are trimmed.
+type issueData struct {
+	Key                        string
+	Rule                       string
+	Type                       string
+	Severity                   string
+	Message                    string
+	FilePath                   string
+	Line                       int
+	Impacts                    []sonar.Impact
+	CleanCodeAttribute         string
+	CleanCodeAttributeCategory string
+	// SonarURL opens the issue in SonarQube, RuleURL the rule description and
+	// BlobURL the file line at the MR head commit.
+	SonarURL string
+	RuleURL  string
+	BlobURL  string
+	// Link points to the issue line in the MR diff; only set for overflow issues.
+	Link string
+}
+
+type inlineTemplateData struct {
+	T               i18n.Catalog
+	Marker          string
+	IssueKeysMarker string
+	// Issue is the first entry of Issues.
+	Issue      issueData
+	Issues     []issueData
+	HasImpacts bool
+}
+
+type severityCount struct {
+	Severity string
+	Count    int
+}
+
+type softwareQualityCount struct {
+	Quality string
+	Total   int
+	// Severities lists the non-zero counts in summaryImpactSeverityOrder.
+	Severities []severityCount
+}
+
+type summaryTemplateData struct {
+	T                 i18n.Catalog
+	Marker            string
+	QualityGateStatus string
+	QualityGate       string
+	DashboardURL      string
+	OverallCoverage   float64
+	NewCodeCoverage   float64
+	TotalIssues       int
+	// Baseline is set when issues are compared with the target branch analysis;
+	// TotalIssues then counts only the issues introduced by the MR.
+	Baseline *baselineData
+	// Delta is nil on the first run or when the previous summary has no snapshot.
+	Delta                 *summaryDelta
+	Taxonomy              string
+	SeverityCounts        []severityCount
+	UnknownSeverityCount  int
+	SoftwareQualityCounts []softwareQualityCount
+	UnclassifiedCount     int
+	// Files lists files with issues; FilesCollapsed is set once it exceeds the
+	// configured number of rows. FileSeverities names the columns of their counts.
+	Files              []fileBreakdown
+	FileSeverities     []string
+	FilesCollapsed     bool
+	TopRules           []ruleCount
+	ProjectLevelIssues []issueData
+	// TrackedIssues lists GitLab issues created for findings that are still reported.
+	TrackedIssues []trackedIssueData
+	// Overflow is the rendered overflow section, empty when nothing was demoted.
+	Overflow string
+}
+
+type resolvedTemplateData struct {
+	T         i18n.Catalog
+	Marker    string
+	IssueKeys []string
+	CommitSHA string
+	ShortSHA  string
+	CommitURL string
+	// PipelineID and PipelineURL are empty outside GitLab CI.
+	PipelineID  string
+	PipelineURL string
+	// KeptOpen is set when the discussion stays unresolved because of human replies.
+	KeptOpen bool
+}
+
+type overflowFile struct {
+	Path   string
+	Issues []issueData
+}
+
+type overflowTemplateData struct {
+	T     i18n.Catalog
+	Count int
+	Files []overflowFile
+}
+
+var templateFuncs = template.FuncMap{
+	"add":     func(a, b int) int { return a + b },
+	"cell":    escapeTableCell,
+	"impacts": formatCodeImpacts,
+	"join":    strings.Join,
+	"lower":   strings.ToLower,
+	"signed":  func(value float64) string { return fmt.Sprintf("%+.2f", value) },
+	"upper":   strings.ToUpper,
+}
+
+func defaultCommentTemplates() commentTemplates {
+	return commentTemplates{
+		catalog:   i18n.English(),
+		fileRows:  config.DefaultSummaryFileRows,
+		noteLimit: gitlabNoteLimit,
+		inline:    template.Must(template.New("inline").Funcs(templateFuncs).Parse(defaultInlineTemplate)),
+		summary:   template.Must(template.New("summary").Funcs(templateFuncs).Parse(defaultSummaryTemplate)),
+		overflow:  template.Must(template.New("overflow").Funcs(templateFuncs).Parse(defaultOverflowTemplate)),
+		resolved:  template.Must(template.New("resolved").Funcs(templateFuncs).Parse(defaultResolvedTemplate)),
+	}
+}
+
+// loadCommentTemplates replaces the built-in templates with the given files and
+// renders each one against sample data so that mistakes fail before any API call.
+func loadCommentTemplates(catalog i18n.Catalog, inlinePath, summaryPath, overflowPath, resolvedPath string) (commentTemplates, error) {
+	templates := defaultCommentTemplates()
+	templates.catalog = catalog
+
+	for _, source := range []struct {
+		path   string
+		target **template.Template
+	}{
+		{path: inlinePath, target: &templates.inline},
+		{path: summaryPath, target: &templates.summary},
+		{path: overflowPath, target: &templates.overflow},
+		{path: resolvedPath, target: &templates.resolved},
+	} {
+		if source.path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(source.path)
+		if err != nil {
+			return commentTemplates{}, fmt.Errorf("failed to read comment template: %w", err)
+		}
+		parsed, err := template.New((*source.target).Name()).Funcs(templateFuncs).Parse(string(content))
+		if err != nil {
+			return commentTemplates{}, fmt.Errorf("invalid comment template %s: %w", source.path, err)
+		}
+		*source.target = parsed
+	}
+
+	if err := templates.validate(); err != nil {
+		return commentTemplates{}, err
+	}
+
+	return templates, nil
+}
+
+func (templates commentTemplates) validate() error {
+	sample := []sonar.Issue{
+		{
+			Key:                        "SAMPLE-1",
+			Rule:                       "go:S100",
+			Type:                       "BUG",
+			Severity:                   "MAJOR",
+			Message:                    "Sample issue",
+			FilePath:                   "main.go",
+			Line:                       1,
+			Impacts:                    []sonar.Impact{{SoftwareQuality: sonar.SoftwareQualityReliability, Severity: "HIGH"}},
+			CleanCodeAttribute:         "LOGICAL",
+			CleanCodeAttributeCategory: "INTENTIONAL",
+		},
+		{Key: "SAMPLE-2", Rule: "go:S200", Type: "CODE_SMELL", Severity: "MINOR", Message: "Another issue", FilePath: "main.go", Line: 2},
+	}
+
+	links := commentLinks{
+		sonar:      sonar.UILinks{BaseURL: "https://sonar.example.com", ProjectKey: "sample"},
+		projectURL: "https://gitlab.example.com/group/project",
+		headSHA:    "0123456789abcdef",
+	}
+	for _, issues := range [][]sonar.Issue{sample[:1], sample} {
+		if _, err := formatInlineDiscussion(templates, links, issues); err != nil {
+			return err
+		}
+	}
+	for _, taxonomy := range []sonar.Taxonomy{sonar.TaxonomyLegacy, sonar.TaxonomyCleanCode} {
+		if _, err := formatMergeRequestSummaryComment(
+			templates,
+			links,
+			sonar.QualityReport{QualityGateStatus: "passed", OverallCoverage: 80, NewCodeCoverage: 75},
+			sample,
+			sample[1:],
+			[]overflowIssue{{issue: sample[0], link: "https://gitlab.example.com/diffs#line"}},
+			taxonomy,
+			&summarySnapshot{QualityGate: "failed", OverallCoverage: 78, Issues: map[string]string{"SAMPLE-1": "MAJOR", "SAMPLE-0": "CRITICAL"}},
+			&baselineComparison{target: "main", preexisting: sample[1:]},
+			map[string]int{"SAMPLE-2": 7},
+		); err != nil {
+			return err
+		}
+	}
+	for _, keptOpen := range []bool{false, true} {
+		options := resolvedReplyOptions{
+			templates:   templates,
+			links:       links,
+			pipelineID:  "1",
+			pipelineURL: "https://gitlab.example.com/group/project/-/pipelines/1",
+		}
+		if _, err := formatResolvedReply(options, []string{"SAMPLE-1"}, keptOpen); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func executeTemplate(tmpl *template.Template, data any) (string, error) {
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("failed to render %s comment template: %w", tmpl.Name(), err)
+	}
+
+	return buffer.String(), nil
+}
+
+// ensureMarkers prepends the hidden markers a template left out, so that later
+// runs still recognize the comment.
+func ensureMarkers(body string, markers ...string) string {
+	var missing []string
+	for _, marker := range markers {
+		if !strings.Contains(body, marker) {
+			missing = append(missing, marker)
+		}
+	}
+	if len(missing) == 0 {
+		return body
+	}
+
+	return strings.Join(missing, "\n") + "\n" + body
+}
+
+func newIssueData(links commentLinks, issue sonar.Issue) issueData {
+	return issueData{
+		Key:                        strings.TrimSpace(issue.Key),
+		Rule:                       strings.TrimSpace(issue.Rule),
+		Type:                       strings.TrimSpace(issue.Type),
+		Severity:                   strings.TrimSpace(issue.Severity),
+		Message:                    strings.TrimSpace(issue.Message),
+		FilePath:                   issue.FilePath,
+		Line:                       issue.Line,
+		Impacts:                    issue.Impacts,
+		CleanCodeAttribute:         strings.TrimSpace(issue.CleanCodeAttribute),
+		CleanCodeAttributeCategory: strings.TrimSpace(issue.CleanCodeAttributeCategory),
+		SonarURL:                   links.sonar.Issue(issue.Key),
+		RuleURL:                    links.sonar.Rule(strings.TrimSpace(issue.Rule)),
+		BlobURL:                    links.blob(issue.FilePath, issue.Line),
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestLoadCommentTemplatesInjectsMissingMarkers(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	inlinePath := writeTemplateFile(t, dir, "inline.tmpl", "{{ range .Issues }}:warning: {{ .Rule }} {{ .Message }}\n{{ end }}")
+	summaryPath := writeTemplateFile(t, dir, "summary.tmpl", "## Static analysis\nGate: {{ .QualityGateStatus }}, issues: {{ .TotalIssues }}")
+
+	templates, err := loadCommentTemplates(i18n.English(), inlinePath, summaryPath, "", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	inline, err := formatInlineDiscussion(templates, commentLinks{}, []sonar.Issue{{Key: "K1", Rule: "go:S1", Message: "boom"}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if inline != commentMarker+"\n<!-- sonar-issues: K1 -->\n:warning: go:S1 boom\n" {
+		t.Fatalf("unexpected inline comment %q", inline)
+	}
+
+	summary, err := formatMergeRequestSummaryComment(
+		templates,
+		commentLinks{},
+		sonar.QualityReport{QualityGateStatus: "passed"},
+		[]sonar.Issue{{Key: "K1"}},
+		nil,
+		nil,
+		sonar.TaxonomyLegacy,
+		nil,
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	assertCommentContains(t, summary, "## Static analysis\nGate: passed, issues: 1")
+	if !isSummaryNote(summary) {
+		t.Fatalf("expected custom summary to be recognized as summary note, got %q", summary)
+	}
+}
+
+func TestLoadCommentTemplatesRejectsInvalidTemplates(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "syntax.tmpl", content: "{{ if .Issues }}", expected: "invalid comment template"},
+		{name: "field.tmpl", content: "{{ .Issue.Author }}", expected: "failed to render inline comment template"},
+	}
+
+	for _, test := range tests {
+		_, err := loadCommentTemplates(i18n.English(), writeTemplateFile(t, dir, test.name, test.content), "", "", "")
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Fatalf("%s: expected %q error, got %v", test.name, test.expected, err)
+		}
+	}
+
+	_, err := loadCommentTemplates(i18n.English(), "", filepath.Join(dir, "missing.tmpl"), "", "")
+	if err == nil || !strings.Contains(err.Error(), "failed to read comment template") {
+		t.Fatalf("expected missing file error, got %v", err)
+	}
+}
+
+func TestLoadCommentTemplatesCustomOverflow(t *testing.T) {
+	t.Parallel()
+
+	overflowPath := writeTemplateFile(
+		t,
+		t.TempDir(),
+		"overflow.tmpl",
+		"Skipped {{ .Count }}:{{ range .Files }}{{ range .Issues }} {{ .FilePath }}:{{ .Line }}{{ end }}{{ end }}",
+	)
+	templates, err := loadCommentTemplates(i18n.English(), "", "", overflowPath, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	summary, err := formatMergeRequestSummaryComment(
+		templates,
+		commentLinks{},
+		sonar.QualityReport{QualityGateStatus: "passed"},
+		nil,
+		nil,
+		[]overflowIssue{{issue: sonar.Issue{FilePath: "a.go", Line: 4}}, {issue: sonar.Issue{FilePath: "a.go", Line: 2}}},
+		sonar.TaxonomyLegacy,
+		nil,
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasSuffix(summary, "\n\nSkipped 2: a.go:2 a.go:4") {
+		t.Fatalf("unexpected summary with custom overflow %q", summary)
+	}
+}
+
+func TestLoadCommentTemplatesUsesCustomResolvedTemplate(t *testing.T) {
+	t.Parallel()
+
+	resolvedPath := writeTemplateFile(
+		t,
+		t.TempDir(),
+		"resolved.tmpl",
+		"Fixed {{ join .IssueKeys \", \" }} in {{ .CommitSHA }}{{ if .KeptOpen }}, please resolve{{ end }}",
+	)
+	templates, err := loadCommentTemplates(i18n.English(), "", "", "", resolvedPath)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	reply, err := formatResolvedReply(
+		resolvedReplyOptions{templates: templates, links: commentLinks{headSHA: "abc123"}},
+		[]string{"K1", "K2"},
+		true,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reply != resolvedMarker+"\nFixed K1, K2 in abc123, please resolve" {
+		t.Fatalf("unexpected resolved reply %q", reply)
+	}
+}
+
+func writeTemplateFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	return path
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+// trackedIssueMarkerFormat tags the description of a created GitLab issue with
+// the SonarQube issue key or rule it tracks.
+const trackedIssueMarkerFormat = "<!-- sonar-gitlab-commenter:tracked %s -->"
+
+var trackedIssueMarkerRegex = regexp.MustCompile(`<!-- sonar-gitlab-commenter:tracked (\S+) -->`)
+
+// trackedIssueTitleLength keeps titles of created issues within GitLab limits
+// for long SonarQube messages.
+const trackedIssueTitleLength = 200
+
+// trackedIssueGroup is the set of findings one GitLab issue is created for. key
+// is the SonarQube issue key, or the rule with --create-issues=rule.
+type trackedIssueGroup struct {
+	key    string
+	issues []sonar.Issue
+}
+
+// trackedIssueData is the template view of a GitLab issue created for findings
+// that are still reported.
+type trackedIssueData struct {
+	Key string
+	IID int
+	URL string
+}
+
+// planTrackedIssues groups the summary-only findings that match the severity and
+// type filters and have no GitLab issue in tracked yet. Groups are ordered by
+// their most severe finding, then by key.
+func planTrackedIssues(cfg config.Config, candidates []sonar.Issue, tracked map[string]int) []trackedIssueGroup {
+	if cfg.CreateIssues == "" {
+		return nil
+	}
+
+	var groups []trackedIssueGroup
+	positions := make(map[string]int)
+	for _, issue := range sonar.FilterIssuesBySeverity(candidates, cfg.CreateIssuesSeverity) {
+		if len(cfg.CreateIssuesTypes) > 0 && !slices.Contains(cfg.CreateIssuesTypes, strings.ToUpper(strings.TrimSpace(issue.Type))) {
+			continue
+		}
+
+		key := strings.TrimSpace(issue.Key)
+		if cfg.CreateIssues == config.CreateIssuesPerRule {
+			key = strings.TrimSpace(issue.Rule)
+		}
+		if key == "" || tracked[key] > 0 {
+			continue
+		}
+
+		position, exists := positions[key]
+		if !exists {
+			position = len(groups)
+			positions[key] = position
+			groups = append(groups, trackedIssueGroup{key: key})
+		}
+		groups[position].issues = append(groups[position].issues, issue)
+	}
+
+	slices.SortFunc(groups, func(a, b trackedIssueGroup) int {
+		return cmp.Or(cmp.Compare(groupSeverityRank(a), groupSeverityRank(b)), strings.Compare(a.key, b.key))
+	})
+
+	return groups
+}
+
+func groupSeverityRank(group trackedIssueGroup) int {
+	rank := len(summarySeverityOrder)
+	for _, issue := range group.issues {
+		rank = min(rank, severityRank(issue.Severity))
+	}
+
+	return rank
+}
+
+// findTrackedIssues searches the project for GitLab issues created by earlier
+// runs, so that a lost summary snapshot does not lead to duplicates.
+func findTrackedIssues(ctx context.Context, gitlabClient *gitlab.Client, cfg config.Config) (map[string]int, error) {
+	issues, err := gitlabClient.SearchIssueDescriptions(ctx, cfg.GitLabProjectID, "sonar-gitlab-commenter:tracked")
+	if err != nil {
+		return nil, wrapGitLabError(err, "failed to search GitLab issues created for findings")
+	}
+
+	found := make(map[string]int)
+	for _, issue := range issues {
+		for _, match := range trackedIssueMarkerRegex.FindAllStringSubmatch(issue.Description, -1) {
+			if found[match[1]] == 0 {
+				found[match[1]] = issue.IID
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// createTrackedIssues opens one GitLab issue per group and returns the IIDs of the
+// issues created before any failure, so that they are recorded either way.
+func createTrackedIssues(
+	ctx context.Context,
+	gitlabClient *gitlab.Client,
+	cfg config.Config,
+	catalog i18n.Catalog,
+	links commentLinks,
+	mergeRequestURL string,
+	groups []trackedIssueGroup,
+	now time.Time,
+) (map[string]int, error) {
+	dueDate := ""
+	if cfg.CreateIssuesDueDays > 0 {
+		dueDate = now.AddDate(0, 0, cfg.CreateIssuesDueDays).Format(time.DateOnly)
+	}
+
+	created := make(map[string]int, len(groups))
+	for _, group := range groups {
+		issue := formatTrackedIssue(cfg, catalog, links, mergeRequestURL, group)
+		issue.Labels = cfg.CreateIssuesLabels
+		issue.DueDate = dueDate
+
+		result, err := gitlabClient.CreateIssue(ctx, cfg.GitLabProjectID, issue)
+		if err != nil {
+			return created, wrapGitLabError(err, fmt.Sprintf("failed to create a GitLab issue for %q", group.key))
+		}
+		created[group.key] = result.IID
+	}
+
+	return created, nil
+}
+
+func formatTrackedIssue(
+	cfg config.Config,
+	catalog i18n.Catalog,
+	links commentLinks,
+	mergeRequestURL string,
+	group trackedIssueGroup,
+) gitlab.NewIssue {
+	first := newIssueData(links, group.issues[0])
+	title := fmt.Sprintf(catalog.TrackedIssueTitle, first.Message)
+	if cfg.CreateIssues == config.CreateIssuesPerRule {
+		title = fmt.Sprintf(catalog.TrackedRuleTitle, group.key, len(group.issues))
+	}
+
+	source := fmt.Sprintf("!%d", cfg.GitLabMRIID)
+	if mergeRequestURL != "" {
+		source = fmt.Sprintf("[%s](%s)", source, mergeRequestURL)
+	}
+
+	var description strings.Builder
+	fmt.Fprintf(&description, trackedIssueMarkerFormat+"\n", group.key)
+	fmt.Fprintf(&description, catalog.TrackedIssueSource+"\n", source)
+	if cfg.CreateIssues == config.CreateIssuesPerRule {
+		rule := "`" + group.key + "`"
+		if first.RuleURL != "" {
+			rule = fmt.Sprintf("[%s](%s)", rule, first.RuleURL)
+		}
+		fmt.Fprintf(&description, "\n%s: %s\n", catalog.Rule, rule)
+	}
+	description.WriteString("\n")
+	for _, issue := range group.issues {
+		data := newIssueData(links, issue)
+		fmt.Fprintf(&description, "- [%s][%s] %s (%s `%s`)", data.Severity, data.Type, data.Message, catalog.RuleReference, data.Rule)
+		if data.FilePath != "" {
+			location := data.FilePath
+			if data.Line > 0 {
+				location += fmt.Sprintf(":%d", data.Line)
+			}
+			if data.BlobURL != "" {
+				location = fmt.Sprintf("[%s](%s)", location, data.BlobURL)
+			}
+			fmt.Fprintf(&description, " — %s", location)
+		}
+		if data.SonarURL != "" {
+			fmt.Fprintf(&description, " — [%s](%s)", catalog.OpenInSonar, data.SonarURL)
+		}
+		description.WriteString("\n")
+	}
+
+	return gitlab.NewIssue{Title: truncateRunes(title, trackedIssueTitleLength), Description: description.String()}
+}
+
+// newTrackedIssueData lists the tracked GitLab issues whose issue key or rule is
+// still reported, by IID.
+func newTrackedIssueData(links commentLinks, tracked map[string]int, issues []sonar.Issue) []trackedIssueData {
+	reported := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		reported[strings.TrimSpace(issue.Key)] = true
+		reported[strings.TrimSpace(issue.Rule)] = true
+	}
+
+	var data []trackedIssueData
+	for key, iid := range tracked {
+		if !reported[key] || iid <= 0 {
+			continue
+		}
+		entry := trackedIssueData{Key: key, IID: iid}
+		if links.projectURL != "" {
+			entry.URL = fmt.Sprintf("%s/-/issues/%d", links.projectURL, iid)
+		}
+		data = append(data, entry)
+	}
+	slices.SortFunc(data, func(a, b trackedIssueData) int {
+		return cmp.Or(cmp.Compare(a.IID, b.IID), strings.Compare(a.Key, b.Key))
+	})
+
+	return data
+}
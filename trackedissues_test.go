@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sonar-gitlab-commenter/internal/config"
+	"sonar-gitlab-commenter/internal/gitlab"
+	"sonar-gitlab-commenter/internal/i18n"
+	"sonar-gitlab-commenter/internal/sonar"
+)
+
+func TestPlanTrackedIssuesFiltersAndGroups(t *testing.T) {
+	t.Parallel()
+
+	candidates := []sonar.Issue{
+		{Key: "A", Rule: "go:S1", Type: "VULNERABILITY", Severity: "CRITICAL"},
+		{Key: "B", Rule: "go:S2", Type: "BUG", Severity: "BLOCKER"},
+		{Key: "C", Rule: "go:S1", Type: "VULNERABILITY", Severity: "BLOCKER"},
+		{Key: "D", Rule: "go:S3", Type: "CODE_SMELL", Severity: "MAJOR"},
+		{Key: "E", Rule: "go:S4", Type: "VULNERABILITY", Severity: "BLOCKER"},
+	}
+	tracked := map[string]int{"E": 3, "go:S4": 3}
+
+	perIssue := planTrackedIssues(config.Config{
+		CreateIssues:         config.CreateIssuesPerIssue,
+		CreateIssuesSeverity: "CRITICAL",
+		CreateIssuesTypes:    []string{"VULNERABILITY"},
+	}, candidates, tracked)
+	expected := []trackedIssueGroup{
+		{key: "C", issues: candidates[2:3]},
+		{key: "A", issues: candidates[0:1]},
+	}
+	if !reflect.DeepEqual(perIssue, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, perIssue)
+	}
+
+	perRule := planTrackedIssues(config.Config{
+		CreateIssues:         config.CreateIssuesPerRule,
+		CreateIssuesSeverity: "BLOCKER",
+	}, candidates, tracked)
+	expected = []trackedIssueGroup{
+		{key: "go:S1", issues: []sonar.Issue{candidates[2]}},
+		{key: "go:S2", issues: []sonar.Issue{candidates[1]}},
+	}
+	if !reflect.DeepEqual(perRule, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, perRule)
+	}
+
+	if groups := planTrackedIssues(config.Config{}, candidates, nil); groups != nil {
+		t.Fatalf("expected no groups when disabled, got %+v", groups)
+	}
+}
+
+func TestCreateTrackedIssuesFormatsIssues(t *testing.T) {
+	t.Parallel()
+
+	var created []gitlab.NewIssue
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		created = append(created, gitlab.NewIssue{
+			Title:       r.PostForm.Get("title"),
+			Description: r.PostForm.Get("description"),
+			Labels:      r.PostForm.Get("labels"),
+			DueDate:     r.PostForm.Get("due_date"),
+		})
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"iid":9}`))
+	}))
+	defer server.Close()
+
+	cfg := config.Config{
+		GitLabProjectID:     100,
+		GitLabMRIID:         42,
+		CreateIssues:        config.CreateIssuesPerRule,
+		CreateIssuesLabels:  "sonarqube,security",
+		CreateIssuesDueDays: 14,
+	}
+	links := commentLinks{
+		sonar:      sonar.UILinks{BaseURL: "https://sonar.example.com", ProjectKey: "app"},
+		projectURL: "https://gitlab.example.com/group/app",
+		headSHA:    "head",
+	}
+	group := trackedIssueGroup{key: "go:S1", issues: []sonar.Issue{
+		{Key: "A", Rule: "go:S1", Type: "VULNERABILITY", Severity: "BLOCKER", Message: "Leaked secret", FilePath: "main.go", Line: 3},
+		{Key: "B", Rule: "go:S1", Type: "VULNERABILITY", Severity: "BLOCKER", Message: "Leaked token"},
+	}}
+
+	result, err := createTrackedIssues(
+		context.Background(),
+		gitlab.NewClient(server.URL, "token", server.Client()),
+		cfg,
+		i18n.English(),
+		links,
+		"https://gitlab.example.com/group/app/-/merge_requests/42",
+		[]trackedIssueGroup{group},
+		time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC),
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(result, map[string]int{"go:S1": 9}) {
+		t.Fatalf("expected go:S1 to be tracked as #9, got %v", result)
+	}
+
+	expected := gitlab.NewIssue{
+		Title: "[SonarQube] Rule go:S1: 2 issues",
+		Description: "<!-- sonar-gitlab-commenter:tracked go:S1 -->\n" +
+			"Reported by SonarQube in merge request [!42](https://gitlab.example.com/group/app/-/merge_requests/42).\n\n" +
+			"Rule: [`go:S1`](https://sonar.example.com/coding_rules?open=go%3AS1&rule_key=go%3AS1)\n\n" +
+			"- [BLOCKER][VULNERABILITY] Leaked secret (rule `go:S1`) — [main.go:3](https://gitlab.example.com/group/app/-/blob/head/main.go#L3) — [Open in SonarQube](https://sonar.example.com/project/issues?id=app&open=A)\n" +
+			"- [BLOCKER][VULNERABILITY] Leaked token (rule `go:S1`) — [Open in SonarQube](https://sonar.example.com/project/issues?id=app&open=B)\n",
+		Labels:  "sonarqube,security",
+		DueDate: "2024-05-15",
+	}
+	if len(created) != 1 || created[0] != expected {
+		t.Fatalf("expected %+v, got %+v", expected, created)
+	}
+}
+
+func TestRunWithCreateIssuesLinksIssuesFromSummary(t *testing.T) {
+	t.Parallel()
+
+	previous, err := encodeSummarySnapshot(summarySnapshot{QualityGate: "OK", Tracked: map[string]int{"ISSUE-2": 3}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	notesJSON := strings.ReplaceAll(previous+"\n"+commentMarker+"\n**SonarQube summary**", "\n", `\n`)
+
+	var (
+		mu      sync.Mutex
+		titles  []string
+		summary string
+		search  string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42":
+			_, _ = w.Write([]byte(`{"iid":42,"web_url":"https://gitlab.example.com/group/app/-/merge_requests/42","diff_refs":{"base_sha":"base","start_sha":"start","head_sha":"head"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/changes":
+			_, _ = w.Write([]byte(`{"changes":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -0,0 +12,4 @@\n+one\n+two\n+three\n+four"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/discussions":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes":
+			_, _ = w.Write([]byte(`[{"id":1,"body":"` + notesJSON + `"}]`))
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v4/projects/100/merge_requests/42/notes/1":
+			summary = r.PostForm.Get("body")
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/issues":
+			search = r.URL.Query().Get("search") + " in " + r.URL.Query().Get("in")
+			_, _ = w.Write([]byte(`[{"iid":4,"description":"<!-- sonar-gitlab-commenter:tracked ISSUE-4 -->\nReported by SonarQube in merge request !41."}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v4/projects/100/issues":
+			titles = append(titles, r.PostForm.Get("title"))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"iid":5,"web_url":"https://gitlab.example.com/group/app/-/issues/5"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v4/projects/100/merge_requests/42/draft_notes":
+			_, _ = w.Write([]byte(`[]`))
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/api/v4/projects/100/merge_requests/42/draft_notes"):
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":1}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/authentication/validate":
+			_, _ = w.Write([]byte(`{"valid":true}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/server/version":
+			_, _ = w.Write([]byte(`10.6.0.92116`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/issues/search":
+			_, _ = w.Write([]byte(`{"issues":[` +
+				`{"key":"ISSUE-0","rule":"go:S100","type":"VULNERABILITY","severity":"BLOCKER","message":"Posted inline","component":"project:main.go","line":12},` +
+				`{"key":"ISSUE-1","rule":"go:S100","type":"VULNERABILITY","severity":"BLOCKER","message":"Hardcoded secret","component":"project:main.go","line":13},` +
+				`{"key":"ISSUE-2","rule":"go:S200","type":"VULNERABILITY","severity":"BLOCKER","message":"Weak cipher","component":"project:main.go","line":14},` +
+				`{"key":"ISSUE-3","rule":"go:S300","type":"CODE_SMELL","severity":"MAJOR","message":"Rename","component":"project:main.go","line":15},` +
+				`{"key":"ISSUE-4","rule":"go:S400","type":"VULNERABILITY","severity":"BLOCKER","message":"Tracked before the summary was lost","component":"project:main.go","line":15}` +
+				`],"paging":{"pageIndex":1,"pageSize":500,"total":5}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/qualitygates/project_status":
+			_, _ = w.Write([]byte(`{"projectStatus":{"status":"OK"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/measures/component":
+			_, _ = w.Write([]byte(`{"component":{"measures":[{"metric":"coverage","value":"80.5"},{"metric":"new_coverage","value":"70.0"}]}}`))
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	err = runWith(
+		append(draftNotesTestArgs(server.URL), "--create-issues=issue", "--max-inline-comments=1"),
+		func(string) string { return "" },
+		&output,
+	)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(titles, []string{"[SonarQube] Hardcoded secret"}) {
+		t.Fatalf("expected one GitLab issue for ISSUE-1, got %q", titles)
+	}
+	if search != "sonar-gitlab-commenter:tracked in description" {
+		t.Fatalf("expected a description search for tracked markers, got %q", search)
+	}
+	assertCommentContains(t, summary, "**GitLab issues tracking findings**\n- [#3](https://gitlab.example.com/group/app/-/issues/3): `ISSUE-2`\n- [#4](https://gitlab.example.com/group/app/-/issues/4): `ISSUE-4`\n- [#5](https://gitlab.example.com/group/app/-/issues/5): `ISSUE-1`")
+	snapshot, ok := decodeSummarySnapshot(summary)
+	if !ok || !reflect.DeepEqual(snapshot.Tracked, map[string]int{"ISSUE-1": 5, "ISSUE-2": 3, "ISSUE-4": 4}) {
+		t.Fatalf("expected tracked issues in the snapshot, got %+v", snapshot.Tracked)
+	}
+	assertCommentContains(t, output.String(), "Created 1 GitLab issues for SonarQube findings")
+}